@@ -0,0 +1,567 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chris/jot/config"
+	"github.com/chris/jot/internal/agent"
+	"github.com/chris/jot/internal/bench"
+	"github.com/chris/jot/internal/db"
+	"github.com/chris/jot/internal/migrate"
+	"github.com/chris/jot/internal/publish"
+	"github.com/chris/jot/internal/share"
+	"github.com/chris/jot/internal/takeout"
+)
+
+// runCommand dispatches a jot subcommand and returns the process exit code.
+// Subcommands work on the database directly and never start the bot or REPL.
+func runCommand(cfg *config.Config, args []string) int {
+	name, rest := args[0], args[1:]
+	switch name {
+	case "__complete":
+		cmdComplete(cfg, rest)
+		return 0
+	case "completion":
+		if err := cmdCompletion(rest); err != nil {
+			fmt.Fprintf(os.Stderr, "completion: %v\n", err)
+			return 1
+		}
+		return 0
+	case "bench":
+		// Runs against its own scratch database, never the real one.
+		if err := cmdBench(rest); err != nil {
+			fmt.Fprintf(os.Stderr, "bench: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+	var run func(*db.DB, []string) error
+	switch name {
+	case "purge":
+		run = cmdPurge
+	case "takeout":
+		run = cmdTakeout
+	case "share":
+		run = cmdShare
+	case "reindex":
+		run = cmdReindex
+	case "tools":
+		run = cmdTools
+	case "publish":
+		run = cmdPublish
+	case "setup":
+		run = cmdSetup
+	case "migrate-from":
+		run = func(d *db.DB, args []string) error { return cmdMigrateFrom(d, cfg.DatabasePath, args) }
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", name, commandUsage)
+		return 2
+	}
+
+	database, err := db.Open(cfg.DatabasePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to open database: %v\n", err)
+		return 1
+	}
+	defer database.Close()
+
+	if err := run(database, rest); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+		return 1
+	}
+	return 0
+}
+
+const commandUsage = `Usage:
+  jot                      run the Discord bot or interactive CLI
+  jot purge [-y] <query>   delete everything mentioning <query>
+  jot takeout [-o file]    export all data to a zip (Markdown + JSON)
+  jot share [-o file] [-encrypt] [-r key] <thing-id>
+                           render one thing and its notes for sharing
+  jot reindex              rebuild the full-text search indexes
+  jot tools [deny|allow <profile> <pattern>]
+                           list or change the tools each entry point
+                           (discord, cli, schedule, job, guild) may not use
+  jot publish [-o dir] [-hugo] [-days n] [-s schedule] [-push]
+                           render check-ins and reviews as a static site
+  jot setup [pack]         list workflow packs, or install one (schedules,
+                           saved filters, and area tags)
+  jot bench [-rows n] [-messages n]
+                           time the DB and history-trimming hot paths on
+                           a scratch database
+  jot migrate-from [-y] [-force] <old.db>
+                           import things, memories, and habit logs from an
+                           older jot database (projects/todos/ideas too)
+  jot completion bash|zsh|fish
+                           print a shell completion script (subcommands,
+                           flags, schedule names, and tags)
+`
+
+// cmdPurge lists everything mentioning the query, asks for confirmation, and
+// deletes it across memories, things, summaries, and conversation history.
+func cmdPurge(database *db.DB, args []string) error {
+	fs := flag.NewFlagSet("purge", flag.ContinueOnError)
+	yes := fs.Bool("y", false, "delete without asking for confirmation")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	query := strings.Join(fs.Args(), " ")
+	if query == "" {
+		return fmt.Errorf("usage: jot purge [-y] <query>")
+	}
+
+	c, err := database.FindPurgeCandidates(query)
+	if err != nil {
+		return err
+	}
+	if c.Count() == 0 {
+		fmt.Printf("Nothing mentions %q.\n", query)
+		return nil
+	}
+	printPurgeCandidates(os.Stdout, c)
+
+	if !*yes && !confirm(os.Stdin, os.Stdout, fmt.Sprintf("Delete these %d item(s)?", c.Count())) {
+		fmt.Println("Aborted. Nothing was deleted.")
+		return nil
+	}
+	res, err := database.Purge(c)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Deleted %d memory(ies), %d thing(s), %d summary(ies), %d transcript message(s), %d schedule run(s), %d job(s), %d unsent message(s), %d audit entry(ies); cleared %d conversation(s).\n",
+		res.Memories, res.Things, res.Summaries, res.Transcript, res.ScheduleRuns, res.Jobs, res.Deliveries, res.Audit, res.Conversations)
+	return nil
+}
+
+// cmdTakeout writes a zip archive of everything jot stores.
+func cmdTakeout(database *db.DB, args []string) error {
+	fs := flag.NewFlagSet("takeout", flag.ContinueOnError)
+	out := fs.String("o", "jot-takeout-"+time.Now().Format("2006-01-02")+".zip", "output file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	e, err := database.Export()
+	if err != nil {
+		return err
+	}
+	// The archive holds everything jot knows; keep it private to the user.
+	f, err := os.OpenFile(*out, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("creating %s: %w", *out, err)
+	}
+	if err := takeout.Write(f, e); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("writing %s: %w", *out, err)
+	}
+	fmt.Printf("Wrote %s (%d things, %d memories, %d conversation summaries).\n",
+		*out, len(e.Things), len(e.Memories)+len(e.ProposedMemories), len(e.ConversationSummaries))
+	return nil
+}
+
+// cmdShare renders a thing and its linked memories as Markdown, optionally
+// encrypted with age, to stdout or a file.
+func cmdShare(database *db.DB, args []string) error {
+	fs := flag.NewFlagSet("share", flag.ContinueOnError)
+	out := fs.String("o", "", "output file (default stdout)")
+	encrypt := fs.Bool("encrypt", false, "encrypt with age (passphrase unless -r is given)")
+	var recipients stringList
+	fs.Var(&recipients, "r", "age recipient public key (repeatable, implies -encrypt)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: jot share [-o file] [-encrypt] [-r key] <thing-id>")
+	}
+	id, err := strconv.ParseInt(strings.TrimPrefix(fs.Arg(0), "#"), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid thing ID %q", fs.Arg(0))
+	}
+
+	t, err := database.GetThing(id)
+	if err != nil {
+		return err
+	}
+	if t == nil {
+		return fmt.Errorf("thing %d not found", id)
+	}
+	memories, err := database.SearchMemories("", "", "", &id, "", 100)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	share.Render(&buf, t, memories)
+	data := buf.Bytes()
+	if *encrypt || len(recipients) > 0 {
+		if data, err = share.Encrypt(data, recipients); err != nil {
+			return err
+		}
+	}
+
+	if *out == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(*out, data, 0o600); err != nil {
+		return fmt.Errorf("writing %s: %w", *out, err)
+	}
+	fmt.Printf("Wrote %s.\n", *out)
+	return nil
+}
+
+// cmdReindex rebuilds every FTS index from its source rows and reports the
+// counts before and after.
+func cmdReindex(database *db.DB, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("usage: jot reindex")
+	}
+	results, err := database.Reindex()
+	for _, r := range results {
+		fmt.Printf("%s: %d source rows, %d indexed before, %d after\n", r.Table, r.Source, r.Before, r.Indexed)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Println("Search indexes rebuilt and verified.")
+	return nil
+}
+
+// cmdTools lists tool denials per profile, or adds or removes one. Patterns
+// are tool names or globs such as "delete_*".
+func cmdTools(database *db.DB, args []string) error {
+	const usage = "usage: jot tools [deny|allow <profile> <pattern>]"
+	if len(args) == 0 {
+		denials, err := database.ListToolDenials()
+		if err != nil {
+			return err
+		}
+		if len(denials) == 0 {
+			fmt.Println("Every entry point may use every tool.")
+		}
+		for _, t := range denials {
+			fmt.Printf("%-10s %s\n", t.Profile, t.Pattern)
+		}
+		return nil
+	}
+	if len(args) != 3 {
+		return fmt.Errorf(usage)
+	}
+	action, profile, pattern := args[0], args[1], args[2]
+	if !slices.Contains(agent.Profiles, profile) {
+		return fmt.Errorf("unknown profile %q (use %s)", profile, strings.Join(agent.Profiles, ", "))
+	}
+	switch action {
+	case "deny":
+		if err := database.DenyTool(profile, pattern); err != nil {
+			return err
+		}
+		fmt.Printf("%s may no longer use %s.\n", profile, pattern)
+	case "allow":
+		ok, err := database.AllowTool(profile, pattern)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("%s is not denied %s", profile, pattern)
+		}
+		fmt.Printf("%s may use %s again.\n", profile, pattern)
+	default:
+		return fmt.Errorf(usage)
+	}
+	return nil
+}
+
+// cmdPublish renders logged schedule output and current stats as a static
+// site, optionally committing and pushing it when the output is a git repo.
+func cmdPublish(database *db.DB, args []string) error {
+	fs := flag.NewFlagSet("publish", flag.ContinueOnError)
+	out := fs.String("o", "jot-site", "output directory")
+	hugo := fs.Bool("hugo", false, "write Hugo content (content/log/*.md) instead of HTML")
+	days := fs.Int("days", 30, "include runs from the last n days (0 for all)")
+	title := fs.String("title", "Operating log", "site title")
+	push := fs.Bool("push", false, "git commit and push the output directory")
+	var schedules stringList
+	fs.Var(&schedules, "s", "only include this schedule (repeatable)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 || *days < 0 {
+		return fmt.Errorf("usage: jot publish [-o dir] [-hugo] [-days n] [-s schedule] [-push]")
+	}
+
+	now := time.Now()
+	since, statDays := "", 7
+	if *days > 0 {
+		since, statDays = now.AddDate(0, 0, -*days).UTC().Format(time.DateTime), *days
+	}
+	runs, err := database.ListScheduleRuns(since, schedules)
+	if err != nil {
+		return err
+	}
+	sum, err := database.GetSummary(db.SummaryOptions{Today: now.Format(time.DateOnly), ListLimit: 1000})
+	if err != nil {
+		return err
+	}
+	// Area completions use the same window as the headline count.
+	from := now.AddDate(0, 0, -statDays)
+	completed, err := database.ListCompletedBetween(from, now)
+	if err != nil {
+		return err
+	}
+	areas, err := database.AreaRollups(nil, now.Format(time.DateOnly), from.UTC().Format(time.DateTime))
+	if err != nil {
+		return err
+	}
+	site := &publish.Site{
+		Title:       *title,
+		GeneratedAt: now,
+		Days:        statDays,
+		Open:        sum.Open,
+		Overdue:     len(sum.Overdue),
+		Completed:   len(completed),
+		Areas:       areas,
+		Runs:        runs,
+	}
+
+	write := publish.WriteHTML
+	if *hugo {
+		write = publish.WriteHugo
+	}
+	pages, err := write(*out, site)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %d page(s) from %d run(s) to %s.\n", pages, len(runs), *out)
+	if *push {
+		if err := publish.Push(*out, "jot publish "+now.Format(time.DateOnly)); err != nil {
+			return err
+		}
+		fmt.Println("Pushed.")
+	}
+	return nil
+}
+
+// cmdSetup lists the built-in workflow packs or installs one.
+func cmdSetup(database *db.DB, args []string) error {
+	if len(args) == 0 {
+		for _, p := range db.Packs {
+			fmt.Printf("%-12s %s\n", p.Name, p.Description)
+		}
+		return nil
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("usage: jot setup [pack]")
+	}
+	p := db.FindPack(args[0])
+	if p == nil {
+		names := make([]string, len(db.Packs))
+		for i, p := range db.Packs {
+			names[i] = p.Name
+		}
+		return fmt.Errorf("unknown pack %q (use %s)", args[0], strings.Join(names, ", "))
+	}
+	res, err := database.ApplyPack(p)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Installed %s: %d schedule(s), %d saved filter(s), %d area(s) with new tags.\n", p.Name, res.Schedules, res.Filters, res.Areas)
+	if len(res.Skipped) > 0 {
+		fmt.Printf("Kept your existing %s.\n", strings.Join(res.Skipped, ", "))
+	}
+	return nil
+}
+
+// cmdBench seeds a scratch database and runs the benchmark suite from
+// internal/bench against it, printing one line per case.
+func cmdBench(args []string) error {
+	fs := flag.NewFlagSet("bench", flag.ContinueOnError)
+	rows := fs.Int("rows", bench.DefaultRows, "things and memories to seed")
+	messages := fs.Int("messages", bench.DefaultMessages, "conversation history length")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 || *rows < 1 || *messages < 1 {
+		return fmt.Errorf("usage: jot bench [-rows n] [-messages n]")
+	}
+
+	dir, err := os.MkdirTemp("", "jot-bench")
+	if err != nil {
+		return fmt.Errorf("creating scratch dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	database, err := db.Open(filepath.Join(dir, "bench.db"))
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	start := time.Now()
+	if err := bench.Seed(database, *rows); err != nil {
+		return err
+	}
+	fmt.Printf("Seeded %d things and %d memories in %s\n\n", *rows, *rows, time.Since(start).Round(time.Millisecond))
+	for _, c := range bench.Cases(database, *rows, *messages) {
+		res, err := bench.Measure(c)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%-30s %10d %12s/op %8d B/op %8d allocs/op\n", c.Name, res.N, res.PerOp, res.BytesPerOp, res.AllocsPerOp)
+	}
+	return nil
+}
+
+// cmdMigrateFrom reads an older jot database, shows how each of its tables
+// maps into things and memories, and imports it once confirmed.
+func cmdMigrateFrom(database *db.DB, dbPath string, args []string) error {
+	fs := flag.NewFlagSet("migrate-from", flag.ContinueOnError)
+	yes := fs.Bool("y", false, "import without asking for confirmation")
+	force := fs.Bool("force", false, "import even if this file was imported before")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: jot migrate-from [-y] [-force] <old.db>")
+	}
+	src, err := filepath.Abs(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(src); err != nil {
+		return err
+	}
+	if dst, err := filepath.Abs(dbPath); err == nil && dst == src {
+		return fmt.Errorf("%s is the database jot is using", src)
+	}
+
+	p, err := migrate.Read(src)
+	if err != nil {
+		return err
+	}
+	if !*force {
+		if at, err := database.GetNote(migrate.NoteKey(p.Hash)); err != nil {
+			return err
+		} else if at != "" {
+			return fmt.Errorf("%s (or a copy of it) was already imported at %s UTC; pass -force to import it again", src, at)
+		}
+	}
+	printMigratePlan(os.Stdout, p)
+	if p.Count() == 0 {
+		fmt.Println("Nothing to import.")
+		return nil
+	}
+	if !*yes && !confirm(os.Stdin, os.Stdout, fmt.Sprintf("Import %d thing(s), %d memory(ies), and %d habit log(s)?", len(p.Things), len(p.Memories), len(p.Habits))) {
+		fmt.Println("Aborted. Nothing was imported.")
+		return nil
+	}
+	res, err := migrate.Apply(database, p)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Imported %d thing(s), %d memory(ies) (%d duplicate(s) skipped), and %d habit log(s).\n",
+		res.Things, res.Memories, res.Duplicates, res.Habits)
+	return nil
+}
+
+// stringList is a repeatable string flag.
+type stringList []string
+
+func (s *stringList) String() string     { return strings.Join(*s, ",") }
+func (s *stringList) Set(v string) error { *s = append(*s, v); return nil }
+
+func printPurgeCandidates(w io.Writer, c *db.PurgeCandidates) {
+	if len(c.Memories) > 0 {
+		fmt.Fprintf(w, "Memories (%d):\n", len(c.Memories))
+		for _, m := range c.Memories {
+			fmt.Fprintf(w, "  #%d [%s] %s\n", m.ID, m.Category, oneLine(m.Content, 100))
+		}
+	}
+	if len(c.Things) > 0 {
+		fmt.Fprintf(w, "Things (%d, linked memories are deleted too):\n", len(c.Things))
+		for _, t := range c.Things {
+			fmt.Fprintf(w, "  #%d [%s] %s\n", t.ID, t.Status, oneLine(t.Title, 100))
+		}
+	}
+	if len(c.Summaries) > 0 {
+		fmt.Fprintf(w, "Conversation summaries (%d):\n", len(c.Summaries))
+		for _, s := range c.Summaries {
+			fmt.Fprintf(w, "  #%d %s %s\n", s.ID, s.CreatedAt, oneLine(s.Summary, 80))
+		}
+	}
+	if len(c.Transcript) > 0 {
+		fmt.Fprintf(w, "Transcript messages (%d):\n", len(c.Transcript))
+		for _, m := range c.Transcript {
+			fmt.Fprintf(w, "  %s %s: %s\n", m.CreatedAt, m.Role, oneLine(m.Content, 80))
+		}
+	}
+	if len(c.ScheduleRuns) > 0 {
+		fmt.Fprintf(w, "Schedule runs (%d):\n", len(c.ScheduleRuns))
+		for _, r := range c.ScheduleRuns {
+			fmt.Fprintf(w, "  %s %s: %s\n", r.CreatedAt, r.ScheduleName, oneLine(r.Output, 80))
+		}
+	}
+	if len(c.Jobs) > 0 {
+		fmt.Fprintf(w, "Background jobs (%d):\n", len(c.Jobs))
+		for _, j := range c.Jobs {
+			fmt.Fprintf(w, "  #%d [%s] %s\n", j.ID, j.Status, oneLine(j.Title, 80))
+		}
+	}
+	if len(c.Deliveries) > 0 {
+		fmt.Fprintf(w, "Unsent messages (%d):\n", len(c.Deliveries))
+		for _, dl := range c.Deliveries {
+			fmt.Fprintf(w, "  %s [%s] %s\n", dl.Label, dl.Status, oneLine(dl.Content, 80))
+		}
+	}
+	if len(c.Audit) > 0 {
+		fmt.Fprintf(w, "Audit log entries (%d):\n", len(c.Audit))
+		for _, e := range c.Audit {
+			fmt.Fprintf(w, "  %s %s %s: %s\n", e.CreatedAt, e.Action, e.Target, oneLine(e.Detail, 80))
+		}
+	}
+	if len(c.Conversations) > 0 {
+		fmt.Fprintf(w, "Conversation history to clear (%d): %s\n", len(c.Conversations), strings.Join(c.Conversations, ", "))
+	}
+}
+
+func printMigratePlan(w io.Writer, p *migrate.Plan) {
+	fmt.Fprintf(w, "Found in %s:\n", p.Source)
+	for _, s := range p.Steps {
+		into := "skipped"
+		if s.Into != "" {
+			into = "→ " + s.Into
+		}
+		fmt.Fprintf(w, "  %-16s %6d row(s) %s\n", s.Table, s.Rows, into)
+		for _, n := range s.Notes {
+			fmt.Fprintf(w, "      - %s\n", n)
+		}
+	}
+}
+
+// confirm prints prompt and reads a y/N answer from r.
+func confirm(r io.Reader, w io.Writer, prompt string) bool {
+	fmt.Fprintf(w, "%s [y/N] ", prompt)
+	line, _ := bufio.NewReader(r).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
+// oneLine collapses whitespace and truncates s to n bytes for listings.
+func oneLine(s string, n int) string {
+	return db.Truncate(strings.Join(strings.Fields(s), " "), n)
+}
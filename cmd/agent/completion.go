@@ -0,0 +1,213 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/chris/jot/config"
+	"github.com/chris/jot/internal/agent"
+	"github.com/chris/jot/internal/db"
+	"github.com/chris/jot/internal/llm"
+)
+
+// completionCommand describes a subcommand for shell completion. Flags and
+// arguments name a value kind: "" for boolean flags, "value" for free text,
+// and otherwise a kind that completionValues knows how to list.
+type completionCommand struct {
+	name  string
+	flags map[string]string // flag -> kind of its value
+	args  []string          // kind of each positional argument
+	more  bool              // the last argument repeats
+}
+
+// completionCommands mirrors commandUsage. Keep the two in step when adding a
+// subcommand or flag.
+var completionCommands = []completionCommand{
+	{name: "purge", flags: map[string]string{"-y": ""}, args: []string{"tag"}, more: true},
+	{name: "takeout", flags: map[string]string{"-o": "file"}},
+	{name: "share", flags: map[string]string{"-o": "file", "-encrypt": "", "-r": "value"}, args: []string{"value"}},
+	{name: "reindex"},
+	{name: "tools", args: []string{"action", "profile", "tool"}},
+	{name: "publish", flags: map[string]string{"-o": "dir", "-hugo": "", "-days": "value", "-title": "value", "-s": "schedule", "-push": ""}},
+	{name: "setup", args: []string{"pack"}},
+	{name: "bench", flags: map[string]string{"-rows": "value", "-messages": "value"}},
+	{name: "migrate-from", flags: map[string]string{"-y": "", "-force": ""}, args: []string{"file"}},
+	{name: "completion", args: []string{"shell"}},
+}
+
+// complete returns the candidates for the last of words, the arguments after
+// "jot" up to and including the word being completed. File and directory
+// arguments come back as the single directive ":file" or ":dir" so the shell
+// script can fall back to its own path completion.
+func complete(words []string, values func(kind string) []string) []string {
+	if len(words) <= 1 {
+		names := make([]string, len(completionCommands))
+		for i, c := range completionCommands {
+			names[i] = c.name
+		}
+		return names
+	}
+	i := slices.IndexFunc(completionCommands, func(c completionCommand) bool { return c.name == words[0] })
+	if i < 0 {
+		return nil
+	}
+	cmd := completionCommands[i]
+	cur := words[len(words)-1]
+
+	// Walk the words before the current one to find where it falls: the value
+	// of a flag, a flag, or the nth positional argument.
+	pos, kind := 0, ""
+	expectValue := false
+	for _, w := range words[1 : len(words)-1] {
+		switch {
+		case expectValue:
+			expectValue = false
+		case strings.HasPrefix(w, "-"):
+			k, ok := cmd.flags[w]
+			expectValue = ok && k != ""
+		default:
+			pos++
+		}
+	}
+	switch {
+	case expectValue:
+		kind = cmd.flags[words[len(words)-2]]
+	case strings.HasPrefix(cur, "-") && pos == 0:
+		flags := make([]string, 0, len(cmd.flags))
+		for f := range cmd.flags {
+			flags = append(flags, f)
+		}
+		slices.Sort(flags)
+		return flags
+	case pos < len(cmd.args):
+		kind = cmd.args[pos]
+	case cmd.more && len(cmd.args) > 0:
+		kind = cmd.args[len(cmd.args)-1]
+	}
+
+	switch kind {
+	case "", "value":
+		return nil
+	case "file", "dir":
+		return []string{":" + kind}
+	}
+	return values(kind)
+}
+
+// completionValues lists the candidates of one kind. Tags and schedule names
+// come from the database, opened read-only so that completing never creates
+// or migrates one.
+func completionValues(cfg *config.Config) func(kind string) []string {
+	return func(kind string) []string {
+		switch kind {
+		case "action":
+			return []string{"deny", "allow"}
+		case "profile":
+			return agent.Profiles
+		case "tool":
+			names := make([]string, len(llm.AgentTools))
+			for i, t := range llm.AgentTools {
+				names[i] = t.Name
+			}
+			return names
+		case "pack":
+			names := make([]string, len(db.Packs))
+			for i, p := range db.Packs {
+				names[i] = p.Name
+			}
+			return names
+		case "shell":
+			return []string{"bash", "zsh", "fish"}
+		}
+
+		database, err := db.OpenReadOnly(cfg.DatabasePath)
+		if err != nil {
+			return nil
+		}
+		defer database.Close()
+		switch kind {
+		case "tag":
+			tags, _ := database.ListTags()
+			return tags
+		case "schedule":
+			schedules, _ := database.ListSchedules(false)
+			names := make([]string, len(schedules))
+			for i, s := range schedules {
+				names[i] = s.Name
+			}
+			return names
+		}
+		return nil
+	}
+}
+
+// cmdComplete prints completion candidates one per line. The scripts from
+// jot completion call it on every tab press.
+func cmdComplete(cfg *config.Config, args []string) {
+	for _, c := range complete(args, completionValues(cfg)) {
+		fmt.Println(c)
+	}
+}
+
+// cmdCompletion prints the completion script for a shell.
+func cmdCompletion(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: jot completion bash|zsh|fish")
+	}
+	script, ok := completionScripts[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown shell %q (use bash, zsh, or fish)", args[0])
+	}
+	fmt.Print(script)
+	return nil
+}
+
+var completionScripts = map[string]string{
+	"bash": `# jot bash completion. Add to ~/.bashrc:
+#   source <(jot completion bash)
+_jot() {
+	local cur=${COMP_WORDS[COMP_CWORD]} IFS=$'\n'
+	local -a out
+	out=($(jot __complete "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null))
+	case ${out[0]} in
+	:file) compopt -o filenames; COMPREPLY=($(compgen -f -- "$cur")) ;;
+	:dir) compopt -o filenames; COMPREPLY=($(compgen -d -- "$cur")) ;;
+	*) COMPREPLY=($(compgen -W "${out[*]}" -- "$cur")) ;;
+	esac
+}
+complete -F _jot jot
+`,
+	"zsh": `#compdef jot
+# jot zsh completion. Add to ~/.zshrc after compinit:
+#   source <(jot completion zsh)
+_jot() {
+	local -a out
+	out=("${(@f)$(jot __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+	[[ -n ${out[1]} ]] || return 1
+	case ${out[1]} in
+	:file) _files ;;
+	:dir) _files -/ ;;
+	*) compadd -a out ;;
+	esac
+}
+compdef _jot jot
+`,
+	"fish": `# jot fish completion. Save as ~/.config/fish/completions/jot.fish:
+#   jot completion fish > ~/.config/fish/completions/jot.fish
+function __jot_complete
+	set -l args (commandline -opc)
+	set -e args[1]
+	set -l out (jot __complete $args (commandline -ct) 2>/dev/null)
+	switch "$out[1]"
+	case :file
+		__fish_complete_path (commandline -ct)
+	case :dir
+		__fish_complete_directories (commandline -ct)
+	case '*'
+		printf '%s\n' $out
+	end
+end
+complete -c jot -f -a '(__jot_complete)'
+`,
+}
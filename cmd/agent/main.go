@@ -15,6 +15,7 @@ import (
 	"github.com/chris/jot/internal/db"
 	"github.com/chris/jot/internal/discord"
 	"github.com/chris/jot/internal/llm"
+	"github.com/chris/jot/internal/plaintext"
 	"github.com/chris/jot/internal/scheduler"
 	"github.com/chris/jot/internal/watch"
 )
@@ -22,6 +23,10 @@ import (
 func main() {
 	cfg := config.Load()
 
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(cfg, os.Args[1:]))
+	}
+
 	database, err := db.Open(cfg.DatabasePath)
 	if err != nil {
 		log.Fatalf("failed to open database: %v", err)
@@ -41,9 +46,22 @@ func main() {
 	}
 
 	ag := agent.New(database, client, cfg.MaxContextTokens)
+	ag.SetMemoryApproval(cfg.MemoryApproval)
+	ag.SetBlockerAgeDays(cfg.BlockerAgeDays)
+	ag.SetSynonyms(cfg.Synonyms)
+	ag.SetSummaryDefaults(db.SummaryOptions{
+		RecentDays:   cfg.SummaryRecentDays,
+		RecentLimit:  cfg.SummaryRecentLimit,
+		ListLimit:    cfg.SummaryListLimit,
+		OpenStatuses: cfg.SummaryOpenStatuses,
+	})
 
 	wr := watch.NewRunner(database, client)
 	ag.SetWatchRunner(wr)
+	ag.SetIntegrations(agent.Integrations{
+		DiscordBot: cfg.DiscordToken != "",
+		Webhook:    cfg.DiscordWebhook != "",
+	})
 
 	// If Discord token is set, run as bot
 	if cfg.DiscordToken != "" {
@@ -56,7 +74,7 @@ func main() {
 }
 
 func runCLI(ag *agent.Agent) {
-	ctx := context.Background()
+	ctx := agent.WithProfile(context.Background(), agent.ProfileCLI)
 	scanner := bufio.NewScanner(os.Stdin)
 
 	// Check if stdin is a pipe (non-interactive)
@@ -79,9 +97,17 @@ func runCLI(ag *agent.Agent) {
 			break
 		}
 
-		reply, err := ag.RunWithConversation(ctx, "cli", input)
+		var reply string
+		var err error
+		if args, ok := agent.ParseReviewCommand(input); ok {
+			reply, err = ag.ReviewMemories(args)
+		} else {
+			reply, err = ag.RunWithConversation(ctx, "cli", input)
+		}
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		} else if ag.PlainText("cli") {
+			fmt.Println(plaintext.Strip(reply))
 		} else {
 			fmt.Println(reply)
 		}
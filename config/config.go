@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 	"gopkg.in/yaml.v3"
@@ -21,6 +22,10 @@ type ModelConfig struct {
 type YAMLConfig struct {
 	Models      map[string]ModelConfig `yaml:"models"`
 	ActiveModel string                `yaml:"active_model"`
+
+	// Synonyms maps locale -> canonical tag or habit -> words that mean the
+	// same, added to the built-in ones.
+	Synonyms map[string]map[string][]string `yaml:"synonyms"`
 }
 
 type Config struct {
@@ -36,6 +41,8 @@ type Config struct {
 	Models      map[string]ModelConfig
 	ActiveModel string
 
+	Synonyms map[string]map[string][]string // extra tag/habit synonyms from config.yaml
+
 	// App
 	DiscordToken     string
 	DiscordWebhook   string
@@ -43,6 +50,14 @@ type Config struct {
 	DatabasePath     string
 	CheckInCron      string
 	MaxContextTokens int
+	MemoryApproval   bool // agent-created memories start as proposed until the user approves them
+	BlockerAgeDays   int  // check-ins escalate blockers unresolved at least this long
+
+	// get_summary defaults; zero values use the DB layer's defaults
+	SummaryRecentDays   int
+	SummaryRecentLimit  int
+	SummaryListLimit    int
+	SummaryOpenStatuses []string
 }
 
 func Load() *Config {
@@ -62,7 +77,14 @@ func LoadFrom(yamlPath string) *Config {
 		DatabasePath:     envOr("DATABASE_PATH", "./data.db"),
 		CheckInCron:      envOr("CHECK_IN_CRON", "0 9 * * *"),
 		MaxContextTokens: envInt("MAX_CONTEXT_TOKENS", 180000),
+		MemoryApproval:   envBool("MEMORY_APPROVAL"),
+		BlockerAgeDays:   envInt("BLOCKER_AGE_DAYS", 7),
 		LLMAuthToken:     os.Getenv("ANTHROPIC_AUTH_TOKEN"),
+
+		SummaryRecentDays:   envInt("SUMMARY_RECENT_DAYS", 0),
+		SummaryRecentLimit:  envInt("SUMMARY_RECENT_LIMIT", 0),
+		SummaryListLimit:    envInt("SUMMARY_LIST_LIMIT", 0),
+		SummaryOpenStatuses: envList("SUMMARY_OPEN_STATUSES"),
 	}
 
 	yc, err := loadYAML(yamlPath)
@@ -78,6 +100,7 @@ func LoadFrom(yamlPath string) *Config {
 
 	cfg.Models = yc.Models
 	cfg.ActiveModel = yc.ActiveModel
+	cfg.Synonyms = yc.Synonyms
 
 	mc, ok := yc.Models[yc.ActiveModel]
 	if !ok {
@@ -139,6 +162,22 @@ func envInt(key string, fallback int) int {
 	return fallback
 }
 
+// envList splits a comma-separated env var, dropping empty entries.
+func envList(key string) []string {
+	var out []string
+	for _, s := range strings.Split(os.Getenv(key), ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func envBool(key string) bool {
+	b, _ := strconv.ParseBool(os.Getenv(key))
+	return b
+}
+
 func envFloat64(key string) *float64 {
 	if v := os.Getenv(key); v != "" {
 		if f, err := strconv.ParseFloat(v, 64); err == nil {
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -17,6 +18,8 @@ func clearLLMEnv(t *testing.T) {
 		"OLLAMA_BASE_URL",
 		"DISCORD_BOT_TOKEN", "DISCORD_WEBHOOK_URL", "DISCORD_USER_ID",
 		"DATABASE_PATH", "CHECK_IN_CRON", "MAX_CONTEXT_TOKENS",
+		"MEMORY_APPROVAL",
+		"SUMMARY_RECENT_DAYS", "SUMMARY_RECENT_LIMIT", "SUMMARY_LIST_LIMIT", "SUMMARY_OPEN_STATUSES",
 	}
 	for _, k := range keys {
 		t.Setenv(k, "")
@@ -205,6 +208,38 @@ func TestLoadFrom_NoYAML_DefaultsToAnthropic(t *testing.T) {
 	if cfg.MaxContextTokens != 180000 {
 		t.Errorf("max context tokens = %d, want 180000", cfg.MaxContextTokens)
 	}
+	if cfg.MemoryApproval {
+		t.Error("memory approval should default to off")
+	}
+}
+
+func TestLoadFrom_MemoryApproval(t *testing.T) {
+	clearLLMEnv(t)
+	t.Setenv("MEMORY_APPROVAL", "true")
+
+	cfg := LoadFrom("/nonexistent/config.yaml")
+
+	if !cfg.MemoryApproval {
+		t.Error("expected memory approval to be enabled")
+	}
+}
+
+func TestLoadFrom_Summary(t *testing.T) {
+	clearLLMEnv(t)
+	t.Setenv("SUMMARY_RECENT_DAYS", "14")
+	t.Setenv("SUMMARY_OPEN_STATUSES", "open, active,,waiting")
+
+	cfg := LoadFrom("/nonexistent/config.yaml")
+
+	if cfg.SummaryRecentDays != 14 {
+		t.Errorf("summary recent days = %d, want 14", cfg.SummaryRecentDays)
+	}
+	if cfg.SummaryRecentLimit != 0 {
+		t.Errorf("summary recent limit = %d, want 0 (DB default)", cfg.SummaryRecentLimit)
+	}
+	if got := strings.Join(cfg.SummaryOpenStatuses, ","); got != "open,active,waiting" {
+		t.Errorf("summary open statuses = %q", got)
+	}
 }
 
 func TestResolveAPIKey(t *testing.T) {
@@ -202,7 +202,7 @@ func seedDB(t *testing.T, database *db.DB, seed SeedData) {
 		}
 	}
 	for _, mem := range seed.Memories {
-		_, err := database.SaveMemory(mem.Content, mem.Category, "eval", mem.Tags, nil, "")
+		_, _, err := database.SaveMemory(mem.Content, mem.Category, "eval", mem.Tags, nil, "")
 		if err != nil {
 			t.Fatalf("seeding memory: %v", err)
 		}
@@ -313,8 +313,22 @@ Scoring guide:
 4 = Good — thoughtful, specific, engages with nuance
 5 = Excellent — insightful, well-structured, would genuinely help the user
 
-Respond with ONLY a JSON object, no other text:
-{"score": <1-5>, "reasoning": "<1-2 sentences explaining the score>"}`
+Submit your verdict with the submit_score tool.`
+
+// judgeTool is forced on every judge call so the verdict arrives as tool
+// params rather than free text that has to be parsed.
+var judgeTool = llm.Tool{
+	Name:        "submit_score",
+	Description: "Submit the score for the assistant's response.",
+	Parameters: map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"score":     map[string]any{"type": "integer", "description": "Score from 1 to 5"},
+			"reasoning": map[string]any{"type": "string", "description": "1-2 sentences explaining the score"},
+		},
+		"required": []string{"score", "reasoning"},
+	},
+}
 
 func formatToolContext(history []llm.Message) string {
 	var parts []string
@@ -336,32 +350,25 @@ func judgeResponse(ctx context.Context, client llm.Client, prompt, response, rub
 	if toolContext != "" {
 		toolSection = fmt.Sprintf("\n\n<tool_interactions>\n%s\n</tool_interactions>\n\nThe assistant had access to tools and the above shows what it called and what was returned. Details the assistant mentions that match tool results are NOT hallucinated.", toolContext)
 	}
-	judgePrompt := fmt.Sprintf("User prompt: %s%s\n\n<assistant_response>\n%s\n</assistant_response>\n\nRubric: %s\n\nRespond with ONLY a JSON object.", prompt, toolSection, response, rubric)
+	judgePrompt := fmt.Sprintf("User prompt: %s%s\n\n<assistant_response>\n%s\n</assistant_response>\n\nRubric: %s", prompt, toolSection, response, rubric)
 
 	resp, err := llm.ChatWithRetry(ctx, client, judgeSystemPrompt, []llm.Message{
 		{Role: "user", Content: judgePrompt},
-	}, nil) // no tools for judging
+	}, []llm.Tool{judgeTool}, llm.ForceTool(judgeTool.Name))
 	if err != nil {
 		return 0, "", fmt.Errorf("judge LLM call: %w", err)
 	}
+	if len(resp.ToolCalls) == 0 {
+		return 0, "", fmt.Errorf("judge did not call %s: %q", judgeTool.Name, resp.Content)
+	}
 
-	// Parse the JSON response.
 	var verdict struct {
 		Score     int    `json:"score"`
 		Reasoning string `json:"reasoning"`
 	}
-
-	// Try to extract JSON from the response (LLM might wrap it in markdown).
-	content := resp.Content
-	if idx := strings.Index(content, "{"); idx >= 0 {
-		if end := strings.LastIndex(content, "}"); end > idx {
-			content = content[idx : end+1]
-		}
-	}
-
-	decoder := json.NewDecoder(strings.NewReader(content))
-	if err := decoder.Decode(&verdict); err != nil {
-		return 0, "", fmt.Errorf("parsing judge response %q: %w", resp.Content, err)
+	b, _ := json.Marshal(resp.ToolCalls[0].Params)
+	if err := json.Unmarshal(b, &verdict); err != nil {
+		return 0, "", fmt.Errorf("parsing judge verdict %s: %w", b, err)
 	}
 
 	if verdict.Score < 1 || verdict.Score > 5 {
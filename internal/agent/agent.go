@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/chris/jot/internal/db"
@@ -14,15 +15,25 @@ import (
 
 const maxToolRounds = 10
 
+// listThingsLimit caps list_things results when the model doesn't pass a
+// limit, so a large backlog doesn't flood the context.
+const listThingsLimit = 50
+
 type Agent struct {
 	db               *db.DB
 	client           llm.Client
 	watchRunner      *watch.Runner
+	memoryApproval   bool
+	summaryDefaults  db.SummaryOptions
+	blockerAgeDays   int
+	synonyms         Synonyms
+	integrations     Integrations
+	purges           pendingPurges
 	MaxContextTokens int
 }
 
 func New(database *db.DB, client llm.Client, maxContextTokens int) *Agent {
-	return &Agent{db: database, client: client, MaxContextTokens: maxContextTokens}
+	return &Agent{db: database, client: client, synonyms: NewSynonyms(nil), MaxContextTokens: maxContextTokens}
 }
 
 // SetWatchRunner sets the watch runner for manual watch execution via tools.
@@ -30,8 +41,34 @@ func (a *Agent) SetWatchRunner(wr *watch.Runner) {
 	a.watchRunner = wr
 }
 
+// SetMemoryApproval enables user-approved memory mode: memories saved by the
+// agent start as proposed and stay hidden until the user approves them.
+func (a *Agent) SetMemoryApproval(enabled bool) {
+	a.memoryApproval = enabled
+}
+
+// SetSummaryDefaults sets the get_summary window, counts, and open statuses
+// used when the tool call doesn't override them.
+func (a *Agent) SetSummaryDefaults(opts db.SummaryOptions) {
+	a.summaryDefaults = opts
+}
+
+// SetBlockerAgeDays sets how long a blocker must stay unresolved before
+// check-in context asks the model to escalate it. Zero uses the default.
+func (a *Agent) SetBlockerAgeDays(days int) {
+	a.blockerAgeDays = days
+}
+
+// SetSynonyms adds tag synonyms (locale -> canonical -> variants) to the
+// built-in ones, so tags and habits in any language land on one name.
+func (a *Agent) SetSynonyms(extra map[string]map[string][]string) {
+	a.synonyms = NewSynonyms(extra)
+}
+
 // Run takes a user message, runs the tool-calling loop, and returns the final text response.
 func (a *Agent) Run(ctx context.Context, history []llm.Message, userMessage string) (string, []llm.Message, error) {
+	ctx, turn := turnFrom(ctx)
+
 	// Prepend current time to user message so the LLM has temporal context
 	// without embedding it in the system prompt (which would break caching).
 	loc := a.userLocation()
@@ -48,11 +85,12 @@ func (a *Agent) Run(ctx context.Context, history []llm.Message, userMessage stri
 	copy(messages, history)
 	messages = append(messages, llm.Message{Role: "user", Content: timePrefix + userMessage})
 
-	// Fixed costs: system prompt + tool definitions.
-	fixedTokens := llm.EstimateTokens(llm.SystemPrompt) + llm.EstimateToolsTokens(llm.AgentTools)
-	messageBudget := a.MaxContextTokens - fixedTokens
-	if messageBudget < 1000 {
-		messageBudget = 1000 // floor so we always have room for at least the current turn
+	system := a.systemPrompt(ctx)
+	messageBudget := a.messageBudget() - (llm.EstimateTokens(system) - llm.EstimateTokens(llm.SystemPrompt))
+
+	tools, err := a.toolsFor(ctx)
+	if err != nil {
+		return "", nil, err
 	}
 
 	for i := 0; i < maxToolRounds; i++ {
@@ -60,7 +98,7 @@ func (a *Agent) Run(ctx context.Context, history []llm.Message, userMessage stri
 		if len(trimmed) < len(messages) {
 			log.Printf("context trimmed: %d → %d messages", len(messages), len(trimmed))
 		}
-		resp, err := a.chatWithRetry(ctx, llm.SystemPrompt, trimmed, llm.AgentTools)
+		resp, err := a.chatWithRetry(ctx, system, trimmed, tools)
 		if err != nil {
 			return "", nil, fmt.Errorf("llm chat: %w", err)
 		}
@@ -78,13 +116,26 @@ func (a *Agent) Run(ctx context.Context, history []llm.Message, userMessage stri
 			ToolCalls: resp.ToolCalls,
 		})
 
+		if observe, ok := ctx.Value(toolObserverKey{}).(func([]string)); ok {
+			names := make([]string, len(resp.ToolCalls))
+			for i, tc := range resp.ToolCalls {
+				names[i] = tc.Name
+			}
+			observe(names)
+		}
+
 		// Execute each tool call and append results
 		for _, tc := range resp.ToolCalls {
-			result := a.executeTool(ctx, tc.Name, tc.Params)
+			var result string
+			if !hasTool(tools, tc.Name) {
+				result = fmt.Sprintf(`{"error":"%s is not available here"}`, tc.Name)
+			} else {
+				result = a.executeToolOnce(ctx, turn.id, tc.Name, tc.Params)
+			}
 			if result == "null" || result == "[]" {
 				result = fmt.Sprintf("[%s returned no results.]", tc.Name)
 			}
-			log.Printf("tool %s → %s", tc.Name, truncate(result, 200))
+			log.Printf("tool %s → %s", tc.Name, db.Truncate(result, 200))
 			messages = append(messages, llm.Message{
 				Role:       "user",
 				Content:    result,
@@ -96,6 +147,25 @@ func (a *Agent) Run(ctx context.Context, history []llm.Message, userMessage stri
 	return "I hit the maximum number of tool calls. Here's what I have so far.", messages, nil
 }
 
+type toolObserverKey struct{}
+
+// WithToolObserver makes Run call fn with each round's tool names before
+// executing them, so callers can show progress during long turns.
+func WithToolObserver(ctx context.Context, fn func(names []string)) context.Context {
+	return context.WithValue(ctx, toolObserverKey{}, fn)
+}
+
+// messageBudget is the token budget for messages: MaxContextTokens minus the
+// fixed costs of the system prompt and tool definitions.
+func (a *Agent) messageBudget() int {
+	fixedTokens := llm.EstimateTokens(llm.SystemPrompt) + llm.EstimateToolsTokens(llm.AgentTools)
+	budget := a.MaxContextTokens - fixedTokens
+	if budget < 1000 {
+		budget = 1000 // floor so we always have room for at least the current turn
+	}
+	return budget
+}
+
 // chatWithRetry wraps client.Chat with retry on rate limit (429) errors.
 func (a *Agent) chatWithRetry(ctx context.Context, systemPrompt string, messages []llm.Message, tools []llm.Tool) (*llm.Response, error) {
 	return llm.ChatWithRetry(ctx, a.client, systemPrompt, messages, tools)
@@ -105,12 +175,31 @@ func (a *Agent) executeTool(ctx context.Context, name string, params map[string]
 	var result any
 	var err error
 
+	a.canonicalizeParams(name, params)
+
 	switch name {
 	case "list_things":
-		status, _ := getString(params, "status")
-		priority, _ := getString(params, "priority")
-		tag, _ := getString(params, "tag")
-		result, err = a.db.ListThings(status, priority, tag)
+		q := db.ThingQuery{Limit: listThingsLimit}
+		q.Status, _ = getString(params, "status")
+		q.Priority, _ = getString(params, "priority")
+		q.Tag, _ = getString(params, "tag")
+		q.Sort, _ = getString(params, "sort")
+		if v, ok := getInt(params, "limit"); ok && v > 0 {
+			q.Limit = int(v)
+		}
+		if v, ok := getInt(params, "offset"); ok && v > 0 {
+			q.Offset = int(v)
+		}
+		things, total, e := a.db.QueryThings(q)
+		switch {
+		case e != nil:
+			err = e
+		case total > q.Offset+len(things) || q.Offset > 0:
+			// Only paged results get wrapped, so the model knows there's more.
+			result = map[string]any{"things": things, "total": total, "offset": q.Offset, "limit": q.Limit}
+		default:
+			result = things
+		}
 
 	case "create_thing":
 		title, _ := getString(params, "title")
@@ -127,21 +216,68 @@ func (a *Agent) executeTool(ctx context.Context, name string, params map[string]
 				}
 			}
 		}
+		var autoTags []string
+		if len(tags) == 0 {
+			autoTags = a.inferTags(title + " " + notes)
+			tags = autoTags
+		}
 		id, e := a.db.CreateThing(title, notes, priority, dueDate, tags)
 		if e != nil {
 			err = e
+		} else if len(autoTags) > 0 {
+			result = map[string]any{"id": id, "status": "created", "auto_tags": autoTags}
 		} else {
 			result = map[string]any{"id": id, "status": "created"}
 		}
 
+	case "create_things":
+		items, _ := params["things"].([]any)
+		var things []db.NewThing
+		autoTags := map[int][]string{}
+		for i, item := range items {
+			m, _ := item.(map[string]any)
+			var t db.NewThing
+			t.Title, _ = getString(m, "title")
+			t.Notes, _ = getString(m, "notes")
+			t.Priority, _ = getString(m, "priority")
+			t.DueDate, _ = getString(m, "due_date")
+			t.Tags = getStrings(m, "tags")
+			if t.Title == "" {
+				err = fmt.Errorf("thing %d has no title", i+1)
+				break
+			}
+			if len(t.Tags) == 0 {
+				if t.Tags = a.inferTags(t.Title + " " + t.Notes); len(t.Tags) > 0 {
+					autoTags[i] = t.Tags
+				}
+			}
+			things = append(things, t)
+		}
+		if err == nil && len(things) == 0 {
+			err = fmt.Errorf("things must be a non-empty array")
+		}
+		if err != nil {
+			break
+		}
+		ids, e := a.db.CreateThings(things)
+		if e != nil {
+			err = e
+			break
+		}
+		res := map[string]any{"ids": ids, "status": "created", "count": len(ids)}
+		if len(autoTags) > 0 {
+			byID := map[int64][]string{}
+			for i, tags := range autoTags {
+				byID[ids[i]] = tags
+			}
+			res["auto_tags"] = byID
+		}
+		result = res
+
 	case "update_thing":
 		id, _ := getInt(params, "id")
 		fields := make(map[string]any)
-		for _, k := range []string{"title", "notes", "status", "priority", "due_date"} {
-			if v, ok := params[k]; ok {
-				fields[k] = v
-			}
-		}
+		copyToolFields("things", params, fields)
 		if v, ok := params["tags"]; ok {
 			if arr, ok := v.([]any); ok {
 				var tags []string
@@ -166,6 +302,91 @@ func (a *Agent) executeTool(ctx context.Context, name string, params map[string]
 			result = map[string]any{"status": "completed"}
 		}
 
+	case "get_summary":
+		opts := a.summaryDefaults
+		if v, ok := getInt(params, "recent_days"); ok {
+			opts.RecentDays = int(v)
+		}
+		if v, ok := getInt(params, "recent_limit"); ok {
+			opts.RecentLimit = int(v)
+		}
+		if v, ok := getInt(params, "list_limit"); ok {
+			opts.ListLimit = int(v)
+		}
+		if v := getStrings(params, "open_statuses"); len(v) > 0 {
+			opts.OpenStatuses = v
+		}
+		opts.Tag, _ = getString(params, "tag")
+		opts.Area, _ = getString(params, "area")
+		opts.Today = time.Now().In(a.userLocation()).Format(time.DateOnly)
+		result, err = a.db.GetSummary(opts)
+
+	case "save_filter":
+		name, _ := getString(params, "name")
+		if del, _ := params["delete"].(bool); del {
+			var found bool
+			if found, err = a.db.DeleteFilter(name); err == nil {
+				result = map[string]any{"status": "deleted", "found": found}
+			}
+			break
+		}
+		f := db.SavedFilter{Name: name, Tags: getStrings(params, "tags")}
+		f.Status, _ = getString(params, "status")
+		f.Priority, _ = getString(params, "priority")
+		f.Sort, _ = getString(params, "sort")
+		if v, ok := getInt(params, "due_within_days"); ok {
+			f.DueWithinDays = int(v)
+		}
+		if v, ok := getInt(params, "limit"); ok {
+			f.Limit = int(v)
+		}
+		f.CheckIn, _ = params["check_in"].(bool)
+		var id int64
+		if id, err = a.db.SaveFilter(f); err == nil {
+			result = map[string]any{"status": "saved", "id": id}
+		}
+
+	case "run_filter":
+		result, err = a.runFilters(params)
+
+	case "list_areas":
+		sinceDays, ok := getInt(params, "since_days")
+		if !ok || sinceDays <= 0 {
+			sinceDays = 7
+		}
+		now := time.Now().In(a.userLocation())
+		var areas []db.Area
+		var rollups []db.AreaRollup
+		if areas, err = a.db.ListAreas(); err == nil {
+			rollups, err = a.db.AreaRollups(a.summaryDefaults.OpenStatuses, now.Format(time.DateOnly),
+				now.AddDate(0, 0, -int(sinceDays)+1).Format(time.DateOnly))
+		}
+		if err == nil {
+			result = map[string]any{"areas": areas, "rollups": rollups, "since_days": sinceDays}
+		}
+
+	case "set_area":
+		name, _ := getString(params, "name")
+		if del, _ := params["delete"].(bool); del {
+			var found bool
+			if found, err = a.db.DeleteArea(name); err == nil {
+				result = map[string]any{"status": "deleted", "found": found}
+			}
+			break
+		}
+		var id int64
+		if id, err = a.db.SetArea(name, getStrings(params, "tags")); err == nil {
+			result = map[string]any{"status": "saved", "id": id}
+		}
+
+	case "completed_things":
+		result, err = a.completedThings(params)
+
+	case "list_thing_events":
+		sinceDays, _ := getInt(params, "since_days")
+		kind, _ := getString(params, "kind")
+		result, err = a.db.ListThingEvents(int(sinceDays), kind)
+
 	case "save_memory":
 		content, _ := getString(params, "content")
 		category, _ := getString(params, "category")
@@ -184,13 +405,29 @@ func (a *Agent) executeTool(ctx context.Context, name string, params map[string]
 				}
 			}
 		}
-		id, e := a.db.SaveMemory(content, category, "agent", tags, thingID, expiresAt)
+		var autoTags []string
+		if len(tags) == 0 {
+			autoTags = a.inferTags(content)
+			tags = autoTags
+		}
+		save, status := a.db.SaveMemory, "saved"
+		if a.memoryApproval {
+			save, status = a.db.ProposeMemory, "proposed"
+		}
+		id, dup, e := save(content, category, "agent", tags, thingID, expiresAt)
 		if e != nil {
 			err = e
+		} else if dup {
+			result = map[string]any{"id": id, "status": "exists", "duplicate": true}
+		} else if len(autoTags) > 0 {
+			result = map[string]any{"id": id, "status": status, "auto_tags": autoTags}
 		} else {
-			result = map[string]any{"id": id, "status": "saved"}
+			result = map[string]any{"id": id, "status": status}
 		}
 
+	case "save_memories":
+		result, err = a.saveMemories(params)
+
 	case "search_memories":
 		query, _ := getString(params, "query")
 		category, _ := getString(params, "category")
@@ -206,11 +443,7 @@ func (a *Agent) executeTool(ctx context.Context, name string, params map[string]
 	case "update_memory":
 		id, _ := getInt(params, "id")
 		fields := make(map[string]any)
-		for _, k := range []string{"content", "category", "expires_at"} {
-			if v, ok := params[k]; ok {
-				fields[k] = v
-			}
-		}
+		copyToolFields("memories", params, fields)
 		if v, ok := params["tags"]; ok {
 			if arr, ok := v.([]any); ok {
 				var tags []string
@@ -235,11 +468,36 @@ func (a *Agent) executeTool(ctx context.Context, name string, params map[string]
 			result = map[string]any{"status": "deleted"}
 		}
 
+	case "forget":
+		result, err = a.forget(ctx, params)
+
 	case "list_recent_memories":
 		category, _ := getString(params, "category")
 		limit, _ := getInt(params, "limit")
 		result, err = a.db.ListRecentMemories(category, int(limit))
 
+	case "get_memory_stats":
+		result, err = a.db.GetMemoryStats()
+
+	case "describe_data_model":
+		result, err = db.Model()
+
+	case "list_capabilities":
+		result, err = a.capabilities(ctx)
+
+	case "set_style":
+		result, err = a.setStyle(ctx, params)
+
+	case "search_conversations":
+		query, _ := getString(params, "query")
+		since, _ := getString(params, "since")
+		limit, _ := getInt(params, "limit")
+		if userID := userFrom(ctx); userID == "" {
+			err = fmt.Errorf("no conversation to search outside a chat")
+		} else {
+			result, err = a.db.SearchConversations(userID, query, since, int(limit))
+		}
+
 	case "list_schedules":
 		result, err = a.db.ListSchedules(false)
 
@@ -265,9 +523,21 @@ func (a *Agent) executeTool(ctx context.Context, name string, params map[string]
 			id, e := a.db.CreateSchedule(name, cronExpr, prompt)
 			if e != nil {
 				err = e
-			} else {
-				result = map[string]any{"id": id, "status": "created"}
+				break
 			}
+			opts := make(map[string]any)
+			boolFields(params, opts, "check_in", "digest")
+			for _, k := range []string{"memory_days", "memory_limit"} {
+				if v, ok := getInt(params, k); ok {
+					opts[k] = v
+				}
+			}
+			if len(opts) > 0 {
+				if err = a.db.UpdateSchedule(id, opts); err != nil {
+					break
+				}
+			}
+			result = map[string]any{"id": id, "status": "created"}
 		}
 
 	case "update_schedule":
@@ -282,21 +552,8 @@ func (a *Agent) executeTool(ctx context.Context, name string, params map[string]
 			break
 		}
 		fields := make(map[string]any)
-		if v, ok := getString(params, "cron_expr"); ok {
-			fields["cron_expr"] = v
-		}
-		if v, ok := getString(params, "prompt"); ok {
-			fields["prompt"] = v
-		}
-		if v, ok := params["enabled"]; ok {
-			if b, ok := v.(bool); ok {
-				if b {
-					fields["enabled"] = 1
-				} else {
-					fields["enabled"] = 0
-				}
-			}
-		}
+		copyToolFields("schedules", params, fields)
+		boolFields(params, fields, "enabled", "check_in", "digest")
 		err = a.db.UpdateSchedule(sched.ID, fields)
 		if err == nil {
 			result = map[string]any{"status": "updated"}
@@ -309,6 +566,37 @@ func (a *Agent) executeTool(ctx context.Context, name string, params map[string]
 			result = map[string]any{"status": "deleted"}
 		}
 
+	case "start_job":
+		if profileFrom(ctx) == ProfileJob {
+			result = map[string]any{"error": "background jobs cannot start other jobs; do the work in this run"}
+			break
+		}
+		title, _ := getString(params, "title")
+		prompt, _ := getString(params, "prompt")
+		id, e := a.db.EnqueueJob(title, prompt)
+		if e != nil {
+			err = e
+			break
+		}
+		result = map[string]any{"id": id, "status": "queued"}
+
+	case "spawn_task":
+		task, _ := getString(params, "task")
+		summary, e := a.runSubtask(ctx, task, getStrings(params, "tools"))
+		if e != nil {
+			err = e
+			break
+		}
+		result = map[string]any{"summary": summary}
+
+	case "list_jobs":
+		status, _ := getString(params, "status")
+		if id, ok := getInt(params, "id"); ok {
+			result, err = a.db.GetJob(id)
+			break
+		}
+		result, err = a.db.ListJobs(status, 10)
+
 	case "list_watches":
 		result, err = a.db.ListWatches(false)
 
@@ -345,12 +633,7 @@ func (a *Agent) executeTool(ctx context.Context, name string, params map[string]
 			break
 		}
 		fields := make(map[string]any)
-		if v, ok := getString(params, "prompt"); ok {
-			fields["prompt"] = v
-		}
-		if v, ok := getString(params, "cron_expr"); ok {
-			fields["cron_expr"] = v
-		}
+		copyToolFields("watches", params, fields)
 		if v, ok := params["urls"]; ok {
 			if arr, ok := v.([]any); ok {
 				var urls []string
@@ -473,11 +756,166 @@ func getString(params map[string]any, key string) (string, bool) {
 	return s, ok
 }
 
-func truncate(s string, n int) string {
-	if len(s) <= n {
-		return s
+// completedThings lists things completed between two local dates, inclusive.
+// Without dates it covers the last 7 days including today.
+func (a *Agent) completedThings(params map[string]any) (any, error) {
+	loc := a.userLocation()
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	from, to := today.AddDate(0, 0, -6), today
+	for key, dst := range map[string]*time.Time{"from": &from, "to": &to} {
+		if s, _ := getString(params, key); s != "" {
+			t, err := time.ParseInLocation(time.DateOnly, s, loc)
+			if err != nil {
+				return nil, fmt.Errorf("parsing %s %q: %w", key, s, err)
+			}
+			*dst = t
+		}
+	}
+	things, err := a.db.ListCompletedBetween(from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"from":   from.Format(time.DateOnly),
+		"to":     to.Format(time.DateOnly),
+		"count":  len(things),
+		"things": things,
+	}, nil
+}
+
+// runFilters runs the named saved filter, or every check-in filter when
+// check_in is set. With neither, it lists the saved filters.
+func (a *Agent) runFilters(params map[string]any) (any, error) {
+	name, _ := getString(params, "name")
+	checkIn, _ := params["check_in"].(bool)
+	filters, err := a.db.ListFilters()
+	if err != nil {
+		return nil, err
+	}
+	if name == "" && !checkIn {
+		return map[string]any{"filters": filters}, nil
+	}
+
+	today := time.Now().In(a.userLocation()).Format(time.DateOnly)
+	var results []map[string]any
+	for _, f := range filters {
+		if (name != "" && f.Name != strings.ToLower(name)) || (name == "" && !f.CheckIn) {
+			continue
+		}
+		things, total, err := a.db.RunFilter(f, today)
+		if err != nil {
+			return nil, fmt.Errorf("running filter %s: %w", f.Name, err)
+		}
+		results = append(results, map[string]any{"name": f.Name, "things": things, "total": total})
+	}
+	if name != "" && len(results) == 0 {
+		return nil, fmt.Errorf("no saved filter named %q", name)
+	}
+	return map[string]any{"results": results}, nil
+}
+
+// saveMemories saves an array of memories in one transaction. Entries
+// without tags are auto-tagged, and memory approval applies as in
+// save_memory.
+func (a *Agent) saveMemories(params map[string]any) (any, error) {
+	items, _ := params["memories"].([]any)
+	if len(items) == 0 {
+		return nil, fmt.Errorf("memories must be a non-empty array")
+	}
+	status := "saved"
+	if a.memoryApproval {
+		status = "proposed"
 	}
-	return s[:n] + "..."
+	mems := make([]db.NewMemory, len(items))
+	autoTags := map[int][]string{}
+	for i, item := range items {
+		m, _ := item.(map[string]any)
+		var nm db.NewMemory
+		nm.Content, _ = getString(m, "content")
+		nm.Category, _ = getString(m, "category")
+		nm.ExpiresAt, _ = getString(m, "expires_at")
+		nm.Tags = getStrings(m, "tags")
+		if nm.Content == "" || nm.Category == "" {
+			return nil, fmt.Errorf("memory %d needs content and category", i+1)
+		}
+		if err := db.CheckValue("memories", "category", nm.Category); err != nil {
+			return nil, fmt.Errorf("memory %d: %w", i+1, err)
+		}
+		if v, ok := getInt(m, "thing_id"); ok {
+			nm.ThingID = &v
+		}
+		if len(nm.Tags) == 0 {
+			if nm.Tags = a.inferTags(nm.Content); len(nm.Tags) > 0 {
+				autoTags[i] = nm.Tags
+			}
+		}
+		nm.Source = "agent"
+		if a.memoryApproval {
+			nm.Status = "proposed"
+		}
+		mems[i] = nm
+	}
+	saved, err := a.db.BulkSaveMemories(mems)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]map[string]any, len(saved))
+	dups := 0
+	for i, s := range saved {
+		r := map[string]any{"id": s.ID}
+		if s.Duplicate {
+			r["duplicate"] = true
+			dups++
+		}
+		if tags := autoTags[i]; len(tags) > 0 {
+			r["auto_tags"] = tags
+		}
+		results[i] = r
+	}
+	return map[string]any{"status": status, "count": len(saved) - dups, "duplicates": dups, "memories": results}, nil
+}
+
+// copyToolFields copies the table's scalar tool fields (db.ToolFields,
+// generated from schema.sql) from params into fields.
+func copyToolFields(table string, params, fields map[string]any) {
+	for _, k := range db.ToolFields[table] {
+		if v, ok := params[k]; ok {
+			fields[k] = v
+		}
+	}
+}
+
+// boolFields copies boolean params for keys into fields as the 0/1 integers
+// SQLite stores.
+func boolFields(params, fields map[string]any, keys ...string) {
+	for _, k := range keys {
+		if b, ok := params[k].(bool); ok {
+			if b {
+				fields[k] = 1
+			} else {
+				fields[k] = 0
+			}
+		}
+	}
+}
+
+// getStrings returns the string elements of an array param.
+func getStrings(params map[string]any, key string) []string {
+	arr, _ := params[key].([]any)
+	var out []string
+	for _, v := range arr {
+		if s, ok := v.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// Location returns the user's timezone, for callers outside the agent that
+// need the user's local date (e.g. scheduled jobs).
+func (a *Agent) Location() *time.Location {
+	return a.userLocation()
 }
 
 // userLocation returns the user's timezone from the "timezone" note,
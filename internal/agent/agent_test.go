@@ -1,8 +1,16 @@
 package agent
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/chris/jot/internal/db"
+	"github.com/chris/jot/internal/llm"
 )
 
 // --- getInt ---
@@ -96,32 +104,826 @@ func TestGetString_EmptyString(t *testing.T) {
 	}
 }
 
-// --- truncate ---
+// --- idempotency ---
+
+func openTestAgent(t *testing.T) *Agent {
+	t.Helper()
+	d, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatalf("opening test db: %v", err)
+	}
+	t.Cleanup(func() { d.Close() })
+	return New(d, nil, 180000)
+}
+
+func TestIdempotencyKey_ParamOrder(t *testing.T) {
+	a := idempotencyKey("turn", "create_thing", map[string]any{"title": "x", "priority": "high"})
+	b := idempotencyKey("turn", "create_thing", map[string]any{"priority": "high", "title": "x"})
+	if a != b {
+		t.Error("expected same key regardless of param order")
+	}
+}
+
+func TestIdempotencyKey_Distinct(t *testing.T) {
+	base := idempotencyKey("turn", "create_thing", map[string]any{"title": "x"})
+	tests := []struct {
+		name string
+		key  string
+	}{
+		{"different turn", idempotencyKey("other turn", "create_thing", map[string]any{"title": "x"})},
+		{"different tool", idempotencyKey("turn", "save_memory", map[string]any{"title": "x"})},
+		{"different params", idempotencyKey("turn", "create_thing", map[string]any{"title": "y"})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.key == base {
+				t.Error("expected distinct key")
+			}
+		})
+	}
+}
+
+func TestExecuteToolOnce_DedupesCreate(t *testing.T) {
+	a := openTestAgent(t)
+	ctx := context.Background()
+	params := map[string]any{"title": "buy milk"}
+
+	first := a.executeToolOnce(ctx, "track buy milk", "create_thing", params)
+	second := a.executeToolOnce(ctx, "track buy milk", "create_thing", params)
+
+	things, _ := a.db.ListThings("", "", "")
+	if len(things) != 1 {
+		t.Fatalf("expected 1 thing after duplicate call, got %d", len(things))
+	}
+	if strings.Contains(first, "duplicate") {
+		t.Errorf("first call should not be marked duplicate: %s", first)
+	}
+	if !strings.Contains(second, `"duplicate":true`) {
+		t.Errorf("second call should be marked duplicate: %s", second)
+	}
+
+	// A new turn with the same params creates a second thing.
+	a.executeToolOnce(ctx, "track buy milk again", "create_thing", params)
+	things, _ = a.db.ListThings("", "", "")
+	if len(things) != 2 {
+		t.Errorf("expected 2 things after a new turn, got %d", len(things))
+	}
+}
+
+func TestRun_SameMessageTwoTurns(t *testing.T) {
+	a := openTestAgent(t)
+	create := llm.ToolCall{ID: "1", Name: "create_thing", Params: map[string]any{"title": "buy milk"}}
+	a.client = &scriptedClient{responses: []*llm.Response{
+		// First turn: the model repeats the call, which is replayed.
+		{ToolCalls: []llm.ToolCall{create}},
+		{ToolCalls: []llm.ToolCall{create}},
+		{Content: "Added."},
+		// Second turn, same message: a new thing.
+		{ToolCalls: []llm.ToolCall{create}},
+		{Content: "Added."},
+	}}
+	ctx := WithProfile(context.Background(), ProfileCLI)
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := a.Run(ctx, nil, "add milk"); err != nil {
+			t.Fatalf("Run %d: %v", i, err)
+		}
+	}
+	if things, _ := a.db.ListThings("", "", ""); len(things) != 2 {
+		t.Errorf("expected 2 things from two turns, got %d", len(things))
+	}
+}
+
+func TestAuditAndChangesDigest(t *testing.T) {
+	a := openTestAgent(t)
+	ctx := withUser(WithProfile(context.Background(), ProfileSchedule), "u1")
+	since := time.Now().Add(-time.Hour)
+
+	params := map[string]any{"title": "Renew passport"}
+	a.executeToolOnce(ctx, "turn", "create_thing", params)
+	a.executeToolOnce(ctx, "turn", "create_thing", params) // replayed, not logged
+	a.executeToolOnce(ctx, "turn", "update_thing", map[string]any{"id": float64(1), "priority": "high", "status": "active"})
+	a.executeToolOnce(ctx, "turn", "list_things", map[string]any{})               // read
+	a.executeToolOnce(ctx, "turn", "forget", map[string]any{"query": "passport"}) // preview
+	a.executeToolOnce(ctx, "turn", "delete_schedule", map[string]any{"name": "x"})
+	a.executeToolOnce(ctx, "turn", "update_schedule", map[string]any{"name": "missing", "prompt": "x"}) // error
+	a.executeToolOnce(ctx, "turn", "update_thing", map[string]any{"id": float64(1)})                    // no fields
+
+	entries, err := a.db.ListAudit(since)
+	if err != nil {
+		t.Fatalf("ListAudit: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 audit entries, got %+v", entries)
+	}
+	if e := entries[0]; e.Action != "create" || e.Target != "thing #1" || e.Detail != "Renew passport" || e.Profile != ProfileSchedule {
+		t.Errorf("create entry = %+v", e)
+	}
+	if e := entries[1]; e.Action != "update" || e.Detail != "set priority, status" {
+		t.Errorf("update entry = %+v", e)
+	}
+
+	digest, err := a.ChangesDigest(since)
+	if err != nil {
+		t.Fatalf("ChangesDigest: %v", err)
+	}
+	for _, want := range []string{"(3)", "created thing #1: Renew passport (schedule)", "deleted schedule x"} {
+		if !strings.Contains(digest, want) {
+			t.Errorf("digest missing %q:\n%s", want, digest)
+		}
+	}
+	if digest, _ := a.ChangesDigest(time.Now().Add(time.Hour)); !strings.Contains(digest, "none") {
+		t.Errorf("empty digest = %q", digest)
+	}
+}
+
+func TestAuditForget(t *testing.T) {
+	a := openTestAgent(t)
+	ctx := withUser(context.Background(), "u1")
+
+	a.executeToolOnce(ctx, "turn", "create_thing", map[string]any{"title": "Call Initech recruiter"})
+	a.executeToolOnce(ctx, "turn", "save_memory", map[string]any{"content": "Interviewing at Initech", "category": "event"})
+	token := forgetPreview(t, a, ctx, "Initech")
+	later, turn := withTurnState(ctx)
+	a.executeToolOnce(later, turn.id, "forget", map[string]any{"query": "Initech", "confirm": token})
+
+	entries, err := a.db.ListAudit(time.Time{})
+	if err != nil {
+		t.Fatalf("ListAudit: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Tool != "forget" || entries[0].Target != "4 items" {
+		t.Fatalf("expected only the forget entry, logged by count, got %+v", entries)
+	}
+	for _, e := range entries {
+		if strings.Contains(e.Target+e.Detail, "Initech") {
+			t.Errorf("audit log still mentions the forgotten query: %+v", e)
+		}
+	}
+}
+
+// --- memory review ---
+
+func TestParseReviewCommand(t *testing.T) {
+	tests := []struct {
+		input    string
+		wantArgs string
+		wantOK   bool
+	}{
+		{"!memories", "", true},
+		{"  !memories approve 1 2 ", "approve 1 2", true},
+		{"!memoriesapprove", "", false},
+		{"show my memories", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			args, ok := ParseReviewCommand(tt.input)
+			if ok != tt.wantOK || args != tt.wantArgs {
+				t.Errorf("got (%q, %v), want (%q, %v)", args, ok, tt.wantArgs, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestParseReviewIDs(t *testing.T) {
+	ids, err := parseReviewIDs([]string{"#3,4", "7"})
+	if err != nil {
+		t.Fatalf("parseReviewIDs: %v", err)
+	}
+	if len(ids) != 3 || ids[0] != 3 || ids[1] != 4 || ids[2] != 7 {
+		t.Errorf("unexpected ids: %v", ids)
+	}
+
+	ids, err = parseReviewIDs([]string{"all"})
+	if err != nil || ids != nil {
+		t.Errorf("expected (nil, nil) for all, got (%v, %v)", ids, err)
+	}
+
+	if _, err := parseReviewIDs([]string{"abc"}); err == nil {
+		t.Error("expected error for non-numeric ID")
+	}
+	if _, err := parseReviewIDs(nil); err == nil {
+		t.Error("expected error for missing IDs")
+	}
+}
+
+func TestSaveMemoryApprovalMode(t *testing.T) {
+	a := openTestAgent(t)
+	a.SetMemoryApproval(true)
+
+	result := a.executeTool(context.Background(), "save_memory", map[string]any{
+		"content":  "prefers mornings for deep work",
+		"category": "preference",
+	})
+	if !strings.Contains(result, `"status":"proposed"`) {
+		t.Fatalf("expected proposed status, got %s", result)
+	}
+
+	list, err := a.ReviewMemories("")
+	if err != nil {
+		t.Fatalf("ReviewMemories(list): %v", err)
+	}
+	if !strings.Contains(list, "prefers mornings") {
+		t.Errorf("expected proposed memory in review list, got %q", list)
+	}
+
+	if _, err := a.ReviewMemories("approve all"); err != nil {
+		t.Fatalf("ReviewMemories(approve): %v", err)
+	}
+	recent, _ := a.db.ListRecentMemories("", 10)
+	if len(recent) != 1 {
+		t.Errorf("expected approved memory to be active, got %d", len(recent))
+	}
+}
+
+// --- forget ---
+
+func TestForgetPreviewThenConfirm(t *testing.T) {
+	a := openTestAgent(t)
+	a.db.SaveMemory("Lunch with Dana from Initech", "event", "agent", nil, nil, "")
+	ctx := withUser(context.Background(), "u1")
+
+	preview, turn1 := withTurnState(ctx)
+	token := forgetPreview(t, a, preview, "Initech")
+	if recent, _ := a.db.ListRecentMemories("", 10); len(recent) != 1 {
+		t.Fatal("preview must not delete anything")
+	}
+	// Matches saved after the preview aren't part of what the user agreed to.
+	a.db.SaveMemory("Initech offer arrived", "event", "agent", nil, nil, "")
+
+	confirm := map[string]any{"query": "Initech", "confirm": token}
+	if got := a.executeTool(preview, "forget", confirm); !strings.Contains(got, "error") {
+		t.Fatalf("confirming in the preview's turn should fail, got %s", got)
+	}
+	token = forgetPreview(t, a, preview, "Initech")
+	confirm["confirm"] = token
+
+	later, turn2 := withTurnState(ctx)
+	if turn2.id == turn1.id {
+		t.Fatal("turns should have distinct IDs")
+	}
+	if got := a.executeTool(withUser(later, "u2"), "forget", confirm); !strings.Contains(got, "error") {
+		t.Fatalf("another user's token should be rejected, got %s", got)
+	}
+
+	token = forgetPreview(t, a, preview, "Initech")
+	confirm["confirm"] = token
+	a.db.SaveMemory("Initech badge returned", "event", "agent", nil, nil, "")
+	done := a.executeTool(later, "forget", confirm)
+	if !strings.Contains(done, `"status":"forgotten"`) || !strings.Contains(done, `"memories":2`) {
+		t.Fatalf("expected the 2 previewed memories forgotten, got %s", done)
+	}
+	recent, _ := a.db.ListRecentMemories("", 10)
+	if len(recent) != 1 || recent[0].Content != "Initech badge returned" {
+		t.Errorf("expected only the memory saved after the preview to remain, got %+v", recent)
+	}
+	if got := a.executeTool(later, "forget", confirm); !strings.Contains(got, "error") {
+		t.Errorf("a token should only work once, got %s", got)
+	}
+}
+
+// forgetPreview runs a forget preview and returns its confirm token.
+func forgetPreview(t *testing.T, a *Agent, ctx context.Context, query string) string {
+	t.Helper()
+	var res struct {
+		Status string `json:"status"`
+		Token  string `json:"confirm_token"`
+	}
+	out := a.executeTool(ctx, "forget", map[string]any{"query": query})
+	if err := json.Unmarshal([]byte(out), &res); err != nil || res.Status != "preview" || res.Token == "" {
+		t.Fatalf("expected a preview with a confirm token, got %s", out)
+	}
+	return res.Token
+}
+
+func TestMarkHistoryPurged(t *testing.T) {
+	markHistoryPurged(context.Background()) // no turn state: must not panic
+
+	ctx, turn := withTurnState(context.Background())
+	markHistoryPurged(ctx)
+	if !turn.historyPurged {
+		t.Error("expected historyPurged to be set")
+	}
+}
+
+// --- auto-tagging ---
+
+func TestMatchTags(t *testing.T) {
+	vocab := []string{"house", "side-project", "book", "work", "health", "car", "news", "focus", "status"}
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Fix the gutter on the house", []string{"house"}},
+		{"Side project: finish the landing page", []string{"side-project"}},
+		{"Return library books", []string{"book"}},
+		{"Carpool schedule", nil}, // substring only, not a word
+		{"Read the news", []string{"news"}},
+		{"Buy a new kettle", nil}, // "news" is not the plural of "new"
+		{"Focus block after lunch", []string{"focus"}},
+		{"Send the status report", []string{"status"}},
+		{"house, work, health and car stuff", []string{"house", "work", "health"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got := matchTags(tt.text, vocab)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("matchTags(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateThingAutoTags(t *testing.T) {
+	a := openTestAgent(t)
+	a.db.CreateThing("Paint the fence", "", "", "", []string{"house"})
+
+	result := a.executeTool(context.Background(), "create_thing", map[string]any{"title": "Call plumber about the house"})
+	if !strings.Contains(result, `"auto_tags":["house"]`) {
+		t.Fatalf("expected auto_tags in result, got %s", result)
+	}
+	things, _ := a.db.ListThings("", "", "house")
+	if len(things) != 2 {
+		t.Errorf("expected 2 things tagged house, got %d", len(things))
+	}
+
+	// Explicit tags are never overridden.
+	result = a.executeTool(context.Background(), "create_thing", map[string]any{"title": "House insurance renewal", "tags": []any{"finance"}})
+	if strings.Contains(result, "auto_tags") {
+		t.Errorf("explicit tags should skip auto-tagging, got %s", result)
+	}
+}
+
+func TestCreateThingsBatch(t *testing.T) {
+	a := openTestAgent(t)
+
+	result := a.executeTool(context.Background(), "create_things", map[string]any{"things": []any{
+		map[string]any{"title": "Book movers", "priority": "high"},
+		map[string]any{"title": "Forward mail", "due_date": "2026-05-01"},
+		map[string]any{"title": "Cancel internet", "tags": []any{"move"}},
+	}})
+	if !strings.Contains(result, `"count":3`) {
+		t.Fatalf("expected 3 things created, got %s", result)
+	}
+	things, _ := a.db.ListThings("", "", "")
+	if len(things) != 3 {
+		t.Errorf("expected 3 things, got %d", len(things))
+	}
+
+	// A missing title rejects the whole batch.
+	result = a.executeTool(context.Background(), "create_things", map[string]any{"things": []any{
+		map[string]any{"title": "Pack books"},
+		map[string]any{"notes": "no title"},
+	}})
+	if !strings.Contains(result, "error") {
+		t.Fatalf("expected error for untitled thing, got %s", result)
+	}
+	if things, _ := a.db.ListThings("", "", ""); len(things) != 3 {
+		t.Errorf("expected no new things after rejected batch, got %d total", len(things))
+	}
+}
+
+func TestSaveMemoriesBatch(t *testing.T) {
+	a := openTestAgent(t)
+
+	result := a.executeTool(context.Background(), "save_memories", map[string]any{"memories": []any{
+		map[string]any{"content": "Fui al gimnasio", "category": "habit"},
+		map[string]any{"content": "Gym again", "category": "habit"},
+		map[string]any{"content": "Chose Postgres for the new service", "category": "decision"},
+		map[string]any{"content": "Chose Postgres for the new service", "category": "decision"},
+	}})
+	if !strings.Contains(result, `"count":3`) || !strings.Contains(result, `"duplicates":1`) {
+		t.Fatalf("expected 3 saved and 1 duplicate, got %s", result)
+	}
+	if gym, _ := a.db.SearchMemories("", "habit", "gym", nil, "", 10); len(gym) != 2 {
+		t.Errorf("expected both habit logs tagged gym, got %d", len(gym))
+	}
+
+	result = a.executeTool(context.Background(), "save_memories", map[string]any{"memories": []any{
+		map[string]any{"content": "no category"},
+	}})
+	if !strings.Contains(result, "error") {
+		t.Errorf("expected an error for a memory without a category, got %s", result)
+	}
+
+	result = a.executeTool(context.Background(), "save_memories", map[string]any{"memories": []any{
+		map[string]any{"content": "Bought milk", "category": "errand"},
+	}})
+	if !strings.Contains(result, "must be one of") {
+		t.Errorf("expected an error for an unknown category, got %s", result)
+	}
+}
+
+func TestDescribeDataModel(t *testing.T) {
+	a := openTestAgent(t)
+	result := a.executeTool(context.Background(), "describe_data_model", map[string]any{})
+	for _, want := range []string{`"name":"things"`, `"values":["open","active","done","dropped"]`, `"memories.thing_id = things.id"`} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected %s in %s", want, result)
+		}
+	}
+}
+
+func TestListCapabilities(t *testing.T) {
+	a := openTestAgent(t)
+	a.SetIntegrations(Integrations{DiscordBot: true})
+	if err := a.db.DenyTool(ProfileGuild, "delete_*"); err != nil {
+		t.Fatal(err)
+	}
+
+	result := a.executeTool(context.Background(), "list_capabilities", map[string]any{})
+	for _, want := range []string{`"area":"Things"`, `"Create several things at once (e.g. the steps of a project)."`, `"name":"Discord bot","enabled":true`, `"name":"Discord webhook","enabled":false`} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected %s in %s", want, result)
+		}
+	}
+	if strings.Contains(result, "unavailable") {
+		t.Errorf("expected every tool available without a profile, got %s", result)
+	}
+
+	result = a.executeTool(WithProfile(context.Background(), ProfileGuild), "list_capabilities", map[string]any{})
+	if !strings.Contains(result, `"unavailable":["delete_memory","delete_schedule","delete_watch"]`) || strings.Contains(result, "Delete a memory") {
+		t.Errorf("expected delete tools listed as unavailable, got %s", result)
+	}
+}
+
+func TestCapabilityAreasCoverTools(t *testing.T) {
+	area := make(map[string]string)
+	for _, a := range capabilityAreas {
+		for _, name := range a.tools {
+			if prev, ok := area[name]; ok {
+				t.Errorf("%s is in both %s and %s", name, prev, a.name)
+			}
+			area[name] = a.name
+		}
+	}
+	for _, tool := range llm.AgentTools {
+		if _, ok := area[tool.Name]; !ok {
+			t.Errorf("%s is not in any capability area", tool.Name)
+		}
+		delete(area, tool.Name)
+	}
+	for name := range area {
+		t.Errorf("capability area lists unknown tool %s", name)
+	}
+}
+
+func TestListThingsPaging(t *testing.T) {
+	a := openTestAgent(t)
+	for _, title := range []string{"a", "b", "c"} {
+		a.db.CreateThing(title, "", "", "", nil)
+	}
+
+	// Everything fits: the plain list shape is unchanged.
+	result := a.executeTool(context.Background(), "list_things", map[string]any{})
+	if !strings.HasPrefix(result, "[") {
+		t.Errorf("expected plain list, got %s", result)
+	}
+
+	result = a.executeTool(context.Background(), "list_things", map[string]any{"sort": "oldest", "limit": float64(2)})
+	if !strings.Contains(result, `"total":3`) || !strings.Contains(result, `"title":"b"`) || strings.Contains(result, `"title":"c"`) {
+		t.Errorf("expected first page of 2 with total 3, got %s", result)
+	}
+}
+
+func TestRunFilterCheckIn(t *testing.T) {
+	a := openTestAgent(t)
+	a.db.CreateThing("Buy stamps", "", "", "", []string{"errands"})
+	a.db.CreateThing("Write report", "", "", "", []string{"work"})
+
+	a.executeTool(context.Background(), "save_filter", map[string]any{"name": "errands", "tags": []any{"errands"}, "check_in": true})
+	a.executeTool(context.Background(), "save_filter", map[string]any{"name": "work", "tags": []any{"work"}})
+
+	result := a.executeTool(context.Background(), "run_filter", map[string]any{"check_in": true})
+	if !strings.Contains(result, "Buy stamps") || strings.Contains(result, "Write report") {
+		t.Errorf("expected only the check-in filter to run, got %s", result)
+	}
+	result = a.executeTool(context.Background(), "run_filter", map[string]any{"name": "Work"})
+	if !strings.Contains(result, "Write report") {
+		t.Errorf("expected work filter results, got %s", result)
+	}
+	result = a.executeTool(context.Background(), "run_filter", map[string]any{"name": "missing"})
+	if !strings.Contains(result, "error") {
+		t.Errorf("expected error for unknown filter, got %s", result)
+	}
+}
+
+func TestCompletedThingsDefaultsToLastWeek(t *testing.T) {
+	a := openTestAgent(t)
+	recent, _ := a.db.CreateThing("Ship it", "", "", "", nil)
+	a.db.CompleteThing(recent)
+	old, _ := a.db.CreateThing("Ancient history", "", "", "", nil)
+	a.db.UpdateThing(old, map[string]any{"status": "done", "completed_at": "2020-01-01 00:00:00"})
+
+	result := a.executeTool(context.Background(), "completed_things", map[string]any{})
+	if !strings.Contains(result, `"count":1`) || !strings.Contains(result, "Ship it") {
+		t.Errorf("expected only the recent completion, got %s", result)
+	}
+	result = a.executeTool(context.Background(), "completed_things", map[string]any{"from": "2019-12-31", "to": "2020-01-02"})
+	if !strings.Contains(result, "Ancient history") {
+		t.Errorf("expected explicit range to include old completion, got %s", result)
+	}
+	result = a.executeTool(context.Background(), "completed_things", map[string]any{"from": "last week"})
+	if !strings.Contains(result, "error") {
+		t.Errorf("expected error for bad date, got %s", result)
+	}
+}
+
+func TestBuildCheckInPrompt(t *testing.T) {
+	a := openTestAgent(t)
+	a.db.SaveMemory("waiting on legal sign-off", "blocker", "agent", nil, nil, "")
+
+	prompt, err := a.BuildCheckInPrompt(db.Schedule{Prompt: "Perform a morning check-in.", CheckIn: true})
+	if err != nil {
+		t.Fatalf("BuildCheckInPrompt: %v", err)
+	}
+	if !strings.HasPrefix(prompt, "Perform a morning check-in.") {
+		t.Errorf("expected schedule prompt first, got %q", prompt)
+	}
+	if !strings.Contains(prompt, "[Check-in context]") || !strings.Contains(prompt, "Memory: 1 memories: 1 blocker") {
+		t.Errorf("expected memory stats line, got %q", prompt)
+	}
+	if strings.Contains(prompt, "Blockers unresolved") {
+		t.Errorf("expected no escalation for a fresh blocker, got %q", prompt)
+	}
+	if !strings.Contains(prompt, "Memories from the last 7 days") || !strings.Contains(prompt, "[blocker] waiting on legal sign-off") {
+		t.Errorf("expected default memory window, got %q", prompt)
+	}
+}
+
+func TestBuildCheckInPromptBudget(t *testing.T) {
+	a := openTestAgent(t)
+	a.MaxContextTokens = 0 // floor: 1000-token message budget, 250 for check-in context
+	for i := range 40 {
+		a.db.SaveMemory(fmt.Sprintf("decision %d: %s", i, strings.Repeat("long rationale ", 40)), "decision", "agent", nil, nil, "")
+	}
+
+	prompt, err := a.BuildCheckInPrompt(db.Schedule{Prompt: "Check in.", CheckIn: true, MemoryLimit: 40})
+	if err != nil {
+		t.Fatalf("BuildCheckInPrompt: %v", err)
+	}
+	if !strings.Contains(prompt, "Memory: 40 memories") {
+		t.Errorf("expected stats line regardless of budget, got %q", prompt)
+	}
+	if !strings.Contains(prompt, "lower-ranked memories omitted for space") {
+		t.Errorf("expected omitted-memories note, got %q", prompt)
+	}
+	if n := llm.EstimateTokens(prompt); n > 300 {
+		t.Errorf("expected check-in prompt near the 250-token budget, got %d tokens", n)
+	}
+}
+
+func TestFilterTools(t *testing.T) {
+	denied := []string{"delete_*", "forget"}
+	tools := filterTools(llm.AgentTools, denied, false)
+	for _, tool := range tools {
+		if strings.HasPrefix(tool.Name, "delete_") || tool.Name == "forget" {
+			t.Errorf("expected %s to be filtered out", tool.Name)
+		}
+	}
+	if len(tools) == len(llm.AgentTools) {
+		t.Error("expected some tools to be filtered")
+	}
+	if !matchTool(denied, "delete_memory") || matchTool(denied, "update_memory") {
+		t.Error("matchTool mismatched glob")
+	}
+	if got := filterTools(llm.AgentTools, nil, false); len(got) != len(llm.AgentTools) {
+		t.Error("expected no filtering without denials")
+	}
+	if got := filterTools(llm.AgentTools, []string{"list_*"}, true); len(got) == 0 || !strings.HasPrefix(got[0].Name, "list_") {
+		t.Errorf("expected only list_* tools kept, got %v", got)
+	}
+}
+
+func TestStartJob(t *testing.T) {
+	a := openTestAgent(t)
 
-func TestTruncate_Short(t *testing.T) {
-	got := truncate("hello", 10)
-	if got != "hello" {
-		t.Errorf("expected 'hello', got %q", got)
+	result := a.executeTool(context.Background(), "start_job", map[string]any{"prompt": "Research standing desks", "title": "Desks"})
+	if !strings.Contains(result, `"status":"queued"`) {
+		t.Fatalf("expected queued job, got %s", result)
+	}
+	result = a.executeTool(WithProfile(context.Background(), ProfileJob), "start_job", map[string]any{"prompt": "Spawn more work"})
+	if !strings.Contains(result, "error") {
+		t.Errorf("expected jobs to refuse starting jobs, got %s", result)
+	}
+	result = a.executeTool(context.Background(), "list_jobs", map[string]any{"status": "queued"})
+	if !strings.Contains(result, "Desks") || strings.Contains(result, "Spawn more work") {
+		t.Errorf("expected only the first job queued, got %s", result)
+	}
+}
+
+// scriptedClient replays canned responses in order and records the tools
+// offered on each call.
+type scriptedClient struct {
+	responses []*llm.Response
+	tools     [][]string
+}
+
+func (c *scriptedClient) Chat(ctx context.Context, systemPrompt string, messages []llm.Message, tools []llm.Tool, opts ...llm.ChatOption) (*llm.Response, error) {
+	var names []string
+	for _, t := range tools {
+		names = append(names, t.Name)
+	}
+	c.tools = append(c.tools, names)
+	if len(c.responses) == 0 {
+		return &llm.Response{Content: "done"}, nil
+	}
+	resp := c.responses[0]
+	c.responses = c.responses[1:]
+	return resp, nil
+}
+
+func (c *scriptedClient) Generate(ctx context.Context, prompt string, schema llm.Schema) (json.RawMessage, error) {
+	return nil, fmt.Errorf("not scripted")
+}
+
+func TestSpawnTask(t *testing.T) {
+	a := openTestAgent(t)
+	client := &scriptedClient{responses: []*llm.Response{
+		{ToolCalls: []llm.ToolCall{{ID: "1", Name: "spawn_task", Params: map[string]any{"task": "What's stuck in the house stuff?"}}}},
+		// Sub-agent: tries a tool outside its read-only set, then summarizes.
+		{ToolCalls: []llm.ToolCall{{ID: "2", Name: "create_thing", Params: map[string]any{"title": "sneaky"}}}},
+		{Content: "Nothing is stuck."},
+		{Content: "All clear: nothing is stuck."},
+	}}
+	a.client = client
+
+	reply, _, err := a.Run(WithProfile(context.Background(), ProfileCLI), nil, "how's the house stuff?")
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if reply != "All clear: nothing is stuck." {
+		t.Errorf("unexpected reply %q", reply)
+	}
+	if things, _ := a.db.ListThings("", "", ""); len(things) != 0 {
+		t.Errorf("expected the sub-agent's write to be refused, got %+v", things)
+	}
+	sub := strings.Join(client.tools[1], ",")
+	if strings.Contains(sub, "create_thing") || strings.Contains(sub, "spawn_task") || !strings.Contains(sub, "search_memories") {
+		t.Errorf("expected read-only sub-agent tools, got %s", sub)
+	}
+	if !strings.Contains(strings.Join(client.tools[3], ","), "spawn_task") {
+		t.Error("expected the main agent to keep its full tool set")
+	}
+}
+
+func TestSpawnTask_CannotWidenTools(t *testing.T) {
+	a := openTestAgent(t)
+	client := &scriptedClient{responses: []*llm.Response{
+		{ToolCalls: []llm.ToolCall{{ID: "1", Name: "spawn_task", Params: map[string]any{
+			"task":  "Tidy up the house list",
+			"tools": []any{"create_*", "delete_thing", "list_things"},
+		}}}},
+		{ToolCalls: []llm.ToolCall{{ID: "2", Name: "create_thing", Params: map[string]any{"title": "sneaky"}}}},
+		{Content: "Couldn't change anything."},
+		{Content: "Done."},
+	}}
+	a.client = client
+
+	if _, _, err := a.Run(WithProfile(context.Background(), ProfileCLI), nil, "tidy the house list"); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if things, _ := a.db.ListThings("", "", ""); len(things) != 0 {
+		t.Errorf("expected the sub-agent's write to be refused, got %+v", things)
+	}
+	if sub := strings.Join(client.tools[1], ","); sub != "list_things" {
+		t.Errorf("sub-agent tools = %s, want only list_things", sub)
 	}
 }
 
-func TestTruncate_Exact(t *testing.T) {
-	got := truncate("hello", 5)
-	if got != "hello" {
-		t.Errorf("expected 'hello', got %q", got)
+func TestContextCard(t *testing.T) {
+	a := openTestAgent(t)
+	today := time.Now().In(a.Location())
+	a.db.CreateThing("Renew passport", "", "", today.AddDate(0, 0, -3).Format(time.DateOnly), nil)
+	a.db.CreateThing("Pay rent", "", "", today.Format(time.DateOnly), nil)
+	report, _ := a.db.CreateThing("Write report", "", "high", "", nil)
+	a.db.UpdateThing(report, map[string]any{"status": "active"})
+	a.db.SaveMemory("Partner's birthday is 3 March", "event", "user", []string{"pinned"}, nil, "")
+
+	card, err := a.ContextCard()
+	if err != nil {
+		t.Fatalf("ContextCard: %v", err)
+	}
+	for _, want := range []string{
+		"[Context card for " + today.Format("Monday 2006-01-02") + "]",
+		"Things: 3 open (1 active), 1 overdue, 1 due today",
+		"Overdue: #1 Renew passport (due ",
+		"Due today: #2 Pay rent",
+		"Active: #3 Write report (high)",
+		"Pinned: #1 Partner's birthday is 3 March",
+	} {
+		if !strings.Contains(card, want) {
+			t.Errorf("expected %q in card:\n%s", want, card)
+		}
+	}
+
+	// Cached for the rest of the day.
+	a.db.CreateThing("Late addition", "", "", today.Format(time.DateOnly), nil)
+	if again, _ := a.ContextCard(); again != card {
+		t.Errorf("expected cached card, got:\n%s", again)
+	}
+	a.db.SetNote(contextCardNote, `{"date":"2000-01-01","card":"stale"}`)
+	if fresh, _ := a.ContextCard(); !strings.Contains(fresh, "Late addition") {
+		t.Errorf("expected rebuilt card on a new day, got:\n%s", fresh)
+	}
+
+	// Purging a pinned memory drops it from the cached card the same day.
+	c, _ := a.db.FindPurgeCandidates("birthday")
+	if _, err := a.db.Purge(c); err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	if purged, _ := a.ContextCard(); strings.Contains(purged, "birthday") || !strings.Contains(purged, "Late addition") {
+		t.Errorf("expected the purged memory gone from the card, got:\n%s", purged)
 	}
 }
 
-func TestTruncate_Long(t *testing.T) {
-	got := truncate("hello world", 5)
-	if got != "hello..." {
-		t.Errorf("expected 'hello...', got %q", got)
+func TestSetStyle(t *testing.T) {
+	a := openTestAgent(t)
+	ctx := withUser(context.Background(), "u1")
+
+	if got := a.systemPrompt(ctx); got != llm.SystemPrompt {
+		t.Error("expected the plain system prompt before any style is set")
+	}
+	a.executeTool(ctx, "set_style", map[string]any{"tone": "terse", "emoji": false})
+	a.executeTool(ctx, "set_style", map[string]any{"notes": "be more blunt with me"})
+
+	prompt := a.systemPrompt(ctx)
+	for _, want := range []string{"Be terse", "Never use emoji", "be more blunt with me"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("system prompt missing %q", want)
+		}
+	}
+	if got := a.systemPrompt(withUser(context.Background(), "u2")); got != llm.SystemPrompt {
+		t.Error("one user's style should not apply to another")
+	}
+
+	if res := a.executeTool(ctx, "set_style", map[string]any{"tone": "shouty"}); !strings.Contains(res, "unknown tone") {
+		t.Errorf("expected an error for an unknown tone, got %s", res)
+	}
+	a.executeTool(ctx, "set_style", map[string]any{"plain": true})
+	if !a.PlainText("u1") || a.PlainText("u2") {
+		t.Error("expected plain text for u1 only")
+	}
+	a.executeTool(ctx, "set_style", map[string]any{"reset": true})
+	if got := a.systemPrompt(ctx); got != llm.SystemPrompt {
+		t.Error("reset should clear the style")
+	}
+	if a.PlainText("u1") {
+		t.Error("reset should clear plain text")
 	}
 }
 
-func TestTruncate_Empty(t *testing.T) {
-	got := truncate("", 5)
-	if got != "" {
-		t.Errorf("expected '', got %q", got)
+func TestSynonyms(t *testing.T) {
+	a := openTestAgent(t)
+	a.SetSynonyms(map[string]map[string][]string{"es": {"gym": {"gym del barrio"}}})
+
+	if got := a.synonyms.Canonical("Gimnasio"); got != "gym" {
+		t.Errorf("Canonical(Gimnasio) = %q, want gym", got)
+	}
+	if got := a.synonyms.Canonical("errands"); got != "errands" {
+		t.Errorf("Canonical(errands) = %q, want it unchanged", got)
+	}
+
+	params := map[string]any{"title": "Pay rent", "tags": []any{"casa", "home", "bills"}}
+	a.executeTool(context.Background(), "create_thing", params)
+	if got := getStrings(params, "tags"); !slices.Equal(got, []string{"home", "bills"}) {
+		t.Errorf("tags = %v, want [home bills]", got)
+	}
+
+	// English words that are also foreign variants elsewhere stay put.
+	for _, tag := range []string{"training", "lecture"} {
+		if got := a.synonyms.Canonical(tag); got != tag {
+			t.Errorf("Canonical(%s) = %q, want it unchanged", tag, got)
+		}
+	}
+
+	// A thing tagged before synonyms applied is still found by its old tag.
+	a.db.CreateThing("Renew membership", "", "", "", []string{"gimnasio"})
+	result := a.executeTool(context.Background(), "list_things", map[string]any{"tag": "gimnasio"})
+	if !strings.Contains(result, "Renew membership") {
+		t.Errorf("filter by pre-existing tag gimnasio: %s", result)
+	}
+	// So is a saved filter or area for it.
+	a.executeTool(context.Background(), "save_filter", map[string]any{"name": "membership", "tags": []any{"gimnasio"}})
+	if result := a.executeTool(context.Background(), "run_filter", map[string]any{"name": "membership"}); !strings.Contains(result, "Renew membership") {
+		t.Errorf("saved filter for pre-existing tag gimnasio: %s", result)
+	}
+	areaParams := map[string]any{"name": "health", "tags": []any{"gimnasio"}}
+	a.executeTool(context.Background(), "set_area", areaParams)
+	if got := getStrings(areaParams, "tags"); !slices.Equal(got, []string{"gimnasio"}) {
+		t.Errorf("set_area tags = %v, want [gimnasio]", got)
+	}
+
+	for content, want := range map[string][]string{
+		"Fui al gym del barrio": {"gym"},
+		"Heute 5km laufen":      {"run"},
+		"Gym, then a walk":      {"gym", "walk"},
+		"Called mum":            nil,
+	} {
+		params := map[string]any{"content": content, "category": "habit"}
+		a.executeTool(context.Background(), "save_memory", params)
+		if got := getStrings(params, "tags"); !slices.Equal(got, want) {
+			t.Errorf("%q: tags = %v, want %v", content, got, want)
+		}
 	}
 }
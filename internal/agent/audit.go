@@ -0,0 +1,167 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/chris/jot/internal/db"
+)
+
+// auditSpec says how a write tool is logged: the action and the kind of row
+// it writes.
+type auditSpec struct {
+	action string
+	noun   string
+}
+
+// auditedTools are the tools that write. Each successful call is logged to
+// audit_log for the daily changes digest.
+var auditedTools = map[string]auditSpec{
+	"create_thing":    {"create", "thing"},
+	"create_things":   {"create", "things"},
+	"update_thing":    {"update", "thing"},
+	"complete_thing":  {"update", "thing"},
+	"save_filter":     {"update", "filter"},
+	"set_area":        {"update", "area"},
+	"save_memory":     {"create", "memory"},
+	"save_memories":   {"create", "memories"},
+	"update_memory":   {"update", "memory"},
+	"delete_memory":   {"delete", "memory"},
+	"forget":          {"delete", "items"},
+	"set_style":       {"update", "style"},
+	"create_schedule": {"create", "schedule"},
+	"update_schedule": {"update", "schedule"},
+	"delete_schedule": {"delete", "schedule"},
+	"start_job":       {"create", "job"},
+	"create_watch":    {"create", "watch"},
+	"update_watch":    {"update", "watch"},
+	"delete_watch":    {"delete", "watch"},
+}
+
+// audit logs a write tool call that succeeded. Previews, duplicates, and
+// errors changed nothing and are skipped.
+func (a *Agent) audit(ctx context.Context, name string, params map[string]any, result string) {
+	spec, ok := auditedTools[name]
+	if !ok {
+		return
+	}
+	var res map[string]any
+	if json.Unmarshal([]byte(result), &res) != nil {
+		return
+	}
+	if _, failed := res["error"]; failed || res["duplicate"] == true || res["status"] == "preview" {
+		return
+	}
+	action := spec.action
+	if res["status"] == "deleted" {
+		action = "delete" // save_filter and set_area with delete
+	}
+	detail := auditDetail(name, action, params)
+	if action == "update" && detail == "" {
+		return // no fields given, nothing changed
+	}
+	e := db.AuditEntry{
+		Tool:    name,
+		Action:  action,
+		Target:  auditTarget(spec.noun, params, res),
+		Detail:  detail,
+		Profile: profileFrom(ctx),
+	}
+	if err := a.db.LogAudit(e); err != nil {
+		log.Printf("audit %s: %v", name, err)
+	}
+}
+
+// auditTarget names what was written: "thing #12", "schedule evening-review",
+// "3 things". A forget is logged by count only; its query is what the user
+// asked jot not to keep.
+func auditTarget(noun string, params, res map[string]any) string {
+	if n, ok := res["count"].(float64); ok && strings.HasSuffix(noun, "s") {
+		return fmt.Sprintf("%d %s", int(n), noun)
+	}
+	if deleted, ok := res["deleted"].(map[string]any); ok {
+		var n int
+		for _, v := range deleted {
+			if f, ok := v.(float64); ok {
+				n += int(f)
+			}
+		}
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	if name, ok := getString(params, "name"); ok {
+		return noun + " " + name
+	}
+	if id, ok := getInt(params, "id"); ok {
+		return fmt.Sprintf("%s #%d", noun, id)
+	}
+	if id, ok := getInt(res, "id"); ok {
+		return fmt.Sprintf("%s #%d", noun, id)
+	}
+	return noun
+}
+
+// auditDetail describes the change: the title or content of what was created,
+// or the fields an update set.
+func auditDetail(name, action string, params map[string]any) string {
+	switch {
+	case name == "complete_thing":
+		return "marked done"
+	case action == "create":
+		for _, k := range []string{"title", "content", "prompt"} {
+			if s, ok := getString(params, k); ok {
+				return db.Truncate(strings.Join(strings.Fields(s), " "), 80)
+			}
+		}
+	case action == "update":
+		var fields []string
+		for k := range params {
+			if k != "id" && k != "name" {
+				fields = append(fields, k)
+			}
+		}
+		slices.Sort(fields)
+		if len(fields) > 0 {
+			return "set " + strings.Join(fields, ", ")
+		}
+	}
+	return ""
+}
+
+// ChangesDigest lists the writes the agent made since the given time, one
+// line each in the user's timezone, for appending to a scheduled reply.
+func (a *Agent) ChangesDigest(since time.Time) (string, error) {
+	entries, err := a.db.ListAudit(since)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) == 0 {
+		return "**Changes in the last 24 hours:** none. jot didn't create, update, or delete anything.", nil
+	}
+	const maxLines = 30
+	loc := a.userLocation()
+	var b strings.Builder
+	fmt.Fprintf(&b, "**Changes in the last 24 hours** (%d):", len(entries))
+	for i, e := range entries {
+		if i == maxLines {
+			fmt.Fprintf(&b, "\n…and %d more", len(entries)-maxLines)
+			break
+		}
+		at := e.CreatedAt
+		if t, err := time.Parse(time.DateTime, e.CreatedAt); err == nil {
+			at = t.In(loc).Format("15:04")
+		}
+		fmt.Fprintf(&b, "\n- %s %sd %s", at, e.Action, e.Target)
+		if e.Detail != "" {
+			fmt.Fprintf(&b, ": %s", e.Detail)
+		}
+		if e.Profile != "" && e.Profile != ProfileDiscord && e.Profile != ProfileCLI {
+			fmt.Fprintf(&b, " (%s)", e.Profile)
+		}
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,62 @@
+package agent
+
+import (
+	"log"
+	"strings"
+	"unicode"
+)
+
+// maxAutoTags caps how many tags are inferred for a single item.
+const maxAutoTags = 3
+
+// inferTags picks tags from the existing vocabulary whose words all appear in
+// text, so new items join the user's taxonomy without the LLM having to
+// remember it. Returns nil if the vocabulary can't be loaded.
+func (a *Agent) inferTags(text string) []string {
+	vocab, err := a.db.ListTags()
+	if err != nil {
+		log.Printf("auto-tag: loading tag vocabulary: %v", err)
+		return nil
+	}
+	return matchTags(text, vocab)
+}
+
+// matchTags returns up to maxAutoTags tags from vocab (in vocab order) whose
+// words all occur in text. Multi-word tags like "side-project" split on
+// hyphens and underscores. A tag word also matches its plural with an "s",
+// so "books" matches "book"; words are never stemmed, so "news" doesn't match
+// "new".
+func matchTags(text string, vocab []string) []string {
+	words := make(map[string]bool)
+	for _, w := range tagWords(text) {
+		words[w] = true
+	}
+	var tags []string
+	for _, tag := range vocab {
+		parts := tagWords(tag)
+		if len(parts) == 0 {
+			continue
+		}
+		all := true
+		for _, p := range parts {
+			if !words[p] && !words[p+"s"] {
+				all = false
+				break
+			}
+		}
+		if all {
+			tags = append(tags, tag)
+			if len(tags) == maxAutoTags {
+				break
+			}
+		}
+	}
+	return tags
+}
+
+// tagWords lowercases s and splits it into words.
+func tagWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,120 @@
+package agent
+
+import (
+	"context"
+	"strings"
+
+	"github.com/chris/jot/internal/llm"
+)
+
+// Integrations says which outside services this jot process is connected to.
+type Integrations struct {
+	DiscordBot bool // running as a Discord bot, so the scheduler runs
+	Webhook    bool // DISCORD_WEBHOOK_URL is set
+}
+
+// SetIntegrations records the connected services for list_capabilities.
+func (a *Agent) SetIntegrations(in Integrations) {
+	a.integrations = in
+}
+
+// capabilityAreas groups the agent tools by what they're for. Every tool in
+// llm.AgentTools belongs to exactly one area; a test checks this, so adding a
+// tool means placing it here.
+var capabilityAreas = []struct {
+	name  string
+	tools []string
+}{
+	{"Things", []string{"create_thing", "create_things", "update_thing", "complete_thing", "list_things", "get_summary", "completed_things", "list_thing_events", "save_filter", "run_filter", "list_areas", "set_area"}},
+	{"Memory", []string{"save_memory", "save_memories", "search_memories", "list_recent_memories", "get_memory_stats", "update_memory", "delete_memory", "forget", "search_conversations", "set_style"}},
+	{"Schedules and reminders", []string{"create_schedule", "list_schedules", "update_schedule", "delete_schedule"}},
+	{"Background work", []string{"start_job", "list_jobs", "spawn_task"}},
+	{"Web watches", []string{"create_watch", "list_watches", "update_watch", "delete_watch", "run_watch", "list_watch_results"}},
+	{"About jot", []string{"describe_data_model", "list_capabilities"}},
+}
+
+// Capability is one area of what jot can do, described by the first
+// sentence of each available tool's description.
+type Capability struct {
+	Area string   `json:"area"`
+	Can  []string `json:"can"`
+}
+
+// Integration is a connected (or missing) outside service and what it means
+// for the user.
+type Integration struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	Note    string `json:"note"`
+}
+
+// Capabilities is the list_capabilities result.
+type Capabilities struct {
+	Areas        []Capability  `json:"areas"`
+	Integrations []Integration `json:"integrations"`
+	Unavailable  []string      `json:"unavailable,omitempty"` // tools turned off for this entry point
+}
+
+// capabilities lists what jot can do for a run under ctx: the tools it may
+// use, grouped by area, and which integrations are on.
+func (a *Agent) capabilities(ctx context.Context) (*Capabilities, error) {
+	tools, err := a.toolsFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]llm.Tool, len(tools))
+	for _, t := range tools {
+		byName[t.Name] = t
+	}
+
+	c := &Capabilities{}
+	for _, area := range capabilityAreas {
+		var can []string
+		for _, name := range area.tools {
+			if t, ok := byName[name]; ok {
+				can = append(can, firstSentence(t.Description))
+			}
+		}
+		if len(can) > 0 {
+			c.Areas = append(c.Areas, Capability{Area: area.name, Can: can})
+		}
+	}
+	for _, t := range llm.AgentTools {
+		if !hasTool(tools, t.Name) {
+			c.Unavailable = append(c.Unavailable, t.Name)
+		}
+	}
+
+	bot := Integration{Name: "Discord bot", Enabled: a.integrations.DiscordBot,
+		Note: "Schedules, reminders, background jobs, and watches run on time and are delivered by DM."}
+	if !bot.Enabled {
+		bot.Note = "Running as a local CLI: schedules, reminders, background jobs, and watches are saved but only run when jot runs as a Discord bot."
+	}
+	webhook := Integration{Name: "Discord webhook", Enabled: a.integrations.Webhook,
+		Note: "Scheduled messages fall back to the webhook when a DM can't be sent."}
+	if !webhook.Enabled {
+		webhook.Note = "Not set up; scheduled messages go by DM only."
+	}
+	approval := Integration{Name: "Memory approval", Enabled: a.memoryApproval,
+		Note: "New memories wait for approval; review them with " + ReviewCommand + "."}
+	if !approval.Enabled {
+		approval.Note = "Memories are saved right away."
+	}
+	c.Integrations = []Integration{bot, webhook, approval}
+	return c, nil
+}
+
+// firstSentence returns s up to and including the first period that ends a
+// sentence, or all of s. The period in "e.g." doesn't count.
+func firstSentence(s string) string {
+	for i := 0; ; {
+		j := strings.Index(s[i:], ". ")
+		if j < 0 {
+			return s
+		}
+		i += j + 1
+		if !strings.HasSuffix(s[:i], "e.g.") && !strings.HasSuffix(s[:i], "i.e.") {
+			return s[:i]
+		}
+	}
+}
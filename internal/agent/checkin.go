@@ -0,0 +1,115 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chris/jot/internal/db"
+	"github.com/chris/jot/internal/llm"
+)
+
+// defaultBlockerAgeDays is how long a blocker stays unresolved before
+// check-ins escalate it, when SetBlockerAgeDays wasn't called.
+const defaultBlockerAgeDays = 7
+
+// BuildCheckInPrompt appends precomputed check-in context to a schedule's
+// prompt: memory stats, aging blockers, and the most important memories from
+// the schedule's window. It is only used for schedules with check_in set;
+// every other schedule sends its prompt as-is and the model fetches what it
+// needs.
+func (a *Agent) BuildCheckInPrompt(sched db.Schedule) (string, error) {
+	stats, err := a.db.GetMemoryStats()
+	if err != nil {
+		return "", fmt.Errorf("building check-in context: %w", err)
+	}
+	minDays := a.blockerAgeDays
+	if minDays <= 0 {
+		minDays = defaultBlockerAgeDays
+	}
+	blockers, err := a.db.ListAgingBlockers(minDays)
+	if err != nil {
+		return "", fmt.Errorf("building check-in context: %w", err)
+	}
+	memories, err := a.db.GetRecentMemoriesForCheckIn(sched.MemoryDays, sched.MemoryLimit)
+	if err != nil {
+		return "", fmt.Errorf("building check-in context: %w", err)
+	}
+
+	w := &contextWriter{budget: a.messageBudget()/checkInContextShare - llm.EstimateTokens(sched.Prompt)}
+	w.sb.WriteString(sched.Prompt)
+	w.header("\n\n[Check-in context]\n")
+	w.header(fmt.Sprintf("Memory: %s\n", stats.Line()))
+	if len(blockers) > 0 {
+		w.header(fmt.Sprintf("Blockers unresolved %d+ days (escalate each: say how long it has been blocked and suggest a next step):\n", minDays))
+		for i, b := range blockers {
+			if !w.item(fmt.Sprintf("- #%d %s (%d days)\n", b.ID, clip(b.Content), b.AgeDays)) {
+				w.omit(len(blockers)-i, "blockers", "search_memories with category blocker")
+				break
+			}
+		}
+	}
+	if len(memories) > 0 {
+		days := sched.MemoryDays
+		if days <= 0 {
+			days = db.CheckInMemoryDays
+		}
+		w.header(fmt.Sprintf("Memories from the last %d days, most important first:\n", days))
+		for i, m := range memories {
+			if !w.item(fmt.Sprintf("- #%d %s [%s] %s\n", m.ID, datePart(m.CreatedAt), m.Category, clip(m.Content))) {
+				w.omit(len(memories)-i, "lower-ranked memories", "list_recent_memories or search_memories")
+				break
+			}
+		}
+	}
+	return w.sb.String(), nil
+}
+
+// checkInContextShare is the fraction (1/n) of the message budget the
+// injected check-in context may use, leaving the rest for history and tool
+// results.
+const checkInContextShare = 4
+
+// checkInItemChars caps each blocker or memory line in check-in context.
+const checkInItemChars = 300
+
+// contextWriter builds check-in context within a token budget. Section
+// headers always fit; list items stop at the budget and are replaced by a
+// note pointing the model at the tool that fetches the rest.
+type contextWriter struct {
+	sb     strings.Builder
+	budget int
+	used   int
+}
+
+// header appends s regardless of the budget.
+func (w *contextWriter) header(s string) {
+	w.sb.WriteString(s)
+	w.used += llm.EstimateTokens(s)
+}
+
+// item appends s if it fits the budget and reports whether it did.
+func (w *contextWriter) item(s string) bool {
+	if w.used+llm.EstimateTokens(s) > w.budget {
+		return false
+	}
+	w.header(s)
+	return true
+}
+
+// omit notes n items left out for space and where to find them.
+func (w *contextWriter) omit(n int, what, tools string) {
+	fmt.Fprintf(&w.sb, "- (%d more %s omitted for space; call %s if needed)\n", n, what, tools)
+}
+
+// clip collapses whitespace and truncates s to checkInItemChars.
+func clip(s string) string {
+	return db.Truncate(strings.Join(strings.Fields(s), " "), checkInItemChars)
+}
+
+// datePart returns the YYYY-MM-DD prefix of a SQLite timestamp.
+func datePart(ts string) string {
+	if len(ts) >= 10 {
+		return ts[:10]
+	}
+	return ts
+}
@@ -0,0 +1,119 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chris/jot/internal/db"
+)
+
+// contextCardNote is the notes key holding the cached card and its date. The
+// db layer clears it when things it quotes are deleted or purged.
+const contextCardNote = db.ContextCardNote
+
+// pinnedTag marks memories that belong on the context card.
+const pinnedTag = "pinned"
+
+// cachedCard is the stored form of the day's context card.
+type cachedCard struct {
+	Date string `json:"date"`
+	Card string `json:"card"`
+}
+
+// ContextCard returns the compact daily context card for the user's local
+// date: open counts, overdue and due-today things, active things, today's
+// reminders, and pinned memories. The card is built once per day and cached
+// in the notes table, so every conversation's first turn of the day shares it.
+func (a *Agent) ContextCard() (string, error) {
+	loc := a.userLocation()
+	now := time.Now().In(loc)
+	today := now.Format(time.DateOnly)
+
+	if raw, err := a.db.GetNote(contextCardNote); err == nil && raw != "" {
+		var c cachedCard
+		if json.Unmarshal([]byte(raw), &c) == nil && c.Date == today {
+			return c.Card, nil
+		}
+	}
+
+	card, err := a.buildContextCard(now, loc)
+	if err != nil {
+		return "", err
+	}
+	raw, _ := json.Marshal(cachedCard{Date: today, Card: card})
+	if err := a.db.SetNote(contextCardNote, string(raw)); err != nil {
+		return "", fmt.Errorf("caching context card: %w", err)
+	}
+	return card, nil
+}
+
+func (a *Agent) buildContextCard(now time.Time, loc *time.Location) (string, error) {
+	today := now.Format(time.DateOnly)
+	opts := a.summaryDefaults
+	opts.Today = today
+	s, err := a.db.GetSummary(opts)
+	if err != nil {
+		return "", fmt.Errorf("building context card: %w", err)
+	}
+	active, _, err := a.db.QueryThings(db.ThingQuery{Status: "active", Sort: "priority", Limit: 5})
+	if err != nil {
+		return "", fmt.Errorf("building context card: %w", err)
+	}
+	reminders, err := a.db.ListUpcomingOneShots()
+	if err != nil {
+		return "", fmt.Errorf("building context card: %w", err)
+	}
+	pinned, err := a.db.SearchMemories("", "", pinnedTag, nil, "", 5)
+	if err != nil {
+		return "", fmt.Errorf("building context card: %w", err)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "[Context card for %s]\n", now.Format("Monday 2006-01-02"))
+	fmt.Fprintf(&sb, "Things: %d open (%d active), %d overdue, %d due today, %d due this week\n",
+		s.Open, s.Counts["active"], len(s.Overdue), len(s.DueToday), len(s.DueThisWeek))
+	writeCardThings(&sb, "Overdue", s.Overdue, true)
+	writeCardThings(&sb, "Due today", s.DueToday, false)
+	writeCardThings(&sb, "Active", active, false)
+
+	var agenda []string
+	for _, r := range reminders {
+		t, err := time.ParseInLocation(time.DateTime, r.FireAt, time.UTC)
+		if err != nil || t.In(loc).Format(time.DateOnly) != today {
+			continue
+		}
+		agenda = append(agenda, fmt.Sprintf("%s %s", t.In(loc).Format("15:04"), clip(r.Prompt)))
+	}
+	if len(agenda) > 0 {
+		fmt.Fprintf(&sb, "Reminders today: %s\n", strings.Join(agenda, "; "))
+	}
+	if len(pinned) > 0 {
+		items := make([]string, len(pinned))
+		for i, m := range pinned {
+			items[i] = fmt.Sprintf("#%d %s", m.ID, clip(m.Content))
+		}
+		fmt.Fprintf(&sb, "Pinned: %s\n", strings.Join(items, "; "))
+	}
+	return sb.String(), nil
+}
+
+// writeCardThings writes one "label: #id title (detail); ..." line, or
+// nothing when things is empty.
+func writeCardThings(sb *strings.Builder, label string, things []db.Thing, showDue bool) {
+	if len(things) == 0 {
+		return
+	}
+	items := make([]string, len(things))
+	for i, t := range things {
+		items[i] = fmt.Sprintf("#%d %s", t.ID, t.Title)
+		switch {
+		case showDue:
+			items[i] += " (due " + t.DueDate + ")"
+		case t.Priority != "normal":
+			items[i] += " (" + t.Priority + ")"
+		}
+	}
+	fmt.Fprintf(sb, "%s: %s\n", label, strings.Join(items, "; "))
+}
@@ -2,6 +2,8 @@ package agent
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"log"
 	"strings"
@@ -19,6 +21,8 @@ const (
 // RunWithConversation loads persistent conversation history, handles gap
 // detection and summarization, runs the agent, and saves the updated history.
 func (a *Agent) RunWithConversation(ctx context.Context, userID, message string) (string, error) {
+	ctx = withUser(ctx, userID)
+
 	// Load existing conversation
 	history, lastAt, err := a.db.LoadConversation(userID)
 	if err != nil {
@@ -61,15 +65,45 @@ func (a *Agent) RunWithConversation(ctx context.Context, userID, message string)
 		}
 	}
 
+	// The first turn of the user's day opens with the daily context card, so
+	// the model doesn't spend several tool calls re-establishing context.
+	loc := a.userLocation()
+	if lastAt.IsZero() || lastAt.In(loc).Format(time.DateOnly) != time.Now().In(loc).Format(time.DateOnly) {
+		if card, err := a.ContextCard(); err != nil {
+			log.Printf("building context card for %s: %v", userID, err)
+		} else {
+			contextMessages = append(contextMessages,
+				llm.Message{Role: "user", Content: card},
+				llm.Message{Role: "assistant", Content: "Got it, I have today's context."},
+			)
+		}
+	}
+
 	// Build full history: context summaries + raw messages
 	fullHistory := append(contextMessages, history...)
 
 	// Run the agent
+	ctx, turn := withTurnState(ctx)
 	reply, newHistory, err := a.Run(ctx, fullHistory, message)
 	if err != nil {
 		return "", err
 	}
 
+	// The forget tool scrubbed stored history mid-turn; don't write the
+	// in-memory copy back over it.
+	if turn.historyPurged {
+		return reply, nil
+	}
+
+	// Record the exchange in the searchable transcript. The stored user text
+	// omits the injected time prefix; created_at carries the time instead.
+	if err := a.db.AppendTranscript(userID, []llm.Message{
+		{Role: "user", Content: message},
+		{Role: "assistant", Content: reply},
+	}); err != nil {
+		log.Printf("appending transcript for %s: %v", userID, err)
+	}
+
 	// Strip the synthetic context messages before saving — we'll re-inject them next time
 	if len(contextMessages) > 0 && len(newHistory) > len(contextMessages) {
 		newHistory = newHistory[len(contextMessages):]
@@ -87,6 +121,58 @@ func (a *Agent) RunWithConversation(ctx context.Context, userID, message string)
 	return reply, nil
 }
 
+// turnState identifies one turn and carries per-turn flags from tool
+// execution back to RunWithConversation.
+type turnState struct {
+	id            string // keys write-tool deduplication; see executeToolOnce
+	historyPurged bool
+}
+
+type turnStateKey struct{}
+
+// withTurnState starts a new turn with a random ID, so the same message sent
+// again is a new turn, not a replay.
+func withTurnState(ctx context.Context) (context.Context, *turnState) {
+	return withTurnID(ctx, randomID())
+}
+
+// randomID returns 16 random hex characters.
+func randomID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// withTurnID starts a turn with a fixed ID, such as a background job's.
+func withTurnID(ctx context.Context, id string) (context.Context, *turnState) {
+	t := &turnState{id: id}
+	return context.WithValue(ctx, turnStateKey{}, t), t
+}
+
+// turnFrom returns ctx's turn, starting a new one if ctx has none.
+func turnFrom(ctx context.Context) (context.Context, *turnState) {
+	if t, ok := ctx.Value(turnStateKey{}).(*turnState); ok {
+		return ctx, t
+	}
+	return withTurnState(ctx)
+}
+
+// turnID returns the ID of ctx's turn, or "" outside one.
+func turnID(ctx context.Context) string {
+	if t, ok := ctx.Value(turnStateKey{}).(*turnState); ok {
+		return t.id
+	}
+	return ""
+}
+
+// markHistoryPurged records that the current turn's conversation history
+// must not be persisted. It is a no-op outside RunWithConversation.
+func markHistoryPurged(ctx context.Context) {
+	if t, ok := ctx.Value(turnStateKey{}).(*turnState); ok {
+		t.historyPurged = true
+	}
+}
+
 // Summarize calls the LLM with a summarization prompt and no tools to produce
 // a concise summary of the given messages.
 func (a *Agent) Summarize(ctx context.Context, messages []llm.Message) (string, error) {
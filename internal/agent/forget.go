@@ -0,0 +1,102 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/chris/jot/internal/db"
+)
+
+// purgeTokenTTL is how long a forget preview can be confirmed.
+const purgeTokenTTL = 30 * time.Minute
+
+// pendingPurge is a forget preview awaiting the user's go-ahead: exactly the
+// rows they were shown, and the turn that showed them.
+type pendingPurge struct {
+	userID     string
+	turn       string
+	candidates *db.PurgeCandidates
+	expires    time.Time
+}
+
+// pendingPurges holds forget previews by confirm token.
+type pendingPurges struct {
+	mu      sync.Mutex
+	byToken map[string]pendingPurge
+}
+
+func (p *pendingPurges) add(pp pendingPurge) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.byToken == nil {
+		p.byToken = make(map[string]pendingPurge)
+	}
+	now := time.Now()
+	for token, old := range p.byToken {
+		if now.After(old.expires) {
+			delete(p.byToken, token)
+		}
+	}
+	token := randomID()
+	p.byToken[token] = pp
+	return token
+}
+
+// take removes and returns the preview for token, if it hasn't expired.
+func (p *pendingPurges) take(token string) (pendingPurge, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pp, ok := p.byToken[token]
+	delete(p.byToken, token)
+	return pp, ok && time.Now().Before(pp.expires)
+}
+
+// forget previews or deletes everything mentioning a query. A preview returns
+// a confirm token for the listed rows; deleting needs that token, passed on a
+// later turn of the same user's conversation, so the model can't confirm on
+// the user's behalf. The purge deletes exactly the previewed rows, even if
+// more have matched since.
+func (a *Agent) forget(ctx context.Context, params map[string]any) (any, error) {
+	userID := userFrom(ctx)
+	if userID == "" {
+		return nil, fmt.Errorf("forget needs a conversation with a user")
+	}
+	token, _ := getString(params, "confirm")
+	if token == "" {
+		query, _ := getString(params, "query")
+		candidates, err := a.db.FindPurgeCandidates(query)
+		if err != nil {
+			return nil, err
+		}
+		result := map[string]any{"status": "preview", "total": candidates.Count(), "candidates": candidates}
+		if candidates.Count() > 0 {
+			result["confirm_token"] = a.purges.add(pendingPurge{
+				userID:     userID,
+				turn:       turnID(ctx),
+				candidates: candidates,
+				expires:    time.Now().Add(purgeTokenTTL),
+			})
+		}
+		return result, nil
+	}
+
+	switch profileFrom(ctx) {
+	case ProfileSchedule, ProfileJob:
+		return nil, fmt.Errorf("forget can only be confirmed by the user")
+	}
+	pp, ok := a.purges.take(token)
+	if !ok || pp.userID != userID {
+		return nil, fmt.Errorf("unknown or expired confirm token: preview again")
+	}
+	if pp.turn == turnID(ctx) {
+		return nil, fmt.Errorf("show the user the preview and confirm only after they agree in their next message: preview again")
+	}
+	purged, err := a.db.Purge(pp.candidates)
+	if err != nil {
+		return nil, err
+	}
+	markHistoryPurged(ctx)
+	return map[string]any{"status": "forgotten", "deleted": purged}, nil
+}
@@ -0,0 +1,84 @@
+package agent
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"log"
+)
+
+// idempotencyWindow is how long (in seconds) a write tool result is replayed
+// for an identical call in the same turn instead of executing again.
+const idempotencyWindow = 600
+
+// idempotentTools are the write tools whose repeated execution would create
+// duplicate rows. Updates and deletes are naturally idempotent and skip the check.
+var idempotentTools = map[string]bool{
+	"create_thing":    true,
+	"create_things":   true,
+	"save_memory":     true,
+	"save_memories":   true,
+	"create_schedule": true,
+	"create_watch":    true,
+	"start_job":       true,
+}
+
+// executeToolOnce runs a tool, deduplicating write tools by a hash of the
+// turn ID (see withTurnState), tool name, and params. A provider retry or a repeated
+// tool call within the window gets the original result back, marked duplicate.
+// Writes that ran are logged to the audit log.
+func (a *Agent) executeToolOnce(ctx context.Context, turn, name string, params map[string]any) string {
+	if !idempotentTools[name] {
+		result := a.executeTool(ctx, name, params)
+		a.audit(ctx, name, params, result)
+		return result
+	}
+
+	key := idempotencyKey(turn, name, params)
+	if prev, ok, err := a.db.GetToolResult(key, idempotencyWindow); err != nil {
+		log.Printf("idempotency lookup for %s: %v", name, err)
+	} else if ok {
+		log.Printf("tool %s: duplicate call, replaying stored result", name)
+		return markDuplicate(prev)
+	}
+
+	result := a.executeTool(ctx, name, params)
+	if isToolError(result) {
+		return result
+	}
+	a.audit(ctx, name, params, result)
+	if err := a.db.SaveToolResult(key, name, result); err != nil {
+		log.Printf("saving idempotency key for %s: %v", name, err)
+	}
+	return result
+}
+
+// idempotencyKey hashes the turn, tool name, and params. json.Marshal sorts
+// map keys, so params that differ only in order produce the same key.
+func idempotencyKey(turn, name string, params map[string]any) string {
+	p, _ := json.Marshal(params)
+	h := sha256.Sum256([]byte(turn + "\x00" + name + "\x00" + string(p)))
+	return fmt.Sprintf("%x", h)
+}
+
+// markDuplicate adds "duplicate": true to a stored JSON object result.
+func markDuplicate(result string) string {
+	var m map[string]any
+	if err := json.Unmarshal([]byte(result), &m); err != nil {
+		return result
+	}
+	m["duplicate"] = true
+	b, _ := json.Marshal(m)
+	return string(b)
+}
+
+// isToolError reports whether a tool result is an {"error": ...} object.
+func isToolError(result string) bool {
+	var m map[string]any
+	if err := json.Unmarshal([]byte(result), &m); err != nil {
+		return false
+	}
+	_, ok := m["error"]
+	return ok
+}
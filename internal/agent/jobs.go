@@ -0,0 +1,23 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chris/jot/internal/db"
+)
+
+// jobPreamble frames a background job's prompt. Nobody reads the run while it
+// happens, so the model must finish without asking questions.
+const jobPreamble = "[Background job. The user is not watching; do not ask questions. Do the work with your tools, then reply with the finished result to deliver to them.]\n\n"
+
+// RunJob runs a background job as its own agent turn, with no conversation
+// history and the job tool profile, and returns the result to deliver. The
+// turn is keyed on the job ID. Like any turn, that only dedupes identical
+// write calls made within idempotencyWindow of each other; a job requeued
+// after a crash is rerun and can repeat the writes of its first attempt.
+func (a *Agent) RunJob(ctx context.Context, job db.Job) (string, error) {
+	ctx, _ = withTurnID(ctx, fmt.Sprintf("job:%d", job.ID))
+	reply, _, err := a.Run(WithProfile(ctx, ProfileJob), nil, jobPreamble+job.Prompt)
+	return reply, err
+}
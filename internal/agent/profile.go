@@ -0,0 +1,98 @@
+package agent
+
+import (
+	"context"
+	"path"
+
+	"github.com/chris/jot/internal/llm"
+)
+
+// Tool profiles name the entry points the agent runs under. Each profile's
+// denied tools (the tool_profiles table) are hidden from the model and
+// refused if called anyway.
+const (
+	ProfileDiscord  = "discord"
+	ProfileCLI      = "cli"
+	ProfileSchedule = "schedule"
+	ProfileJob      = "job"
+	ProfileGuild    = "guild" // opt-in for Discord servers, see guild_settings
+)
+
+// Profiles lists the known tool profiles.
+var Profiles = []string{ProfileDiscord, ProfileCLI, ProfileSchedule, ProfileJob, ProfileGuild}
+
+type profileKey struct{}
+
+// WithProfile tags ctx with the entry point running the agent. Runs without
+// a profile get every tool.
+func WithProfile(ctx context.Context, profile string) context.Context {
+	return context.WithValue(ctx, profileKey{}, profile)
+}
+
+func profileFrom(ctx context.Context) string {
+	p, _ := ctx.Value(profileKey{}).(string)
+	return p
+}
+
+type allowedToolsKey struct{}
+
+// withAllowedTools limits runs under ctx to tools matching patterns, on top
+// of the profile's denials. Sub-agents use it to get a narrower tool set.
+func withAllowedTools(ctx context.Context, patterns []string) context.Context {
+	return context.WithValue(ctx, allowedToolsKey{}, patterns)
+}
+
+// toolsFor returns the tools a run under ctx may use: every agent tool, minus
+// the profile's denials, limited to the allowed patterns if any are set.
+// Sub-agent runs only ever get read-only tools.
+func (a *Agent) toolsFor(ctx context.Context) ([]llm.Tool, error) {
+	tools := llm.AgentTools
+	if profile := profileFrom(ctx); profile != "" {
+		denied, err := a.db.DeniedTools(profile)
+		if err != nil {
+			return nil, err
+		}
+		tools = filterTools(tools, denied, false)
+	}
+	if allowed, ok := ctx.Value(allowedToolsKey{}).([]string); ok {
+		tools = filterTools(tools, allowed, true)
+	}
+	if isSubtask(ctx) {
+		tools = filterTools(tools, subtaskTools, true)
+	}
+	return tools, nil
+}
+
+// matchTool reports whether name matches any of the patterns.
+func matchTool(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// filterTools returns the tools that match patterns (keep) or that don't.
+func filterTools(tools []llm.Tool, patterns []string, keep bool) []llm.Tool {
+	if len(patterns) == 0 && !keep {
+		return tools
+	}
+	out := make([]llm.Tool, 0, len(tools))
+	for _, t := range tools {
+		if matchTool(patterns, t.Name) == keep {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// hasTool reports whether tools includes one named name.
+func hasTool(tools []llm.Tool, name string) bool {
+	for _, t := range tools {
+		if t.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package agent
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ReviewCommand is the chat command for reviewing proposed memories.
+const ReviewCommand = "!memories"
+
+// ParseReviewCommand reports whether input is a !memories command and
+// returns the text after the command name.
+func ParseReviewCommand(input string) (string, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(input), ReviewCommand)
+	if !ok || (rest != "" && rest[0] != ' ') {
+		return "", false
+	}
+	return strings.TrimSpace(rest), true
+}
+
+// ReviewMemories handles the !memories command. With no arguments it lists
+// proposed memories; "approve" or "reject" followed by IDs (or "all") applies
+// the decision in one batch. It never goes through the LLM, so the agent can't
+// approve its own memories.
+func (a *Agent) ReviewMemories(args string) (string, error) {
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		return a.listProposed()
+	}
+
+	action := strings.ToLower(fields[0])
+	if action != "approve" && action != "reject" {
+		return reviewUsage, nil
+	}
+	ids, err := parseReviewIDs(fields[1:])
+	if err != nil {
+		return "", err
+	}
+
+	if action == "approve" {
+		n, err := a.db.ApproveMemories(ids)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Approved %d memory(ies).", n), nil
+	}
+	n, err := a.db.RejectMemories(ids)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Rejected %d memory(ies).", n), nil
+}
+
+const reviewUsage = "Usage: `!memories` to list, `!memories approve <ids|all>`, `!memories reject <ids|all>`"
+
+func (a *Agent) listProposed() (string, error) {
+	proposed, err := a.db.ListProposedMemories()
+	if err != nil {
+		return "", err
+	}
+	if len(proposed) == 0 {
+		return "No memories awaiting review.", nil
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d proposed memory(ies):\n", len(proposed))
+	for _, m := range proposed {
+		fmt.Fprintf(&sb, "#%d [%s] %s\n", m.ID, m.Category, m.Content)
+	}
+	sb.WriteString("\n" + reviewUsage)
+	return sb.String(), nil
+}
+
+// parseReviewIDs parses memory IDs from command arguments. "all" returns nil,
+// which the DB layer treats as every proposed memory.
+func parseReviewIDs(args []string) ([]int64, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("specify memory IDs or \"all\"")
+	}
+	if len(args) == 1 && strings.EqualFold(args[0], "all") {
+		return nil, nil
+	}
+	var ids []int64
+	for _, arg := range args {
+		for _, part := range strings.Split(arg, ",") {
+			part = strings.TrimPrefix(strings.TrimSpace(part), "#")
+			if part == "" {
+				continue
+			}
+			id, err := strconv.ParseInt(part, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid memory ID %q", part)
+			}
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("specify memory IDs or \"all\"")
+	}
+	return ids, nil
+}
@@ -0,0 +1,168 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/chris/jot/internal/db"
+	"github.com/chris/jot/internal/llm"
+)
+
+// styleNotePrefix keys each user's style in the notes table.
+const styleNotePrefix = "style:"
+
+// maxStyleNotes caps the free-form part of a style, which rides along in
+// every system prompt.
+const maxStyleNotes = 300
+
+// Style is how a user wants jot to talk to them. It is set with set_style and
+// appended to the system prompt for that user's conversations, so it outlasts
+// the current conversation. Empty fields leave the default voice alone.
+type Style struct {
+	Tone     string `json:"tone,omitempty"`     // terse, balanced, chatty
+	Emoji    *bool  `json:"emoji,omitempty"`    // nil = default (sparing)
+	Approach string `json:"approach,omitempty"` // coaching, neutral
+	Notes    string `json:"notes,omitempty"`    // e.g. "be more blunt with me"
+	Plain    bool   `json:"plain,omitempty"`    // deliver plain text for screen readers
+}
+
+var (
+	styleTones      = []string{"terse", "balanced", "chatty"}
+	styleApproaches = []string{"coaching", "neutral"}
+)
+
+// prompt renders s as a system prompt section, or "" if s is empty.
+func (s Style) prompt() string {
+	var lines []string
+	switch s.Tone {
+	case "terse":
+		lines = append(lines, "Be terse: short sentences, no preamble, lists over paragraphs.")
+	case "chatty":
+		lines = append(lines, "Be warm and conversational; a little more detail is welcome.")
+	}
+	if s.Emoji != nil {
+		if *s.Emoji {
+			lines = append(lines, "Emoji are welcome.")
+		} else {
+			lines = append(lines, "Never use emoji.")
+		}
+	}
+	switch s.Approach {
+	case "coaching":
+		lines = append(lines, "Coach: ask what's in the way, suggest a next step, and call out avoidance kindly.")
+	case "neutral":
+		lines = append(lines, "Stay neutral: report and confirm without nudging or advice unless asked.")
+	}
+	if s.Plain {
+		lines = append(lines, "The user reads with a screen reader: write plain sentences, no tables or emoji.")
+	}
+	if s.Notes != "" {
+		lines = append(lines, "The user asked: "+s.Notes)
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return "\n\n## This user's style (overrides How to behave where they conflict)\n\n- " + strings.Join(lines, "\n- ")
+}
+
+type userKey struct{}
+
+// withUser tags ctx with the user whose conversation is running, so their
+// style applies to every round of the turn.
+func withUser(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userKey{}, userID)
+}
+
+func userFrom(ctx context.Context) string {
+	u, _ := ctx.Value(userKey{}).(string)
+	return u
+}
+
+// loadStyle returns a user's saved style, or the zero Style.
+func (a *Agent) loadStyle(userID string) (Style, error) {
+	var s Style
+	raw, err := a.db.GetNote(styleNotePrefix + userID)
+	if err != nil || raw == "" {
+		return s, err
+	}
+	if err := json.Unmarshal([]byte(raw), &s); err != nil {
+		return s, fmt.Errorf("decoding style for %s: %w", userID, err)
+	}
+	return s, nil
+}
+
+// systemPrompt returns the system prompt for a run: the static prompt plus
+// the user's style, if any. The style changes rarely, so the prompt still
+// caches well.
+func (a *Agent) systemPrompt(ctx context.Context) string {
+	userID := userFrom(ctx)
+	if userID == "" {
+		return llm.SystemPrompt
+	}
+	s, err := a.loadStyle(userID)
+	if err != nil {
+		log.Printf("loading style: %v", err)
+		return llm.SystemPrompt
+	}
+	return llm.SystemPrompt + s.prompt()
+}
+
+// PlainText reports whether userID asked for plain-text delivery. Callers
+// deliver replies and check-ins for that user through plaintext.Strip.
+func (a *Agent) PlainText(userID string) bool {
+	s, err := a.loadStyle(userID)
+	if err != nil {
+		log.Printf("loading style: %v", err)
+	}
+	return s.Plain
+}
+
+// setStyle updates the current user's style with the given fields; reset
+// clears it first.
+func (a *Agent) setStyle(ctx context.Context, params map[string]any) (any, error) {
+	userID := userFrom(ctx)
+	if userID == "" {
+		return nil, fmt.Errorf("set_style needs a conversation with a user")
+	}
+	var s Style
+	if reset, _ := params["reset"].(bool); !reset {
+		var err error
+		if s, err = a.loadStyle(userID); err != nil {
+			return nil, err
+		}
+	}
+	if tone, ok := getString(params, "tone"); ok {
+		if tone != "" && !slices.Contains(styleTones, tone) {
+			return nil, fmt.Errorf("unknown tone %q (use %s)", tone, strings.Join(styleTones, ", "))
+		}
+		s.Tone = tone
+	}
+	if approach, ok := getString(params, "approach"); ok {
+		if approach != "" && !slices.Contains(styleApproaches, approach) {
+			return nil, fmt.Errorf("unknown approach %q (use %s)", approach, strings.Join(styleApproaches, ", "))
+		}
+		s.Approach = approach
+	}
+	if emoji, ok := params["emoji"].(bool); ok {
+		s.Emoji = &emoji
+	}
+	if plain, ok := params["plain"].(bool); ok {
+		s.Plain = plain
+	}
+	if notes, ok := getString(params, "notes"); ok {
+		s.Notes = db.Truncate(strings.TrimSpace(notes), maxStyleNotes)
+	}
+
+	raw, err := json.Marshal(s)
+	if err != nil {
+		return nil, fmt.Errorf("encoding style: %w", err)
+	}
+	if err := a.db.SetNote(styleNotePrefix+userID, string(raw)); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
@@ -0,0 +1,44 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+)
+
+// subtaskPreamble frames a delegated subtask. The sub-agent's tool results
+// never reach the main conversation, so its reply must carry everything.
+const subtaskPreamble = "[Subtask delegated by the main agent. Use your tools to complete it, then reply with only a concise summary of what you found (under 200 words). The summary is all the main agent will see.]\n\n"
+
+// subtaskTools is the read-only tool set a sub-agent gets. The caller's tool
+// globs can narrow it but never widen it, so the model can't hand a
+// sub-agent write tools.
+var subtaskTools = []string{"list_*", "search_*", "get_*", "run_filter", "completed_things"}
+
+type subtaskKey struct{}
+
+func isSubtask(ctx context.Context) bool {
+	return ctx.Value(subtaskKey{}) != nil
+}
+
+// runSubtask delegates task to a sub-agent: a fresh Run with no history,
+// its own context window, and only the read-only tools matching tools (all
+// of them by default). It inherits the caller's tool profile, so a sub-agent
+// can never use a tool its parent couldn't, and it cannot spawn further
+// subtasks.
+func (a *Agent) runSubtask(ctx context.Context, task string, tools []string) (string, error) {
+	if isSubtask(ctx) {
+		return "", fmt.Errorf("subtasks cannot spawn subtasks")
+	}
+	if task == "" {
+		return "", fmt.Errorf("task is required")
+	}
+	if len(tools) == 0 {
+		tools = subtaskTools
+	}
+	sub := context.WithValue(withAllowedTools(ctx, tools), subtaskKey{}, true)
+	reply, _, err := a.Run(sub, nil, subtaskPreamble+task)
+	if err != nil {
+		return "", fmt.Errorf("running subtask: %w", err)
+	}
+	return reply, nil
+}
@@ -0,0 +1,172 @@
+package agent
+
+import (
+	"slices"
+	"strings"
+)
+
+// defaultSynonyms maps common habit and tag words in other languages to a
+// canonical English name, by locale. All locales share one lookup, so a
+// variant that is also an English word ("training", "lecture") would rewrite
+// English tags and is left out. Config (synonyms in config.yaml) adds to
+// these and wins on conflicts.
+var defaultSynonyms = map[string]map[string][]string{
+	"es": {
+		"gym":      {"gimnasio", "gim"},
+		"run":      {"correr"},
+		"walk":     {"caminar", "paseo"},
+		"read":     {"leer", "lectura"},
+		"meditate": {"meditar", "meditación"},
+		"swim":     {"nadar", "natación"},
+		"work":     {"trabajo"},
+		"health":   {"salud"},
+		"home":     {"casa", "hogar"},
+		"family":   {"familia"},
+	},
+	"de": {
+		"gym":      {"fitnessstudio"},
+		"run":      {"laufen", "joggen"},
+		"walk":     {"spazieren", "spaziergang"},
+		"read":     {"lesen"},
+		"meditate": {"meditieren"},
+		"swim":     {"schwimmen"},
+		"work":     {"arbeit"},
+		"health":   {"gesundheit"},
+		"home":     {"zuhause", "haushalt"},
+		"family":   {"familie"},
+	},
+	"fr": {
+		"gym":      {"salle de sport", "muscu"},
+		"run":      {"courir", "course à pied"},
+		"walk":     {"marcher"},
+		"read":     {"lire"},
+		"meditate": {"méditer", "méditation"},
+		"swim":     {"nager", "natation"},
+		"work":     {"travail", "boulot"},
+		"health":   {"santé"},
+		"home":     {"maison"},
+		"family":   {"famille"},
+	},
+}
+
+// Synonyms maps a normalized word or phrase to its canonical name.
+type Synonyms map[string]string
+
+// NewSynonyms builds the lookup from the built-in locales plus extra, which
+// has the same shape (locale -> canonical -> variants) and is applied last.
+func NewSynonyms(extra map[string]map[string][]string) Synonyms {
+	s := Synonyms{}
+	for _, groups := range []map[string]map[string][]string{defaultSynonyms, extra} {
+		for _, byCanonical := range groups {
+			for canonical, variants := range byCanonical {
+				canonical = normalizePhrase(canonical)
+				for _, v := range variants {
+					if v = normalizePhrase(v); v != "" && v != canonical {
+						s[v] = canonical
+					}
+				}
+			}
+		}
+	}
+	return s
+}
+
+// Canonical returns the canonical name for tag, or tag unchanged if it has
+// no synonym.
+func (s Synonyms) Canonical(tag string) string {
+	if c, ok := s[normalizePhrase(tag)]; ok {
+		return c
+	}
+	return tag
+}
+
+// canonicalTags maps each tag to its canonical name, dropping duplicates.
+func (s Synonyms) canonicalTags(tags []string) []string {
+	out := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		if c := s.Canonical(t); !seen[c] {
+			seen[c] = true
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// habitNames returns the canonical names of synonyms mentioned in text, so
+// "fui al gimnasio" and "gym session" both log to the gym habit. As in
+// matchTags, a name also matches its plural with an "s".
+func (s Synonyms) habitNames(text string) []string {
+	padded := " " + normalizePhrase(text) + " "
+	var names []string
+	seen := map[string]bool{}
+	for variant, canonical := range s {
+		for _, w := range []string{variant, canonical} {
+			if !seen[canonical] && (strings.Contains(padded, " "+w+" ") || strings.Contains(padded, " "+w+"s ")) {
+				seen[canonical] = true
+				names = append(names, canonical)
+			}
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// normalizePhrase lowercases s and joins its words (tagWords rules) with
+// single spaces.
+func normalizePhrase(s string) string {
+	return strings.Join(tagWords(s), " ")
+}
+
+// taggingTools are the tools whose tags param tags a thing or memory.
+var taggingTools = map[string]bool{
+	"create_thing":  true,
+	"create_things": true,
+	"update_thing":  true,
+	"save_memory":   true,
+	"save_memories": true,
+	"update_memory": true,
+}
+
+// canonicalizeParams rewrites the tags a tagging tool writes (tags, and tags
+// inside things or memories arrays) to canonical names, and tags habit
+// memories with the canonical habits they mention. Every other tool is left
+// alone, since its tags match existing rows (list and search filters, saved
+// filters, areas) and rows tagged before synonyms applied keep their original
+// tags.
+func (a *Agent) canonicalizeParams(name string, params map[string]any) {
+	if len(a.synonyms) == 0 || !taggingTools[name] {
+		return
+	}
+	a.canonicalizeItem(name == "save_memory", params)
+	for _, key := range []string{"things", "memories"} {
+		items, _ := params[key].([]any)
+		for _, item := range items {
+			if m, ok := item.(map[string]any); ok {
+				a.canonicalizeItem(key == "memories", m)
+			}
+		}
+	}
+}
+
+// canonicalizeItem canonicalizes m's tags; for a memory in the habit
+// category it also adds the habits its content mentions.
+func (a *Agent) canonicalizeItem(memory bool, m map[string]any) {
+	if _, ok := m["tags"].([]any); ok {
+		m["tags"] = toAny(a.synonyms.canonicalTags(getStrings(m, "tags")))
+	}
+	if category, _ := getString(m, "category"); memory && category == "habit" {
+		content, _ := getString(m, "content")
+		if habits := a.synonyms.habitNames(content); len(habits) > 0 {
+			m["tags"] = toAny(a.synonyms.canonicalTags(append(getStrings(m, "tags"), habits...)))
+		}
+	}
+}
+
+func toAny(ss []string) []any {
+	out := make([]any, len(ss))
+	for i, s := range ss {
+		out[i] = s
+	}
+	return out
+}
@@ -0,0 +1,139 @@
+// Package bench benchmarks jot's hot paths: scanning things and memories,
+// FTS search, and trimming and estimating long conversation histories. The
+// same cases run under go test -bench and jot bench, which times them with
+// Measure so the binary doesn't link the testing package.
+package bench
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/chris/jot/internal/db"
+	"github.com/chris/jot/internal/llm"
+)
+
+// Default sizes, chosen to be well past what a heavy user accumulates.
+const (
+	DefaultRows     = 50000
+	DefaultMessages = 1000
+)
+
+// Case is one named benchmark. Run performs a single operation.
+type Case struct {
+	Name string
+	Run  func() error
+}
+
+// Seed fills d with rows things and rows memories.
+func Seed(d *db.DB, rows int) error {
+	return d.SeedBenchData(rows, rows)
+}
+
+// Cases returns the benchmarks against d, which Seed filled with rows rows,
+// and a synthetic history of messages messages.
+func Cases(d *db.DB, rows, messages int) []Case {
+	history := History(messages)
+	budget := llm.EstimateMessagesTokens(history) / 2
+	long := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 1000)
+
+	return []Case{
+		{fmt.Sprintf("ListThings/%d", rows), func() error {
+			things, err := d.ListThings("", "", "")
+			if err == nil && len(things) != rows {
+				err = fmt.Errorf("got %d things, want %d", len(things), rows)
+			}
+			return err
+		}},
+		{fmt.Sprintf("ListRecentMemories/%d", rows), func() error {
+			_, err := d.ListRecentMemories("", rows)
+			return err
+		}},
+		{"SearchMemories/fts", func() error {
+			_, err := d.SearchMemories("invoice garden", "", "", nil, "", 20)
+			return err
+		}},
+		{"GetSummary/cached", func() error {
+			_, err := d.GetSummary(db.SummaryOptions{})
+			return err
+		}},
+		{fmt.Sprintf("TrimMessages/%d", messages), func() error {
+			llm.TrimMessages(history, budget)
+			return nil
+		}},
+		{fmt.Sprintf("EstimateMessagesTokens/%d", messages), func() error {
+			llm.EstimateMessagesTokens(history)
+			return nil
+		}},
+		{"EstimateTokens/45KB", func() error {
+			llm.EstimateTokens(long)
+			return nil
+		}},
+	}
+}
+
+// Result is how a case performed in Measure.
+type Result struct {
+	N           int
+	PerOp       time.Duration
+	BytesPerOp  uint64
+	AllocsPerOp uint64
+}
+
+// benchTime is roughly how long Measure runs each case.
+const benchTime = time.Second
+
+// Measure runs c in growing batches, as go test -bench does, until a batch
+// takes benchTime, and reports that batch's cost per operation.
+func Measure(c Case) (Result, error) {
+	n := 1
+	for {
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		start := time.Now()
+		for range n {
+			if err := c.Run(); err != nil {
+				return Result{}, fmt.Errorf("%s: %w", c.Name, err)
+			}
+		}
+		elapsed := time.Since(start)
+		runtime.ReadMemStats(&after)
+		if elapsed >= benchTime {
+			ops := uint64(n)
+			return Result{
+				N:           n,
+				PerOp:       elapsed / time.Duration(n),
+				BytesPerOp:  (after.TotalAlloc - before.TotalAlloc) / ops,
+				AllocsPerOp: (after.Mallocs - before.Mallocs) / ops,
+			}, nil
+		}
+		// Aim 20% past benchTime from this batch's rate, growing at most 100x.
+		next := n * 100
+		if elapsed > 0 {
+			next = min(next, int(int64(n)*int64(benchTime)*6/5/int64(elapsed)))
+		}
+		n = max(next, n+1)
+	}
+}
+
+// History returns a conversation of n messages shaped like real ones: user
+// turns, plain replies, and tool calls with their results.
+func History(n int) []llm.Message {
+	msgs := make([]llm.Message, 0, n)
+	for i := 0; len(msgs) < n; i++ {
+		msgs = append(msgs, llm.Message{Role: "user", Content: fmt.Sprintf("What's left on the %d report? Anything overdue?", i)})
+		if i%2 == 0 {
+			id := fmt.Sprintf("call_%d", i)
+			msgs = append(msgs,
+				llm.Message{Role: "assistant", ToolCalls: []llm.ToolCall{{ID: id, Name: "list_things", Params: map[string]any{
+					"status": "open", "tag": "work", "limit": float64(20), "tags": []any{"report", "q3"},
+				}}}},
+				llm.Message{Role: "user", ToolCallID: id, Content: strings.Repeat(`{"id":12,"title":"Draft the report","status":"open"},`, 10)},
+			)
+		}
+		msgs = append(msgs, llm.Message{Role: "assistant", Content: "Two things are open and one is overdue: the draft, due yesterday."})
+	}
+	return msgs[:n]
+}
@@ -0,0 +1,59 @@
+package bench
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/chris/jot/internal/db"
+)
+
+// BenchmarkJot runs every case against a freshly seeded in-memory database:
+//
+//	go test -bench . -benchmem ./internal/bench
+func BenchmarkJot(b *testing.B) {
+	d, err := db.Open(":memory:")
+	if err != nil {
+		b.Fatalf("opening db: %v", err)
+	}
+	defer d.Close()
+	if err := Seed(d, DefaultRows); err != nil {
+		b.Fatal(err)
+	}
+	for _, c := range Cases(d, DefaultRows, DefaultMessages) {
+		b.Run(c.Name, func(b *testing.B) {
+			for b.Loop() {
+				if err := c.Run(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestMeasure(t *testing.T) {
+	calls := 0
+	res, err := Measure(Case{Name: "count", Run: func() error {
+		calls++
+		return nil
+	}})
+	if err != nil {
+		t.Fatalf("Measure: %v", err)
+	}
+	if res.N < 1000 || calls < res.N || res.PerOp <= 0 {
+		t.Errorf("unexpected result %+v after %d calls", res, calls)
+	}
+
+	_, err = Measure(Case{Name: "broken", Run: func() error { return errors.New("no db") }})
+	if err == nil || !strings.Contains(err.Error(), "broken: no db") {
+		t.Errorf("expected the case's error, got %v", err)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	for _, n := range []int{0, 1, 5, 1000} {
+		if got := len(History(n)); got != n {
+			t.Errorf("History(%d) has %d messages", n, got)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+// benchWords are mixed into synthetic rows so FTS searches have a realistic
+// spread of hits.
+var benchWords = []string{
+	"report", "invoice", "gym", "dentist", "garden", "budget", "meeting", "draft",
+	"groceries", "taxes", "review", "deploy", "birthday", "flight", "library", "bike",
+}
+
+// SeedBenchData inserts things and memories synthetic rows in one transaction,
+// bypassing duplicate detection, for benchmarks and jot bench. Every third
+// row has tags and every fifth thing is done.
+func (d *DB) SeedBenchData(things, memories int) error {
+	tx, err := d.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("starting seed: %w", err)
+	}
+	defer tx.Rollback()
+
+	thingStmt, err := tx.Prepare("INSERT INTO things (title, notes, status, priority, tags, due_date) VALUES (?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		return fmt.Errorf("preparing thing insert: %w", err)
+	}
+	defer thingStmt.Close()
+	today := time.Now().UTC()
+	for i := range things {
+		w := benchWords[i%len(benchWords)]
+		status := "open"
+		if i%5 == 0 {
+			status = "done"
+		}
+		if _, err := thingStmt.Exec(
+			fmt.Sprintf("Thing %d about the %s", i, w),
+			nullStr(fmt.Sprintf("Notes on %s number %d", w, i)),
+			status, []string{"low", "normal", "high", "urgent"}[i%4],
+			nullStr(benchTags(i)),
+			nullStr(today.AddDate(0, 0, i%60-30).Format(time.DateOnly)),
+		); err != nil {
+			return fmt.Errorf("seeding thing: %w", err)
+		}
+	}
+
+	memStmt, err := tx.Prepare("INSERT INTO memories (content, category, source, status, tags) VALUES (?, ?, 'bench', 'active', ?)")
+	if err != nil {
+		return fmt.Errorf("preparing memory insert: %w", err)
+	}
+	defer memStmt.Close()
+	categories := []string{"observation", "decision", "blocker", "preference", "event", "reflection", "habit"}
+	for i := range memories {
+		w, w2 := benchWords[i%len(benchWords)], benchWords[(i/len(benchWords))%len(benchWords)]
+		if _, err := memStmt.Exec(
+			fmt.Sprintf("Memory %d: the %s came up again while sorting out the %s", i, w, w2),
+			categories[i%len(categories)],
+			nullStr(benchTags(i)),
+		); err != nil {
+			return fmt.Errorf("seeding memory: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing seed: %w", err)
+	}
+	d.thingsChanged()
+	return nil
+}
+
+func benchTags(i int) string {
+	if i%3 != 0 {
+		return ""
+	}
+	return fmt.Sprintf(`["%s","bench"]`, benchWords[i%len(benchWords)])
+}
@@ -0,0 +1,21 @@
+// Code generated by go run ./gen; DO NOT EDIT.
+// Source: schema.sql column annotations.
+
+package db
+
+// allowedColumns lists, per table, the columns updateRow may set (@update).
+var allowedColumns = map[string]map[string]bool{
+	"memories":  {"content": true, "category": true, "tags": true, "expires_at": true},
+	"schedules": {"cron_expr": true, "prompt": true, "enabled": true, "check_in": true, "digest": true, "memory_days": true, "memory_limit": true},
+	"things":    {"title": true, "notes": true, "status": true, "priority": true, "tags": true, "due_date": true, "completed_at": true},
+	"watches":   {"prompt": true, "urls": true, "cron_expr": true, "enabled": true},
+}
+
+// ToolFields lists, per table, the scalar columns the update_* tools copy
+// straight from tool params (@tool).
+var ToolFields = map[string][]string{
+	"memories":  {"content", "category", "expires_at"},
+	"schedules": {"cron_expr", "prompt", "memory_days", "memory_limit"},
+	"things":    {"title", "notes", "status", "priority", "due_date"},
+	"watches":   {"prompt", "cron_expr"},
+}
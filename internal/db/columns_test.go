@@ -0,0 +1,85 @@
+package db
+
+import (
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/chris/jot/internal/db/schemaspec"
+)
+
+// TestColumnsGenUpToDate fails when schema.sql annotations changed without
+// rerunning go generate ./internal/db.
+func TestColumnsGenUpToDate(t *testing.T) {
+	want, err := schemaspec.Generate("db", schema)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	got, err := os.ReadFile("columns_gen.go")
+	if err != nil {
+		t.Fatalf("reading columns_gen.go: %v", err)
+	}
+	if string(got) != string(want) {
+		t.Error("columns_gen.go is stale; run go generate ./internal/db")
+	}
+}
+
+// TestAnnotatedColumnsExist checks every whitelisted column exists in a
+// freshly opened database, so a typo in an annotation can't slip through.
+func TestAnnotatedColumnsExist(t *testing.T) {
+	d := openTestDB(t)
+	for table, cols := range allowedColumns {
+		for col := range cols {
+			if !d.columnExists(table, col) {
+				t.Errorf("%s.%s is annotated but missing from the database", table, col)
+			}
+		}
+	}
+	for table, cols := range ToolFields {
+		for _, col := range cols {
+			if !allowedColumns[table][col] {
+				t.Errorf("tool field %s.%s is not updatable", table, col)
+			}
+		}
+	}
+}
+
+// TestDataModel checks the described model matches a freshly opened
+// database and carries the enums and relationships the agent relies on.
+func TestDataModel(t *testing.T) {
+	d := openTestDB(t)
+	m, err := Model()
+	if err != nil {
+		t.Fatalf("Model: %v", err)
+	}
+	names := map[string]bool{}
+	for _, tbl := range m.Tables {
+		names[tbl.Name] = true
+		for _, f := range tbl.Fields {
+			if !d.columnExists(tbl.Name, f.Name) {
+				t.Errorf("%s.%s is described but missing from the database", tbl.Name, f.Name)
+			}
+		}
+	}
+	for _, want := range []string{"things", "memories", "schedules", "watches"} {
+		if !names[want] {
+			t.Errorf("model missing table %s", want)
+		}
+	}
+	if names["conversations"] || names["tool_idempotency"] {
+		t.Error("model includes internal tables")
+	}
+	if !slices.Contains(m.Relationships, "memories.thing_id = things.id") {
+		t.Errorf("relationships = %v", m.Relationships)
+	}
+
+	if err := CheckValue("things", "status", "done"); err != nil {
+		t.Errorf("CheckValue(done): %v", err)
+	}
+	if err := CheckValue("things", "status", "finished"); err == nil {
+		t.Error("CheckValue(finished): expected error")
+	}
+	if err := CheckValue("things", "title", "anything"); err != nil {
+		t.Errorf("CheckValue(title): %v", err)
+	}
+}
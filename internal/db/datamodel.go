@@ -0,0 +1,58 @@
+package db
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/chris/jot/internal/db/schemaspec"
+)
+
+// DataModel describes the user-facing tables, read from the @model and @enum
+// annotations in schema.sql, so it can't drift from the schema.
+type DataModel struct {
+	Tables        []schemaspec.Table `json:"tables"`
+	Relationships []string           `json:"relationships"` // "memories.thing_id = things.id"
+}
+
+var dataModel = sync.OnceValues(func() (*DataModel, error) {
+	tables, err := schemaspec.Describe(schema)
+	if err != nil {
+		return nil, fmt.Errorf("describing schema: %w", err)
+	}
+	m := &DataModel{Tables: tables}
+	for _, t := range tables {
+		for _, f := range t.Fields {
+			if f.References != "" {
+				m.Relationships = append(m.Relationships, t.Name+"."+f.Name+" = "+f.References)
+			}
+		}
+	}
+	return m, nil
+})
+
+// Model returns the data model. It's parsed once and shared; don't modify it.
+func Model() (*DataModel, error) {
+	return dataModel()
+}
+
+// CheckValue returns an error if table.column takes a fixed set of values
+// (@enum) and v isn't one of them. Other columns accept anything.
+func CheckValue(table, column, v string) error {
+	m, err := Model()
+	if err != nil {
+		return err
+	}
+	for _, t := range m.Tables {
+		if t.Name != table {
+			continue
+		}
+		for _, f := range t.Fields {
+			if f.Name == column && len(f.Values) > 0 && !slices.Contains(f.Values, v) {
+				return fmt.Errorf("invalid %s %q: must be one of %s", column, v, strings.Join(f.Values, ", "))
+			}
+		}
+	}
+	return nil
+}
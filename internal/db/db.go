@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	_ "embed"
 	"fmt"
+	"strings"
 
 	_ "modernc.org/sqlite"
 )
@@ -12,20 +13,30 @@ import (
 var schema string
 
 type DB struct {
-	conn *sql.DB
+	conn      *sql.DB
+	summaries summaryCache
 }
 
+// Open opens (creating if needed) the database at path and applies the
+// schema. ":memory:" opens a private in-memory database.
 func Open(path string) (*DB, error) {
-	conn, err := sql.Open("sqlite", path)
+	conn, err := sql.Open("sqlite", dsn(path))
 	if err != nil {
 		return nil, fmt.Errorf("opening database: %w", err)
 	}
+	if path == ":memory:" {
+		// Every connection to :memory: is a separate, empty database.
+		conn.SetMaxOpenConns(1)
+	}
 	if _, err := conn.Exec("PRAGMA journal_mode=WAL"); err != nil {
 		return nil, fmt.Errorf("setting WAL mode: %w", err)
 	}
 	if _, err := conn.Exec("PRAGMA foreign_keys=ON"); err != nil {
 		return nil, fmt.Errorf("enabling foreign keys: %w", err)
 	}
+	d := &DB{conn: conn}
+	newAreas := !d.tableExists("areas")
+	newToolProfiles := !d.tableExists("tool_profiles")
 	if _, err := conn.Exec(schema); err != nil {
 		return nil, fmt.Errorf("running migrations: %w", err)
 	}
@@ -33,13 +44,48 @@ func Open(path string) (*DB, error) {
 	if _, err := conn.Exec(`INSERT OR IGNORE INTO memories_fts(rowid, content) SELECT id, content FROM memories`); err != nil {
 		return nil, fmt.Errorf("backfilling FTS: %w", err)
 	}
-	d := &DB{conn: conn}
 	if err := d.migrate(); err != nil {
 		return nil, fmt.Errorf("running data migrations: %w", err)
 	}
+	if newAreas {
+		if err := d.seedAreas(); err != nil {
+			return nil, err
+		}
+	}
+	if newToolProfiles {
+		if err := d.seedToolProfiles(); err != nil {
+			return nil, err
+		}
+	}
 	return d, nil
 }
 
+// OpenReadOnly opens the existing database at path for reading only. It never
+// creates the file and skips the schema and migrations, so it is cheap enough
+// to run on every shell completion.
+func OpenReadOnly(path string) (*DB, error) {
+	conn, err := sql.Open("sqlite", "file:"+path+"?mode=ro&_pragma=busy_timeout(1000)")
+	if err != nil {
+		return nil, fmt.Errorf("opening database: %w", err)
+	}
+	return &DB{conn: conn}, nil
+}
+
+// dsn adds per-connection settings to a file path. PRAGMAs run with Exec only
+// reach one pooled connection, so foreign keys and the busy timeout go in the
+// DSN; _txlock=immediate takes the write lock at BEGIN so concurrent
+// transactions wait on busy_timeout instead of failing to upgrade.
+func dsn(path string) string {
+	if path == ":memory:" {
+		return path
+	}
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	return path + sep + "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_txlock=immediate"
+}
+
 func (d *DB) Close() error {
 	return d.conn.Close()
 }
@@ -71,6 +117,48 @@ func (d *DB) migrate() error {
 		}
 	}
 
+	// Add status to memories if missing (added with memory approval mode).
+	if !d.columnExists("memories", "status") {
+		if _, err := d.conn.Exec(`ALTER TABLE memories ADD COLUMN status TEXT NOT NULL DEFAULT 'active'`); err != nil {
+			return fmt.Errorf("adding status to memories: %w", err)
+		}
+	}
+
+	// Add check_in to schedules if missing (added with check-in context).
+	// The default check-in schedule is the one that always wanted it.
+	if !d.columnExists("schedules", "check_in") {
+		if _, err := d.conn.Exec(`ALTER TABLE schedules ADD COLUMN check_in INTEGER DEFAULT 0`); err != nil {
+			return fmt.Errorf("adding check_in to schedules: %w", err)
+		}
+		if _, err := d.conn.Exec(`UPDATE schedules SET check_in = 1 WHERE name = 'morning-checkin'`); err != nil {
+			return fmt.Errorf("enabling check-in context: %w", err)
+		}
+	}
+
+	// Add the check-in memory window and changes digest to schedules if missing.
+	for _, col := range []string{"memory_days", "memory_limit", "digest"} {
+		if !d.columnExists("schedules", col) {
+			if _, err := d.conn.Exec(`ALTER TABLE schedules ADD COLUMN ` + col + ` INTEGER DEFAULT 0`); err != nil {
+				return fmt.Errorf("adding %s to schedules: %w", col, err)
+			}
+		}
+	}
+
+	// Add privacy controls to guild_settings if missing.
+	for _, col := range []string{"reply_dm", "delete_after"} {
+		if d.tableExists("guild_settings") && !d.columnExists("guild_settings", col) {
+			if _, err := d.conn.Exec(`ALTER TABLE guild_settings ADD COLUMN ` + col + ` INTEGER NOT NULL DEFAULT 0`); err != nil {
+				return fmt.Errorf("adding %s to guild_settings: %w", col, err)
+			}
+		}
+	}
+
+	// Lowercase area tags saved before SetArea normalized them; a mixed-case
+	// duplicate of a lowercase tag is replaced by it.
+	if _, err := d.conn.Exec(`UPDATE OR REPLACE area_tags SET tag = lower(trim(tag)) WHERE tag != lower(trim(tag))`); err != nil {
+		return fmt.Errorf("lowercasing area tags: %w", err)
+	}
+
 	// Drop removed tables.
 	for _, table := range []string{"check_ins", "skills", "reminders", "habit_logs"} {
 		if _, err := d.conn.Exec("DROP TABLE IF EXISTS " + table); err != nil {
@@ -0,0 +1,53 @@
+// Package dbtest provides a test harness backed by a real on-disk database,
+// for tests that need WAL, concurrent connections, or FTS triggers to behave
+// as they do in production.
+package dbtest
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/chris/jot/internal/db"
+)
+
+// Open creates a fresh database file in a temp directory, runs the schema,
+// and closes it when the test ends.
+func Open(t testing.TB) *db.DB {
+	t.Helper()
+	d, err := db.Open(Path(t))
+	if err != nil {
+		t.Fatalf("opening test db: %v", err)
+	}
+	t.Cleanup(func() { d.Close() })
+	return d
+}
+
+// Path returns a database file path in a temp directory that is removed when
+// the test ends. Use it to open the same file more than once.
+func Path(t testing.TB) string {
+	t.Helper()
+	return filepath.Join(t.TempDir(), "jot.db")
+}
+
+// Concurrently runs fn(0) through fn(n-1) in separate goroutines, waits for
+// them all, and fails the test with each error returned.
+func Concurrently(t testing.TB, n int, fn func(i int) error) {
+	t.Helper()
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fn(i); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,126 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// maxFuzzyCandidates is how many indexed terms each misspelled word expands to.
+const maxFuzzyCandidates = 3
+
+// fuzzyQuery rewrites query for an FTS5 MATCH when it found nothing, replacing
+// each word that isn't in the index with the closest indexed terms from vocab
+// (an fts5vocab table), e.g. "landlrod" → ("landlord"). It returns false when
+// there's nothing to correct or some word has no close match.
+func (d *DB) fuzzyQuery(vocab, query string) (string, bool) {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return "", false
+	}
+	var parts []string
+	changed := false
+	for _, w := range words {
+		candidates, err := d.closeTerms(vocab, w)
+		if err != nil || len(candidates) == 0 {
+			return "", false
+		}
+		if len(candidates) == 1 && candidates[0] == w {
+			parts = append(parts, ftsPhrase(w))
+			continue
+		}
+		changed = true
+		quoted := make([]string, len(candidates))
+		for i, c := range candidates {
+			quoted[i] = ftsPhrase(c)
+		}
+		parts = append(parts, "("+strings.Join(quoted, " OR ")+")")
+	}
+	if !changed {
+		return "", false
+	}
+	return strings.Join(parts, " AND "), true
+}
+
+// closeTerms returns word itself if it's indexed, otherwise up to
+// maxFuzzyCandidates indexed terms within editing distance, closest and most
+// common first. Words under four letters must match exactly (too many
+// neighbours), up to five allow one edit, longer ones two.
+func (d *DB) closeTerms(vocab, word string) ([]string, error) {
+	n := len([]rune(word))
+	maxDist := 2
+	switch {
+	case n < 4:
+		maxDist = 0
+	case n <= 5:
+		maxDist = 1
+	}
+	rows, err := d.conn.Query(fmt.Sprintf(`SELECT term, doc FROM %s WHERE length(term) BETWEEN ? AND ?`, vocab), n-maxDist, n+maxDist)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", vocab, err)
+	}
+	defer rows.Close()
+
+	type candidate struct {
+		term      string
+		dist, doc int
+	}
+	var found []candidate
+	for rows.Next() {
+		var c candidate
+		if err := rows.Scan(&c.term, &c.doc); err != nil {
+			return nil, fmt.Errorf("scanning %s: %w", vocab, err)
+		}
+		if c.term == word {
+			return []string{word}, nil
+		}
+		if c.dist = editDistance(word, c.term); c.dist <= maxDist {
+			found = append(found, c)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].dist != found[j].dist {
+			return found[i].dist < found[j].dist
+		}
+		return found[i].doc > found[j].doc
+	})
+	var terms []string
+	for i := 0; i < len(found) && i < maxFuzzyCandidates; i++ {
+		terms = append(terms, found[i].term)
+	}
+	return terms, nil
+}
+
+// editDistance is the optimal string alignment distance: insertions,
+// deletions, substitutions, and adjacent transpositions each cost one, so
+// "landlrod" is one edit from "landlord".
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,68 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/chris/jot/internal/llm"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"landlord", "landlord", 0},
+		{"landlrod", "landlord", 1}, // transposition
+		{"landlor", "landlord", 1},
+		{"lanlord", "landlord", 1},
+		{"plumer", "plumber", 1},
+		{"kitten", "sitting", 3},
+		{"", "abc", 3},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSearchMemoriesFuzzyFallback(t *testing.T) {
+	d := openTestDB(t)
+	d.SaveMemory("the landlord agreed to replace the boiler", "event", "user", nil, nil, "")
+	d.SaveMemory("paid the plumber for the kitchen sink", "event", "user", nil, nil, "")
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"landlrod", 1},
+		{"landlrod boiler", 1},
+		{"plumer", 1},
+		{"landlord", 1}, // exact terms are unaffected
+		{"zebra", 0},    // nothing close
+		{"cat", 0},      // short words must match exactly
+		{"landlrod zebra", 0},
+	}
+	for _, tt := range tests {
+		got, err := d.SearchMemories(tt.query, "", "", nil, "", 10)
+		if err != nil {
+			t.Fatalf("SearchMemories(%q): %v", tt.query, err)
+		}
+		if len(got) != tt.want {
+			t.Errorf("SearchMemories(%q) = %d results, want %d", tt.query, len(got), tt.want)
+		}
+	}
+}
+
+func TestSearchConversationsFuzzyFallback(t *testing.T) {
+	d := openTestDB(t)
+	d.AppendTranscript("u1", []llm.Message{{Role: "user", Content: "the landlord wants to inspect on Friday"}})
+
+	got, err := d.SearchConversations("u1", "landlrod", "", 10)
+	if err != nil {
+		t.Fatalf("SearchConversations: %v", err)
+	}
+	if len(got) != 1 {
+		t.Errorf("expected misspelled search to find the message, got %d", len(got))
+	}
+}
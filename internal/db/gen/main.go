@@ -0,0 +1,24 @@
+// Command gen writes columns_gen.go from the annotations in schema.sql.
+// Run it with go generate ./internal/db after changing an annotation.
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/chris/jot/internal/db/schemaspec"
+)
+
+func main() {
+	schema, err := os.ReadFile("schema.sql")
+	if err != nil {
+		log.Fatalf("reading schema: %v", err)
+	}
+	src, err := schemaspec.Generate("db", string(schema))
+	if err != nil {
+		log.Fatalf("generating columns: %v", err)
+	}
+	if err := os.WriteFile("columns_gen.go", src, 0o644); err != nil {
+		log.Fatalf("writing columns_gen.go: %v", err)
+	}
+}
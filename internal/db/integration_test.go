@@ -0,0 +1,207 @@
+package db_test
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/chris/jot/internal/db"
+	"github.com/chris/jot/internal/db/dbtest"
+	"github.com/chris/jot/internal/llm"
+)
+
+// These tests run against an on-disk database so WAL, the connection pool,
+// and the FTS triggers behave as they do in production.
+
+func TestFileDBUsesWAL(t *testing.T) {
+	path := dbtest.Path(t)
+	d, err := db.Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer d.Close()
+
+	raw, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("opening raw connection: %v", err)
+	}
+	defer raw.Close()
+	var mode string
+	if err := raw.QueryRow("PRAGMA journal_mode").Scan(&mode); err != nil {
+		t.Fatalf("reading journal_mode: %v", err)
+	}
+	if mode != "wal" {
+		t.Errorf("journal_mode = %q, want wal", mode)
+	}
+}
+
+func TestFileDBReopenKeepsData(t *testing.T) {
+	path := dbtest.Path(t)
+	d, err := db.Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	d.CreateThing("Survives restart", "", "", "", nil)
+	d.SaveMemory("the plumber is named Sal", "observation", "user", nil, nil, "")
+	d.Close()
+
+	d, err = db.Open(path)
+	if err != nil {
+		t.Fatalf("reopening: %v", err)
+	}
+	defer d.Close()
+	if things, _ := d.ListThings("", "", ""); len(things) != 1 {
+		t.Errorf("expected 1 thing after reopen, got %d", len(things))
+	}
+	if mems, _ := d.SearchMemories("plumber", "", "", nil, "", 10); len(mems) != 1 {
+		t.Errorf("expected FTS hit after reopen, got %d", len(mems))
+	}
+	// Reopening must not reseed areas or duplicate schema objects.
+	if areas, _ := d.ListAreas(); len(areas) != 4 {
+		t.Errorf("expected 4 areas after reopen, got %d", len(areas))
+	}
+}
+
+func TestOpenReadOnly(t *testing.T) {
+	path := dbtest.Path(t)
+	missing, err := db.OpenReadOnly(path)
+	if err != nil {
+		t.Fatalf("OpenReadOnly: %v", err)
+	}
+	if _, err := missing.ListTags(); err == nil {
+		t.Error("expected reading a missing database to fail")
+	}
+	missing.Close()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("OpenReadOnly created %s", path)
+	}
+
+	d, err := db.Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	d.CreateThing("Fix gutter", "", "", "", []string{"house"})
+	d.CreateSchedule("morning", "0 8 * * *", "check in")
+	d.Close()
+
+	ro, err := db.OpenReadOnly(path)
+	if err != nil {
+		t.Fatalf("OpenReadOnly: %v", err)
+	}
+	defer ro.Close()
+	if tags, _ := ro.ListTags(); len(tags) != 1 || tags[0] != "house" {
+		t.Errorf("tags = %v, want [house]", tags)
+	}
+	if schedules, _ := ro.ListSchedules(false); len(schedules) != 1 {
+		t.Errorf("expected 1 schedule, got %d", len(schedules))
+	}
+	if _, err := ro.CreateThing("Nope", "", "", "", nil); err == nil {
+		t.Error("expected a write through a read-only connection to fail")
+	}
+}
+
+func TestConcurrentWrites(t *testing.T) {
+	d := dbtest.Open(t)
+	const workers, each = 8, 25
+
+	dbtest.Concurrently(t, workers, func(w int) error {
+		for i := range each {
+			if _, err := d.CreateThing(fmt.Sprintf("thing %d-%d", w, i), "", "", "", []string{"load"}); err != nil {
+				return err
+			}
+			// Habits skip near-duplicate detection, which would fold these together.
+			if _, _, err := d.SaveMemory(fmt.Sprintf("zucchini watering %d-%d", w, i), "habit", "user", nil, nil, ""); err != nil {
+				return err
+			}
+			if _, err := d.CreateThings([]db.NewThing{{Title: fmt.Sprintf("batch %d-%d", w, i)}}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	things, total, err := d.QueryThings(db.ThingQuery{})
+	if err != nil {
+		t.Fatalf("QueryThings: %v", err)
+	}
+	if total != 2*workers*each || len(things) != total {
+		t.Errorf("things = %d (total %d), want %d", len(things), total, 2*workers*each)
+	}
+	mems, err := d.SearchMemories("zucchini", "", "", nil, "", 1000)
+	if err != nil {
+		t.Fatalf("SearchMemories: %v", err)
+	}
+	if len(mems) != workers*each {
+		t.Errorf("FTS found %d memories, want %d", len(mems), workers*each)
+	}
+}
+
+func TestConcurrentReadsDuringWrites(t *testing.T) {
+	d := dbtest.Open(t)
+	d.CreateThing("seed", "", "", "", nil)
+
+	dbtest.Concurrently(t, 6, func(w int) error {
+		for i := range 30 {
+			if w%2 == 0 {
+				if _, err := d.CreateThing(fmt.Sprintf("w%d-%d", w, i), "", "", "", nil); err != nil {
+					return err
+				}
+				continue
+			}
+			if _, err := d.GetSummary(db.SummaryOptions{}); err != nil {
+				return err
+			}
+			if _, err := d.ListTags(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func TestFTSTriggersOnFileDB(t *testing.T) {
+	d := dbtest.Open(t)
+
+	id, _, err := d.SaveMemory("ordered tiles from Portugal", "event", "user", nil, nil, "")
+	if err != nil {
+		t.Fatalf("SaveMemory: %v", err)
+	}
+	if err := d.UpdateMemory(id, map[string]any{"content": "ordered tiles from Spain"}); err != nil {
+		t.Fatalf("UpdateMemory: %v", err)
+	}
+	if mems, _ := d.SearchMemories("Portugal", "", "", nil, "", 10); len(mems) != 0 {
+		t.Errorf("old content still indexed after update: %+v", mems)
+	}
+	if mems, _ := d.SearchMemories("Spain", "", "", nil, "", 10); len(mems) != 1 {
+		t.Errorf("new content not indexed after update")
+	}
+	if err := d.DeleteMemory(id); err != nil {
+		t.Fatalf("DeleteMemory: %v", err)
+	}
+	if mems, _ := d.SearchMemories("Spain", "", "", nil, "", 10); len(mems) != 0 {
+		t.Errorf("deleted memory still indexed")
+	}
+
+	if err := d.AppendTranscript("u1", []llm.Message{{Role: "user", Content: "the zoning board meets Thursday"}}); err != nil {
+		t.Fatalf("AppendTranscript: %v", err)
+	}
+	if msgs, _ := d.SearchConversations("u1", "zoning", "", 10); len(msgs) != 1 {
+		t.Errorf("transcript FTS found %d messages, want 1", len(msgs))
+	}
+}
+
+func TestForeignKeysOnEveryConnection(t *testing.T) {
+	d := dbtest.Open(t)
+
+	// Run enough concurrent work that several pooled connections are used,
+	// then check that each enforces foreign keys.
+	dbtest.Concurrently(t, 8, func(w int) error {
+		missing := int64(1_000_000 + w)
+		_, _, err := d.SaveMemory(fmt.Sprintf("orphan %d", w), "observation", "user", nil, &missing, "")
+		if err == nil {
+			return fmt.Errorf("worker %d: memory linked to missing thing was accepted", w)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,62 @@
+package db
+
+// Packs are the built-in workflow bundles for jot setup. Tags in Areas are
+// the ones a pack's filters and prompts rely on; cron times are in the
+// machine's local time like any other schedule.
+var Packs = []Pack{
+	{
+		Name:        "gtd",
+		Description: "Getting Things Done: next actions, waiting-for, someday/maybe, daily and weekly reviews",
+		Areas: map[string][]string{
+			"work": {"@office", "@calls"},
+			"home": {"@home", "@errands"},
+		},
+		Schedules: []Schedule{
+			{Name: "gtd-daily-review", CronExpr: "0 8 * * 1-5", CheckIn: true,
+				Prompt: "Daily review: list my next actions (tag next) by priority, anything overdue or due today, and what I'm waiting on (tag waiting). Suggest the three to do first."},
+			{Name: "gtd-weekly-review", CronExpr: "0 16 * * 5",
+				Prompt: "Weekly review: what I finished this week, open things with no next step or due date, anything waiting for others over a week, and the someday list (tag someday). Ask which to drop, defer, or make a next action."},
+		},
+		Filters: []SavedFilter{
+			{Name: "next-actions", Tags: []string{"next"}, Sort: "priority", CheckIn: true},
+			{Name: "waiting-for", Tags: []string{"waiting"}, Sort: "oldest"},
+			{Name: "someday", Tags: []string{"someday"}, Sort: "oldest"},
+		},
+	},
+	{
+		Name:        "student",
+		Description: "Assignments and exams: a morning study plan and a Sunday look at the next two weeks",
+		Areas: map[string][]string{
+			"school": {"school", "homework", "exam", "reading"},
+		},
+		Schedules: []Schedule{
+			{Name: "study-plan", CronExpr: "0 7 * * 1-5", CheckIn: true,
+				Prompt: "Plan my study day: homework and exams due in the next week, what to work on first, and anything overdue."},
+			{Name: "weekly-assignments", CronExpr: "0 18 * * 0",
+				Prompt: "Look at homework, reading, and exams due in the next two weeks and help me spread the work across the week."},
+		},
+		Filters: []SavedFilter{
+			{Name: "due-soon", Tags: []string{"homework", "exam", "reading"}, DueWithinDays: 7, Sort: "due_date", CheckIn: true},
+			{Name: "exams", Tags: []string{"exam"}, Sort: "due_date"},
+		},
+	},
+	{
+		Name:        "freelancer",
+		Description: "Client deadlines, proposals, and invoicing: a weekday check-in and a monthly invoice reminder",
+		Areas: map[string][]string{
+			"work":  {"client", "proposal", "invoice"},
+			"admin": {"admin", "tax"},
+		},
+		Schedules: []Schedule{
+			{Name: "freelance-morning", CronExpr: "0 8 * * 1-5", CheckIn: true,
+				Prompt: "Morning check-in: client deliverables due this week, unpaid invoices (tag invoice), and open proposals (tag proposal) that need a follow-up."},
+			{Name: "invoice-reminder", CronExpr: "0 9 1 * *",
+				Prompt: "Start of the month: list client work I completed last month that may need invoicing, plus any open invoice items and tax deadlines."},
+		},
+		Filters: []SavedFilter{
+			{Name: "client-deadlines", Tags: []string{"client"}, DueWithinDays: 14, Sort: "due_date", CheckIn: true},
+			{Name: "unpaid-invoices", Tags: []string{"invoice"}, Sort: "oldest"},
+			{Name: "proposals", Tags: []string{"proposal"}, Sort: "newest"},
+		},
+	},
+}
@@ -14,6 +14,88 @@ type Thing struct {
 	CompletedAt string   `json:"completed_at,omitempty"`
 }
 
+// NewThing holds the fields for creating a thing.
+type NewThing struct {
+	Title       string
+	Notes       string
+	Priority    string
+	DueDate     string
+	Tags        []string
+	Status      string // defaults to open
+	CreatedAt   string // UTC "YYYY-MM-DD HH:MM:SS"; empty = now. Imports keep the original time
+	CompletedAt string // for imported done things
+}
+
+// NewMemory holds the fields for BulkSaveMemories.
+type NewMemory struct {
+	Content   string
+	Category  string
+	Source    string
+	Status    string // active (default) or proposed
+	Tags      []string
+	ThingID   *int64
+	ExpiresAt string
+	CreatedAt string // UTC "YYYY-MM-DD HH:MM:SS"; empty = now. Imports keep the original time
+}
+
+// SavedMemory is the outcome of one BulkSaveMemories entry: the new ID, or
+// the existing one when the memory was a duplicate.
+type SavedMemory struct {
+	ID        int64 `json:"id"`
+	Duplicate bool  `json:"duplicate,omitempty"`
+}
+
+// HabitLog is one occurrence of a habit for BulkLogHabits.
+type HabitLog struct {
+	Habit  string // canonical habit name, stored as the tag
+	Note   string // content; defaults to the habit name
+	At     string // UTC "YYYY-MM-DD HH:MM:SS"; empty = now
+	Source string // defaults to "import"
+}
+
+// ThingEvent records an automatic change or flag on a thing.
+type ThingEvent struct {
+	ID        int64  `json:"id"`
+	ThingID   int64  `json:"thing_id"`
+	Title     string `json:"title"`
+	Kind      string `json:"kind"` // escalated, stale
+	Detail    string `json:"detail,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
+
+// SavedFilter is a named thing query. DueWithinDays is relative to the day
+// the filter runs, so "due this week" stays current.
+type SavedFilter struct {
+	ID            int64    `json:"id"`
+	Name          string   `json:"name"`
+	Status        string   `json:"status,omitempty"`
+	Priority      string   `json:"priority,omitempty"`
+	Tags          []string `json:"tags,omitempty"` // any of
+	DueWithinDays int      `json:"due_within_days,omitempty"`
+	Sort          string   `json:"sort,omitempty"`
+	Limit         int      `json:"limit,omitempty"`
+	CheckIn       bool     `json:"check_in,omitempty"` // run during check-ins
+	CreatedAt     string   `json:"created_at"`
+	UpdatedAt     string   `json:"updated_at"`
+}
+
+// Area is an area of focus that tags map into.
+type Area struct {
+	ID        int64    `json:"id"`
+	Name      string   `json:"name"`
+	Tags      []string `json:"tags"`
+	CreatedAt string   `json:"created_at"`
+}
+
+// AreaRollup counts an area's things: open and overdue now, and completed
+// since a given date.
+type AreaRollup struct {
+	Area      string `json:"area"`
+	Open      int    `json:"open"`
+	Overdue   int    `json:"overdue"`
+	Completed int    `json:"completed"`
+}
+
 type Memory struct {
 	ID        int64    `json:"id"`
 	Content   string   `json:"content"`
@@ -25,18 +107,84 @@ type Memory struct {
 	CreatedAt string   `json:"created_at"`
 }
 
+// TranscriptMessage is one user or assistant message from conversation history.
+type TranscriptMessage struct {
+	ID        int64  `json:"id"`
+	UserID    string `json:"user_id"`
+	Role      string `json:"role"`
+	Content   string `json:"content"`
+	CreatedAt string `json:"created_at"`
+}
+
 type Schedule struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	CronExpr    string `json:"cron_expr,omitempty"`
+	Prompt      string `json:"prompt"`
+	Enabled     bool   `json:"enabled"`
+	LastRun     string `json:"last_run,omitempty"`
+	FireAt      string `json:"fire_at,omitempty"`
+	Fired       bool   `json:"fired,omitempty"`
+	CheckIn     bool   `json:"check_in,omitempty"`     // prompt gets precomputed check-in context
+	MemoryDays  int    `json:"memory_days,omitempty"`  // check-in memory window; 0 = default
+	MemoryLimit int    `json:"memory_limit,omitempty"` // check-in memory count; 0 = default
+	Digest      bool   `json:"digest,omitempty"`       // reply gets the last 24h of changes appended
+	CreatedAt   string `json:"created_at"`
+}
+
+// Delivery is an outbound message in the delivery queue.
+type Delivery struct {
+	ID            int64  `json:"id"`
+	Label         string `json:"label"`
+	Content       string `json:"content"`
+	Status        string `json:"status"` // pending, failed
+	Attempts      int    `json:"attempts"`
+	LastError     string `json:"last_error,omitempty"`
+	NextAttemptAt string `json:"next_attempt_at"`
+	CreatedAt     string `json:"created_at"`
+}
+
+// PendingDeletion is a bot reply waiting to be deleted.
+type PendingDeletion struct {
+	ID        int64
+	ChannelID string
+	MessageID string
+}
+
+// AuditEntry is one write the agent made through a tool.
+type AuditEntry struct {
 	ID        int64  `json:"id"`
-	Name      string `json:"name"`
-	CronExpr  string `json:"cron_expr,omitempty"`
-	Prompt    string `json:"prompt"`
-	Enabled   bool   `json:"enabled"`
-	LastRun   string `json:"last_run,omitempty"`
-	FireAt    string `json:"fire_at,omitempty"`
-	Fired     bool   `json:"fired,omitempty"`
+	Tool      string `json:"tool"`
+	Action    string `json:"action"` // create, update, delete
+	Target    string `json:"target,omitempty"`
+	Detail    string `json:"detail,omitempty"`
+	Profile   string `json:"profile,omitempty"`
 	CreatedAt string `json:"created_at"`
 }
 
+// ScheduleRun is the delivered output of one recurring schedule run, such as
+// a check-in or weekly review. jot publish renders these as an operating log.
+type ScheduleRun struct {
+	ID           int64  `json:"id"`
+	ScheduleName string `json:"schedule_name"`
+	CheckIn      bool   `json:"check_in,omitempty"`
+	Output       string `json:"output"`
+	CreatedAt    string `json:"created_at"`
+}
+
+// Job is a background agent task started with start_job.
+type Job struct {
+	ID         int64  `json:"id"`
+	Title      string `json:"title"`
+	Prompt     string `json:"prompt"`
+	Status     string `json:"status"` // queued, running, done, failed
+	Result     string `json:"result,omitempty"`
+	Error      string `json:"error,omitempty"`
+	CreatedAt  string `json:"created_at"`
+	StartedAt  string `json:"started_at,omitempty"`
+	FinishedAt string `json:"finished_at,omitempty"`
+}
+
 type Watch struct {
 	ID        int64    `json:"id"`
 	Name      string   `json:"name"`
@@ -0,0 +1,137 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// defaultAreas seeds a new database; each area starts with its own name as a tag.
+var defaultAreas = []string{"work", "health", "home", "family"}
+
+// ListAreas returns every area with the tags mapped into it.
+func (d *DB) ListAreas() ([]Area, error) {
+	rows, err := d.conn.Query(`SELECT a.id, a.name, a.created_at, COALESCE(at.tag, '')
+		FROM areas a LEFT JOIN area_tags at ON at.area_id = a.id
+		ORDER BY a.name, at.tag`)
+	if err != nil {
+		return nil, fmt.Errorf("listing areas: %w", err)
+	}
+	defer rows.Close()
+	var areas []Area
+	for rows.Next() {
+		var a Area
+		var tag string
+		if err := rows.Scan(&a.ID, &a.Name, &a.CreatedAt, &tag); err != nil {
+			return nil, fmt.Errorf("scanning area: %w", err)
+		}
+		if n := len(areas); n > 0 && areas[n-1].ID == a.ID {
+			areas[n-1].Tags = append(areas[n-1].Tags, tag)
+			continue
+		}
+		a.Tags = []string{}
+		if tag != "" {
+			a.Tags = append(a.Tags, tag)
+		}
+		areas = append(areas, a)
+	}
+	return areas, rows.Err()
+}
+
+// SetArea creates the area if needed and maps tags into it. A tag already in
+// another area moves to this one. Existing mappings for the area are kept.
+// The name and tags are trimmed and lowercased; rollups match thing tags
+// case-insensitively.
+func (d *DB) SetArea(name string, tags []string) (int64, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return 0, fmt.Errorf("area name is required")
+	}
+	tx, err := d.conn.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("beginning set area: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`INSERT OR IGNORE INTO areas (name) VALUES (?)`, name); err != nil {
+		return 0, fmt.Errorf("creating area %s: %w", name, err)
+	}
+	var id int64
+	if err := tx.QueryRow(`SELECT id FROM areas WHERE name = ?`, name).Scan(&id); err != nil {
+		return 0, fmt.Errorf("looking up area %s: %w", name, err)
+	}
+	for _, tag := range tags {
+		if tag = strings.ToLower(strings.TrimSpace(tag)); tag == "" {
+			continue
+		}
+		if _, err := tx.Exec(`INSERT OR REPLACE INTO area_tags (tag, area_id) VALUES (?, ?)`, tag, id); err != nil {
+			return 0, fmt.Errorf("mapping tag %s: %w", tag, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("committing area: %w", err)
+	}
+	d.thingsChanged()
+	return id, nil
+}
+
+// DeleteArea removes an area and its tag mappings. Things and tags are untouched.
+func (d *DB) DeleteArea(name string) (bool, error) {
+	res, err := d.conn.Exec(`DELETE FROM areas WHERE name = ?`, strings.ToLower(strings.TrimSpace(name)))
+	if err != nil {
+		return false, fmt.Errorf("deleting area %s: %w", name, err)
+	}
+	d.thingsChanged()
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// AreaRollups counts each area's things: open and overdue (relative to today)
+// among openStatuses, and completed on or after completedSince (a UTC
+// YYYY-MM-DD date, or a YYYY-MM-DD HH:MM:SS instant).
+// A thing counts once per area even if several of its tags map there.
+func (d *DB) AreaRollups(openStatuses []string, today, completedSince string) ([]AreaRollup, error) {
+	if len(openStatuses) == 0 {
+		openStatuses = []string{"open", "active"}
+	}
+	in := strings.TrimSuffix(strings.Repeat("?,", len(openStatuses)), ",")
+	var args []any
+	for range 2 {
+		for _, st := range openStatuses {
+			args = append(args, st)
+		}
+	}
+	args = append(args, today, completedSince)
+
+	rows, err := d.conn.Query(`SELECT a.name,
+			COUNT(DISTINCT CASE WHEN t.status IN (`+in+`) THEN t.id END),
+			COUNT(DISTINCT CASE WHEN t.status IN (`+in+`) AND COALESCE(t.due_date,'') != '' AND t.due_date < ? THEN t.id END),
+			COUNT(DISTINCT CASE WHEN t.status = 'done' AND t.completed_at >= ? THEN t.id END)
+		FROM areas a
+		LEFT JOIN area_tags at ON at.area_id = a.id
+		LEFT JOIN things t ON EXISTS (SELECT 1 FROM json_each(COALESCE(t.tags,'[]')) j WHERE lower(j.value) = at.tag)
+		GROUP BY a.id ORDER BY a.name`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("rolling up areas: %w", err)
+	}
+	defer rows.Close()
+	var rollups []AreaRollup
+	for rows.Next() {
+		var r AreaRollup
+		if err := rows.Scan(&r.Area, &r.Open, &r.Overdue, &r.Completed); err != nil {
+			return nil, fmt.Errorf("scanning area rollup: %w", err)
+		}
+		rollups = append(rollups, r)
+	}
+	return rollups, rows.Err()
+}
+
+// seedAreas creates the default areas. Open calls it only when the areas
+// table is new, so areas the user deletes stay deleted.
+func (d *DB) seedAreas() error {
+	for _, name := range defaultAreas {
+		if _, err := d.SetArea(name, []string{name}); err != nil {
+			return fmt.Errorf("seeding area %s: %w", name, err)
+		}
+	}
+	return nil
+}
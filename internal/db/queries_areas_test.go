@@ -0,0 +1,143 @@
+package db
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDefaultAreasSeeded(t *testing.T) {
+	d := openTestDB(t)
+
+	areas, err := d.ListAreas()
+	if err != nil {
+		t.Fatalf("ListAreas: %v", err)
+	}
+	if len(areas) != len(defaultAreas) {
+		t.Fatalf("expected %d seeded areas, got %+v", len(defaultAreas), areas)
+	}
+	for _, a := range areas {
+		if len(a.Tags) != 1 || a.Tags[0] != a.Name {
+			t.Errorf("area %s tags = %v, want [%s]", a.Name, a.Tags, a.Name)
+		}
+	}
+}
+
+func TestSetAreaMovesTags(t *testing.T) {
+	d := openTestDB(t)
+
+	if _, err := d.SetArea("Home", []string{"house", "garden"}); err != nil {
+		t.Fatalf("SetArea: %v", err)
+	}
+	if _, err := d.SetArea("hobbies", []string{"garden"}); err != nil {
+		t.Fatalf("SetArea: %v", err)
+	}
+	areas, _ := d.ListAreas()
+	got := map[string][]string{}
+	for _, a := range areas {
+		got[a.Name] = a.Tags
+	}
+	if len(got["home"]) != 2 || got["home"][0] != "home" || got["home"][1] != "house" {
+		t.Errorf("home tags = %v, want [home house]", got["home"])
+	}
+	if len(got["hobbies"]) != 1 || got["hobbies"][0] != "garden" {
+		t.Errorf("hobbies tags = %v, want [garden]", got["hobbies"])
+	}
+
+	if found, _ := d.DeleteArea("hobbies"); !found {
+		t.Error("expected hobbies to be deleted")
+	}
+	if found, _ := d.DeleteArea("hobbies"); found {
+		t.Error("second delete should report not found")
+	}
+	if _, err := d.SetArea(" ", nil); err == nil {
+		t.Error("expected error for empty area name")
+	}
+}
+
+func TestSetAreaNormalizesTags(t *testing.T) {
+	d := openTestDB(t)
+	// A tag saved before SetArea lowercased them is fixed on open.
+	d.conn.Exec(`INSERT INTO area_tags (tag, area_id) SELECT 'Chores', id FROM areas WHERE name = 'home'`)
+	if err := d.migrate(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	if _, err := d.SetArea("Side", []string{" Writing ", "BLOG"}); err != nil {
+		t.Fatalf("SetArea: %v", err)
+	}
+	got := map[string][]string{}
+	areas, _ := d.ListAreas()
+	for _, a := range areas {
+		got[a.Name] = a.Tags
+	}
+	if strings.Join(got["side"], ",") != "blog,writing" {
+		t.Errorf("side tags = %v, want [blog writing]", got["side"])
+	}
+	if strings.Join(got["home"], ",") != "chores,home" {
+		t.Errorf("home tags = %v, want [chores home]", got["home"])
+	}
+
+	// Things tagged in another case still roll up.
+	d.CreateThing("Draft post", "", "", "", []string{"Blog"})
+	rollups, _ := d.AreaRollups(nil, "2026-03-10", "2000-01-01")
+	for _, r := range rollups {
+		if r.Area == "side" && r.Open != 1 {
+			t.Errorf("side rollup = %+v, want 1 open", r)
+		}
+	}
+	if s, _ := d.GetSummary(SummaryOptions{Today: "2026-03-10", Area: "side"}); s.Counts["open"] != 1 {
+		t.Errorf("side summary counts = %v, want 1 open", s.Counts)
+	}
+}
+
+func TestAreaRollups(t *testing.T) {
+	d := openTestDB(t)
+	d.SetArea("home", []string{"house"})
+
+	d.CreateThing("Quarterly report", "", "normal", "2026-03-01", []string{"work"})
+	d.CreateThing("Fix gutter", "", "normal", "", []string{"house", "home"})
+	done, _ := d.CreateThing("Ship release", "", "normal", "", []string{"work"})
+	d.CompleteThing(done)
+	d.CreateThing("Untagged", "", "normal", "", nil)
+
+	rollups, err := d.AreaRollups(nil, "2026-03-10", "2000-01-01")
+	if err != nil {
+		t.Fatalf("AreaRollups: %v", err)
+	}
+	got := map[string]AreaRollup{}
+	for _, r := range rollups {
+		got[r.Area] = r
+	}
+	if r := got["work"]; r.Open != 1 || r.Overdue != 1 || r.Completed != 1 {
+		t.Errorf("work rollup = %+v, want 1 open, 1 overdue, 1 completed", r)
+	}
+	// Two tags in the same area still count the thing once.
+	if r := got["home"]; r.Open != 1 || r.Overdue != 0 || r.Completed != 0 {
+		t.Errorf("home rollup = %+v, want 1 open", r)
+	}
+	if r := got["health"]; r.Open != 0 || r.Completed != 0 {
+		t.Errorf("health rollup = %+v, want zeros", r)
+	}
+	// The completion window can start at an instant, matching
+	// ListCompletedBetween.
+	d.conn.Exec(`UPDATE things SET completed_at = '2026-03-05 18:00:00' WHERE id = ?`, done)
+	for since, want := range map[string]int{"2026-03-05 12:00:00": 1, "2026-03-05 19:00:00": 0} {
+		rollups, _ := d.AreaRollups(nil, "2026-03-10", since)
+		for _, r := range rollups {
+			if r.Area == "work" && r.Completed != want {
+				t.Errorf("work completed since %s = %d, want %d", since, r.Completed, want)
+			}
+		}
+	}
+
+	s, err := d.GetSummary(SummaryOptions{Today: "2026-03-10", Area: "home"})
+	if err != nil {
+		t.Fatalf("GetSummary: %v", err)
+	}
+	if s.Counts["open"] != 1 || s.Areas != nil {
+		t.Errorf("area-scoped summary counts = %v, areas = %v", s.Counts, s.Areas)
+	}
+	if s, _ := d.GetSummary(SummaryOptions{Today: "2026-03-10"}); len(s.Areas) != len(defaultAreas) {
+		t.Errorf("unscoped summary should roll up %d areas, got %d", len(defaultAreas), len(s.Areas))
+	}
+}
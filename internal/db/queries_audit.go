@@ -0,0 +1,57 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+// LogAudit records a write the agent made.
+func (d *DB) LogAudit(e AuditEntry) error {
+	if err := CheckValue("audit_log", "action", e.Action); err != nil {
+		return err
+	}
+	_, err := d.conn.Exec(`INSERT INTO audit_log (tool, action, target, detail, profile) VALUES (?, ?, ?, ?, ?)`,
+		e.Tool, e.Action, e.Target, e.Detail, e.Profile)
+	if err != nil {
+		return fmt.Errorf("logging %s: %w", e.Tool, err)
+	}
+	return nil
+}
+
+// ListAudit returns the writes logged since the given time, oldest first.
+func (d *DB) ListAudit(since time.Time) ([]AuditEntry, error) {
+	entries, err := d.scanAudit(`SELECT id, tool, action, target, detail, profile, created_at
+		FROM audit_log WHERE created_at >= ? ORDER BY created_at ASC, id ASC`,
+		since.UTC().Format(time.DateTime))
+	if err != nil {
+		return nil, fmt.Errorf("listing audit log: %w", err)
+	}
+	return entries, nil
+}
+
+func (d *DB) scanAudit(query string, args ...any) ([]AuditEntry, error) {
+	rows, err := d.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []AuditEntry
+	for rows.Next() {
+		var e AuditEntry
+		if err := rows.Scan(&e.ID, &e.Tool, &e.Action, &e.Target, &e.Detail, &e.Profile, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning audit entry: %w", err)
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// PruneAuditLog deletes entries older than the given number of days.
+func (d *DB) PruneAuditLog(olderThanDays int) (int64, error) {
+	res, err := d.conn.Exec(`DELETE FROM audit_log WHERE created_at < datetime('now', ?)`,
+		fmt.Sprintf("-%d days", olderThanDays))
+	if err != nil {
+		return 0, fmt.Errorf("pruning audit log: %w", err)
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,33 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAuditLog(t *testing.T) {
+	d := openTestDB(t)
+
+	if err := d.LogAudit(AuditEntry{Tool: "create_thing", Action: "create", Target: "thing #1", Detail: "Buy milk", Profile: "cli"}); err != nil {
+		t.Fatalf("LogAudit: %v", err)
+	}
+	if err := d.LogAudit(AuditEntry{Tool: "delete_memory", Action: "delete", Target: "memory #4"}); err != nil {
+		t.Fatalf("LogAudit: %v", err)
+	}
+	if err := d.LogAudit(AuditEntry{Tool: "list_things", Action: "read"}); err == nil {
+		t.Error("expected an error for an unknown action")
+	}
+	d.conn.Exec(`INSERT INTO audit_log (tool, action, created_at) VALUES ('update_thing', 'update', datetime('now', '-2 days'))`)
+
+	entries, err := d.ListAudit(time.Now().Add(-24 * time.Hour))
+	if err != nil {
+		t.Fatalf("ListAudit: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Target != "thing #1" || entries[1].Action != "delete" {
+		t.Fatalf("entries = %+v", entries)
+	}
+
+	if n, err := d.PruneAuditLog(1); err != nil || n != 1 {
+		t.Errorf("PruneAuditLog = %d, %v; want 1", n, err)
+	}
+}
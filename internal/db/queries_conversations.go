@@ -3,6 +3,7 @@ package db
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/chris/jot/internal/llm"
@@ -119,3 +120,86 @@ func (d *DB) PruneOldSummaries(olderThanDays int) (int64, error) {
 	}
 	return res.RowsAffected()
 }
+
+// AppendTranscript records user and assistant text messages for search.
+// Tool calls and tool results are skipped.
+func (d *DB) AppendTranscript(userID string, messages []llm.Message) error {
+	for _, m := range messages {
+		if m.ToolCallID != "" || strings.TrimSpace(m.Content) == "" {
+			continue
+		}
+		if _, err := d.conn.Exec(
+			`INSERT INTO conversation_messages (user_id, role, content) VALUES (?, ?, ?)`,
+			userID, m.Role, m.Content,
+		); err != nil {
+			return fmt.Errorf("appending transcript: %w", err)
+		}
+	}
+	return nil
+}
+
+// PruneTranscript deletes transcript messages older than the given number of
+// days.
+func (d *DB) PruneTranscript(olderThanDays int) (int64, error) {
+	res, err := d.conn.Exec(`DELETE FROM conversation_messages WHERE created_at < datetime('now', ?)`,
+		fmt.Sprintf("-%d days", olderThanDays))
+	if err != nil {
+		return 0, fmt.Errorf("pruning transcript: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// SearchConversations searches one user's past conversation transcripts. It
+// uses FTS5 ranking, retries with misspellings corrected when that finds
+// nothing, and falls back to LIKE if the query isn't valid FTS syntax.
+func (d *DB) SearchConversations(userID, query, since string, limit int) ([]TranscriptMessage, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	sinceClause := ""
+	args := []any{query, userID}
+	if since != "" {
+		sinceClause = " AND m.created_at >= ?"
+		args = append(args, since)
+	}
+	args = append(args, limit)
+
+	ftsQuery := `SELECT m.id, m.user_id, m.role, m.content, m.created_at
+		FROM conversation_messages_fts f
+		JOIN conversation_messages m ON m.id = f.rowid
+		WHERE conversation_messages_fts MATCH ? AND m.user_id = ?` + sinceClause + `
+		ORDER BY rank LIMIT ?`
+	results, err := d.scanTranscript(ftsQuery, args...)
+	if err == nil && len(results) == 0 {
+		if fuzzy, ok := d.fuzzyQuery("conversation_messages_vocab", query); ok {
+			args[0] = fuzzy
+			return d.scanTranscript(ftsQuery, args...)
+		}
+	}
+	if err == nil {
+		return results, nil
+	}
+
+	args[0] = "%" + query + "%"
+	return d.scanTranscript(`SELECT m.id, m.user_id, m.role, m.content, m.created_at
+		FROM conversation_messages m
+		WHERE m.content LIKE ? AND m.user_id = ?`+sinceClause+`
+		ORDER BY m.created_at DESC LIMIT ?`, args...)
+}
+
+func (d *DB) scanTranscript(query string, args ...any) ([]TranscriptMessage, error) {
+	rows, err := d.conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying transcript: %w", err)
+	}
+	defer rows.Close()
+	var out []TranscriptMessage
+	for rows.Next() {
+		var m TranscriptMessage
+		if err := rows.Scan(&m.ID, &m.UserID, &m.Role, &m.Content, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning transcript message: %w", err)
+		}
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
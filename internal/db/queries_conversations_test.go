@@ -180,3 +180,58 @@ func TestPruneOldSummaries(t *testing.T) {
 		t.Errorf("wrong summary survived: %s", remaining[0].Summary)
 	}
 }
+
+func TestAppendAndSearchConversations(t *testing.T) {
+	d := openTestDB(t)
+
+	err := d.AppendTranscript("user1", []llm.Message{
+		{Role: "user", Content: "The contractor quoted $4k for the deck"},
+		{Role: "assistant", Content: "", ToolCalls: []llm.ToolCall{{ID: "t1", Name: "save_memory"}}},
+		{Role: "user", Content: `{"id":1}`, ToolCallID: "t1"},
+		{Role: "assistant", Content: "Noted the deck quote."},
+	})
+	if err != nil {
+		t.Fatalf("AppendTranscript: %v", err)
+	}
+
+	results, err := d.SearchConversations("user1", "contractor", "", 10)
+	if err != nil {
+		t.Fatalf("SearchConversations: %v", err)
+	}
+	if len(results) != 1 || results[0].Role != "user" || results[0].UserID != "user1" {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+
+	if results, _ := d.SearchConversations("user1", "deck", "", 10); len(results) != 2 {
+		t.Errorf("expected 2 matches for deck (tool messages skipped), got %d", len(results))
+	}
+	if results, _ := d.SearchConversations("user1", "contractor", "2999-01-01", 10); len(results) != 0 {
+		t.Errorf("expected since filter to exclude results, got %d", len(results))
+	}
+	// Invalid FTS syntax falls back to LIKE.
+	if results, err := d.SearchConversations("user1", "$4k", "", 10); err != nil || len(results) != 1 {
+		t.Errorf("expected LIKE fallback to find 1, got %d (err %v)", len(results), err)
+	}
+
+	// Another user's transcript stays out of the results.
+	d.AppendTranscript("user2", []llm.Message{{Role: "user", Content: "my contractor never showed up"}})
+	if results, _ := d.SearchConversations("user1", "contractor", "", 10); len(results) != 1 || results[0].UserID != "user1" {
+		t.Errorf("expected only user1's message, got %+v", results)
+	}
+	if results, _ := d.SearchConversations("user2", "$4k", "", 10); len(results) != 0 {
+		t.Errorf("expected LIKE fallback scoped to user2, got %+v", results)
+	}
+}
+
+func TestPruneTranscript(t *testing.T) {
+	d := openTestDB(t)
+	d.conn.Exec(`INSERT INTO conversation_messages (user_id, role, content, created_at) VALUES ('user1', 'user', 'old parking question', datetime('now', '-400 days'))`)
+	d.AppendTranscript("user1", []llm.Message{{Role: "user", Content: "new parking question"}})
+
+	if n, err := d.PruneTranscript(365); err != nil || n != 1 {
+		t.Fatalf("PruneTranscript = %d, %v; want 1", n, err)
+	}
+	if results, _ := d.SearchConversations("user1", "parking", "", 10); len(results) != 1 || results[0].Content != "new parking question" {
+		t.Errorf("expected only the recent message, got %+v", results)
+	}
+}
@@ -0,0 +1,79 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+const deliveryColumns = `id, label, content, status, attempts, last_error, next_attempt_at, created_at`
+
+// EnqueueDelivery adds a message to the delivery queue, due now.
+func (d *DB) EnqueueDelivery(label, content string) (int64, error) {
+	res, err := d.conn.Exec(`INSERT INTO deliveries (label, content) VALUES (?, ?)`, label, content)
+	if err != nil {
+		return 0, fmt.Errorf("enqueuing delivery: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// DueDeliveries returns up to limit pending deliveries whose next attempt is
+// due, oldest first.
+func (d *DB) DueDeliveries(limit int) ([]Delivery, error) {
+	out, err := d.scanDeliveries(`SELECT `+deliveryColumns+` FROM deliveries
+		WHERE status = 'pending' AND next_attempt_at <= datetime('now')
+		ORDER BY id LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("listing due deliveries: %w", err)
+	}
+	return out, nil
+}
+
+func (d *DB) scanDeliveries(query string, args ...any) ([]Delivery, error) {
+	rows, err := d.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Delivery
+	for rows.Next() {
+		var dl Delivery
+		if err := rows.Scan(&dl.ID, &dl.Label, &dl.Content, &dl.Status, &dl.Attempts, &dl.LastError, &dl.NextAttemptAt, &dl.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning delivery: %w", err)
+		}
+		out = append(out, dl)
+	}
+	return out, rows.Err()
+}
+
+// DeliverySent removes a delivered message from the queue.
+func (d *DB) DeliverySent(id int64) error {
+	if _, err := d.conn.Exec(`DELETE FROM deliveries WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("removing delivery %d: %w", id, err)
+	}
+	return nil
+}
+
+// DeliveryFailed records a failed attempt. With a zero retryAt the delivery
+// is given up on and marked failed; otherwise it is retried then.
+func (d *DB) DeliveryFailed(id int64, errMsg string, retryAt time.Time) error {
+	status, next := "failed", any(nil)
+	if !retryAt.IsZero() {
+		status, next = "pending", retryAt.UTC().Format(time.DateTime)
+	}
+	if _, err := d.conn.Exec(`UPDATE deliveries SET status = ?, attempts = attempts + 1, last_error = ?,
+		next_attempt_at = COALESCE(?, next_attempt_at) WHERE id = ?`, status, errMsg, next, id); err != nil {
+		return fmt.Errorf("recording failed delivery %d: %w", id, err)
+	}
+	return nil
+}
+
+// PruneFailedDeliveries deletes deliveries that were given up on more than
+// the given number of days ago.
+func (d *DB) PruneFailedDeliveries(olderThanDays int) (int64, error) {
+	res, err := d.conn.Exec(`DELETE FROM deliveries WHERE status = 'failed' AND created_at < datetime('now', ?)`,
+		fmt.Sprintf("-%d days", olderThanDays))
+	if err != nil {
+		return 0, fmt.Errorf("pruning deliveries: %w", err)
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,48 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDeliveryQueue(t *testing.T) {
+	d := openTestDB(t)
+
+	first, _ := d.EnqueueDelivery("scheduler[morning]", "Good morning")
+	second, _ := d.EnqueueDelivery("reminder[3]", "Call the dentist")
+
+	due, err := d.DueDeliveries(10)
+	if err != nil {
+		t.Fatalf("DueDeliveries: %v", err)
+	}
+	if len(due) != 2 || due[0].ID != first || due[0].Status != "pending" {
+		t.Fatalf("due = %+v", due)
+	}
+
+	// A retry in the future takes it out of the due list until then.
+	if err := d.DeliveryFailed(first, "connection reset", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("DeliveryFailed: %v", err)
+	}
+	if err := d.DeliverySent(second); err != nil {
+		t.Fatalf("DeliverySent: %v", err)
+	}
+	if due, _ := d.DueDeliveries(10); len(due) != 0 {
+		t.Errorf("expected nothing due, got %+v", due)
+	}
+
+	d.conn.Exec(`UPDATE deliveries SET next_attempt_at = datetime('now', '-1 minute')`)
+	due, _ = d.DueDeliveries(10)
+	if len(due) != 1 || due[0].Attempts != 1 || due[0].LastError != "connection reset" {
+		t.Fatalf("retry = %+v", due)
+	}
+
+	// Giving up marks it failed; it stays for inspection until pruned.
+	d.DeliveryFailed(first, "still down", time.Time{})
+	if due, _ := d.DueDeliveries(10); len(due) != 0 {
+		t.Errorf("failed delivery still due: %+v", due)
+	}
+	d.conn.Exec(`UPDATE deliveries SET created_at = datetime('now', '-40 days')`)
+	if n, err := d.PruneFailedDeliveries(30); err != nil || n != 1 {
+		t.Errorf("PruneFailedDeliveries = %d, %v; want 1", n, err)
+	}
+}
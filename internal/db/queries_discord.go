@@ -0,0 +1,85 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+// SetLastMessage records messageID as the newest message handled in a Discord
+// channel. Older IDs never move the mark back.
+func (d *DB) SetLastMessage(channelID, messageID string) error {
+	// Snowflakes grow with time: a longer ID is newer, equal lengths compare
+	// as strings.
+	_, err := d.conn.Exec(`INSERT INTO discord_channels (channel_id, last_message_id) VALUES (?, ?)
+		ON CONFLICT(channel_id) DO UPDATE SET last_message_id = excluded.last_message_id, updated_at = datetime('now')
+		WHERE length(excluded.last_message_id) > length(last_message_id)
+		   OR (length(excluded.last_message_id) = length(last_message_id) AND excluded.last_message_id > last_message_id)`,
+		channelID, messageID)
+	if err != nil {
+		return fmt.Errorf("saving last message for channel %s: %w", channelID, err)
+	}
+	return nil
+}
+
+// LastMessages returns the newest handled message ID by channel ID.
+func (d *DB) LastMessages() (map[string]string, error) {
+	rows, err := d.conn.Query(`SELECT channel_id, last_message_id FROM discord_channels`)
+	if err != nil {
+		return nil, fmt.Errorf("listing last messages: %w", err)
+	}
+	defer rows.Close()
+	last := make(map[string]string)
+	for rows.Next() {
+		var ch, id string
+		if err := rows.Scan(&ch, &id); err != nil {
+			return nil, fmt.Errorf("scanning last message: %w", err)
+		}
+		last[ch] = id
+	}
+	return last, rows.Err()
+}
+
+// ScheduleDeletions records bot replies in a channel to delete at due.
+func (d *DB) ScheduleDeletions(channelID string, messageIDs []string, due time.Time) error {
+	tx, err := d.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("scheduling deletions: %w", err)
+	}
+	defer tx.Rollback()
+	at := due.UTC().Format(time.DateTime)
+	for _, id := range messageIDs {
+		if _, err := tx.Exec(`INSERT INTO discord_deletions (channel_id, message_id, due_at) VALUES (?, ?, ?)`,
+			channelID, id, at); err != nil {
+			return fmt.Errorf("scheduling deletion of %s: %w", id, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// DueDeletions returns up to limit replies whose deletion is due, oldest first.
+func (d *DB) DueDeletions(limit int) ([]PendingDeletion, error) {
+	rows, err := d.conn.Query(`SELECT id, channel_id, message_id FROM discord_deletions
+		WHERE due_at <= datetime('now') ORDER BY due_at, id LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("listing due deletions: %w", err)
+	}
+	defer rows.Close()
+	var out []PendingDeletion
+	for rows.Next() {
+		var p PendingDeletion
+		if err := rows.Scan(&p.ID, &p.ChannelID, &p.MessageID); err != nil {
+			return nil, fmt.Errorf("scanning deletion: %w", err)
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+// DeletionDone removes a pending deletion once it has been carried out or
+// given up on.
+func (d *DB) DeletionDone(id int64) error {
+	if _, err := d.conn.Exec(`DELETE FROM discord_deletions WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("removing deletion %d: %w", id, err)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSetLastMessage(t *testing.T) {
+	d := openTestDB(t)
+
+	for _, id := range []string{"999", "1001", "1000"} {
+		if err := d.SetLastMessage("dm", id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	last, err := d.LastMessages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last["dm"] != "1001" {
+		t.Errorf("last message = %q, want newest ID 1001", last["dm"])
+	}
+}
+
+func TestPendingDeletions(t *testing.T) {
+	d := openTestDB(t)
+
+	if err := d.ScheduleDeletions("ch", []string{"1", "2"}, time.Now().Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.ScheduleDeletions("ch", []string{"3"}, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	due, err := d.DueDeletions(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(due) != 2 || due[0].MessageID != "1" || due[1].MessageID != "2" {
+		t.Fatalf("due = %+v, want messages 1 and 2", due)
+	}
+	if err := d.DeletionDone(due[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if due, _ = d.DueDeletions(10); len(due) != 1 || due[0].MessageID != "2" {
+		t.Errorf("after done, due = %+v, want message 2", due)
+	}
+}
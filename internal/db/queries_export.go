@@ -0,0 +1,187 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/chris/jot/internal/llm"
+)
+
+// Export is a complete snapshot of everything jot stores, used by takeout.
+type Export struct {
+	ExportedAt            string                `json:"exported_at"`
+	Things                []Thing               `json:"things"`
+	ThingEvents           []ThingEvent          `json:"thing_events"`
+	Memories              []Memory              `json:"memories"`
+	ProposedMemories      []Memory              `json:"proposed_memories"`
+	Schedules             []Schedule            `json:"schedules"`
+	ScheduleRuns          []ScheduleRun         `json:"schedule_runs"`
+	Jobs                  []Job                 `json:"jobs"`
+	Deliveries            []Delivery            `json:"deliveries"`
+	Watches               []Watch               `json:"watches"`
+	WatchResults          []WatchResult         `json:"watch_results"`
+	Areas                 []Area                `json:"areas"`
+	SavedFilters          []SavedFilter         `json:"saved_filters"`
+	ToolDenials           []ToolDenial          `json:"tool_denials"`
+	GuildSettings         []GuildSettings       `json:"guild_settings"`
+	Notes                 map[string]string     `json:"notes"`
+	Conversations         []Conversation        `json:"conversations"`
+	ConversationSummaries []ConversationSummary `json:"conversation_summaries"`
+	Transcript            []TranscriptMessage   `json:"transcript"`
+	AuditLog              []AuditEntry          `json:"audit_log"`
+}
+
+// Conversation is a user's stored live conversation history.
+type Conversation struct {
+	UserID        string        `json:"user_id"`
+	Messages      []llm.Message `json:"messages"`
+	LastMessageAt string        `json:"last_message_at"`
+}
+
+// Export reads every table into memory. Unlike the regular list queries it
+// includes expired memories that haven't been pruned yet and all watch results.
+func (d *DB) Export() (*Export, error) {
+	e := &Export{ExportedAt: time.Now().UTC().Format(time.DateTime)}
+	var err error
+
+	if e.Things, err = d.scanThings(`SELECT id, title, COALESCE(notes,''), status, priority,
+		COALESCE(tags,'[]'), COALESCE(due_date,''), created_at, updated_at,
+		COALESCE(completed_at,'') FROM things ORDER BY id`); err != nil {
+		return nil, fmt.Errorf("exporting things: %w", err)
+	}
+	if e.ThingEvents, err = d.scanThingEvents(`SELECT e.id, e.thing_id, t.title, e.kind, e.detail, e.created_at
+		FROM thing_events e JOIN things t ON t.id = e.thing_id ORDER BY e.id`); err != nil {
+		return nil, fmt.Errorf("exporting thing events: %w", err)
+	}
+	if e.Memories, err = d.scanMemories(`SELECT id, content, category, COALESCE(tags,'[]'), thing_id, source, COALESCE(expires_at,''), created_at
+		FROM memories WHERE status = 'active' ORDER BY id`); err != nil {
+		return nil, fmt.Errorf("exporting memories: %w", err)
+	}
+	if e.ProposedMemories, err = d.ListProposedMemories(); err != nil {
+		return nil, fmt.Errorf("exporting proposed memories: %w", err)
+	}
+	if e.Schedules, err = d.ListSchedules(false); err != nil {
+		return nil, fmt.Errorf("exporting schedules: %w", err)
+	}
+	if e.ScheduleRuns, err = d.ListScheduleRuns("", nil); err != nil {
+		return nil, fmt.Errorf("exporting schedule runs: %w", err)
+	}
+	if e.Jobs, err = d.scanJobs(`SELECT ` + jobColumns + ` FROM jobs ORDER BY id`); err != nil {
+		return nil, fmt.Errorf("exporting jobs: %w", err)
+	}
+	if e.Deliveries, err = d.scanDeliveries(`SELECT ` + deliveryColumns + ` FROM deliveries ORDER BY id`); err != nil {
+		return nil, fmt.Errorf("exporting deliveries: %w", err)
+	}
+	if e.Watches, err = d.ListWatches(false); err != nil {
+		return nil, fmt.Errorf("exporting watches: %w", err)
+	}
+	if e.WatchResults, err = d.exportWatchResults(); err != nil {
+		return nil, err
+	}
+	if e.Areas, err = d.ListAreas(); err != nil {
+		return nil, err
+	}
+	if e.SavedFilters, err = d.ListFilters(); err != nil {
+		return nil, err
+	}
+	if e.ToolDenials, err = d.ListToolDenials(); err != nil {
+		return nil, err
+	}
+	if e.GuildSettings, err = d.ListGuildSettings(); err != nil {
+		return nil, err
+	}
+	if e.Notes, err = d.exportNotes(); err != nil {
+		return nil, err
+	}
+	if e.Conversations, err = d.exportConversations(); err != nil {
+		return nil, err
+	}
+	if e.ConversationSummaries, err = d.exportSummaries(); err != nil {
+		return nil, err
+	}
+	if e.Transcript, err = d.scanTranscript(`SELECT id, user_id, role, content, created_at
+		FROM conversation_messages ORDER BY id`); err != nil {
+		return nil, fmt.Errorf("exporting transcript: %w", err)
+	}
+	if e.AuditLog, err = d.ListAudit(time.Time{}); err != nil {
+		return nil, fmt.Errorf("exporting audit log: %w", err)
+	}
+	return e, nil
+}
+
+func (d *DB) exportWatchResults() ([]WatchResult, error) {
+	rows, err := d.conn.Query(`SELECT id, watch_id, content_hash, title, COALESCE(body,''), COALESCE(source_url,''), first_seen, notified
+		FROM watch_results ORDER BY watch_id, id`)
+	if err != nil {
+		return nil, fmt.Errorf("exporting watch results: %w", err)
+	}
+	defer rows.Close()
+	var out []WatchResult
+	for rows.Next() {
+		var r WatchResult
+		var notified int
+		if err := rows.Scan(&r.ID, &r.WatchID, &r.ContentHash, &r.Title, &r.Body, &r.SourceURL, &r.FirstSeen, &notified); err != nil {
+			return nil, fmt.Errorf("scanning watch result: %w", err)
+		}
+		r.Notified = notified == 1
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+func (d *DB) exportNotes() (map[string]string, error) {
+	rows, err := d.conn.Query(`SELECT key, value FROM notes ORDER BY key`)
+	if err != nil {
+		return nil, fmt.Errorf("exporting notes: %w", err)
+	}
+	defer rows.Close()
+	notes := make(map[string]string)
+	for rows.Next() {
+		var k, v string
+		if err := rows.Scan(&k, &v); err != nil {
+			return nil, fmt.Errorf("scanning note: %w", err)
+		}
+		notes[k] = v
+	}
+	return notes, rows.Err()
+}
+
+func (d *DB) exportConversations() ([]Conversation, error) {
+	rows, err := d.conn.Query(`SELECT user_id, messages, COALESCE(last_message_at,'') FROM conversations ORDER BY user_id`)
+	if err != nil {
+		return nil, fmt.Errorf("exporting conversations: %w", err)
+	}
+	defer rows.Close()
+	var out []Conversation
+	for rows.Next() {
+		var c Conversation
+		var raw string
+		if err := rows.Scan(&c.UserID, &raw, &c.LastMessageAt); err != nil {
+			return nil, fmt.Errorf("scanning conversation: %w", err)
+		}
+		if err := json.Unmarshal([]byte(raw), &c.Messages); err != nil {
+			return nil, fmt.Errorf("unmarshaling conversation %s: %w", c.UserID, err)
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+func (d *DB) exportSummaries() ([]ConversationSummary, error) {
+	rows, err := d.conn.Query(`SELECT id, user_id, summary, COALESCE(message_count, 0), created_at
+		FROM conversation_summaries ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("exporting summaries: %w", err)
+	}
+	defer rows.Close()
+	var out []ConversationSummary
+	for rows.Next() {
+		var s ConversationSummary
+		if err := rows.Scan(&s.ID, &s.UserID, &s.Summary, &s.MessageCount, &s.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning summary: %w", err)
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
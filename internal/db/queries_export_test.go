@@ -0,0 +1,52 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/chris/jot/internal/llm"
+)
+
+func TestExport(t *testing.T) {
+	d := openTestDB(t)
+
+	d.CreateThing("Renew passport", "", "high", "", nil)
+	d.recordThingEvent(1, "Renew passport", "stale", "untouched for 14 days")
+	d.SaveMemory("Passport expires in June", "event", "agent", nil, nil, "")
+	d.ProposeMemory("Prefers window seats", "preference", "agent", nil, nil, "")
+	d.SetNote("timezone", "America/Chicago")
+	d.SaveConversation("cli", []llm.Message{{Role: "user", Content: "hi"}})
+	d.SaveConversationSummary("cli", "Talked about travel.", 2)
+	jobID, _ := d.EnqueueJob("Visa research", "Check visa rules for Japan")
+	d.FinishJob(jobID, "No visa needed under 90 days.", "")
+	d.EnqueueDelivery("reminder[1]", "Passport photos at 3pm")
+	d.LogAudit(AuditEntry{Tool: "create_thing", Action: "create", Target: "thing #1", Detail: "Renew passport"})
+
+	e, err := d.Export()
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if len(e.Things) != 1 || len(e.Memories) != 1 || len(e.ProposedMemories) != 1 {
+		t.Errorf("unexpected counts: things=%d memories=%d proposed=%d", len(e.Things), len(e.Memories), len(e.ProposedMemories))
+	}
+	if len(e.ThingEvents) != 1 || e.ThingEvents[0].Kind != "stale" || e.ThingEvents[0].Title != "Renew passport" {
+		t.Errorf("unexpected thing events: %+v", e.ThingEvents)
+	}
+	if e.Notes["timezone"] != "America/Chicago" {
+		t.Errorf("expected timezone note, got %v", e.Notes)
+	}
+	if len(e.Conversations) != 1 || len(e.Conversations[0].Messages) != 1 {
+		t.Errorf("unexpected conversations: %+v", e.Conversations)
+	}
+	if len(e.ConversationSummaries) != 1 {
+		t.Errorf("expected 1 summary, got %d", len(e.ConversationSummaries))
+	}
+	if len(e.Jobs) != 1 || e.Jobs[0].Result != "No visa needed under 90 days." {
+		t.Errorf("unexpected jobs: %+v", e.Jobs)
+	}
+	if len(e.Deliveries) != 1 || e.Deliveries[0].Content != "Passport photos at 3pm" {
+		t.Errorf("unexpected deliveries: %+v", e.Deliveries)
+	}
+	if len(e.AuditLog) != 1 || e.AuditLog[0].Detail != "Renew passport" {
+		t.Errorf("unexpected audit log: %+v", e.AuditLog)
+	}
+}
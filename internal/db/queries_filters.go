@@ -0,0 +1,104 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// filterSpec is the stored part of a SavedFilter.
+type filterSpec struct {
+	Status        string   `json:"status,omitempty"`
+	Priority      string   `json:"priority,omitempty"`
+	Tags          []string `json:"tags,omitempty"`
+	DueWithinDays int      `json:"due_within_days,omitempty"`
+	Sort          string   `json:"sort,omitempty"`
+	Limit         int      `json:"limit,omitempty"`
+	CheckIn       bool     `json:"check_in,omitempty"`
+}
+
+// SaveFilter creates or replaces the filter with f.Name and returns its ID.
+func (d *DB) SaveFilter(f SavedFilter) (int64, error) {
+	f.Name = strings.ToLower(strings.TrimSpace(f.Name))
+	if f.Name == "" {
+		return 0, fmt.Errorf("filter name is required")
+	}
+	if _, ok := thingSorts[f.Sort]; f.Sort != "" && !ok {
+		return 0, fmt.Errorf("unknown sort %q (use priority, due_date, newest, or oldest)", f.Sort)
+	}
+	spec, _ := json.Marshal(filterSpec{
+		Status: f.Status, Priority: f.Priority, Tags: f.Tags, DueWithinDays: f.DueWithinDays,
+		Sort: f.Sort, Limit: f.Limit, CheckIn: f.CheckIn,
+	})
+	if _, err := d.conn.Exec(`INSERT INTO saved_filters (name, spec) VALUES (?, ?)
+		ON CONFLICT(name) DO UPDATE SET spec = excluded.spec, updated_at = datetime('now')`, f.Name, string(spec)); err != nil {
+		return 0, fmt.Errorf("saving filter %s: %w", f.Name, err)
+	}
+	var id int64
+	if err := d.conn.QueryRow(`SELECT id FROM saved_filters WHERE name = ?`, f.Name).Scan(&id); err != nil {
+		return 0, fmt.Errorf("looking up filter %s: %w", f.Name, err)
+	}
+	return id, nil
+}
+
+// GetFilter returns a saved filter by name, or nil if there is none.
+func (d *DB) GetFilter(name string) (*SavedFilter, error) {
+	filters, err := d.scanFilters(`SELECT id, name, spec, created_at, updated_at FROM saved_filters WHERE name = ?`,
+		strings.ToLower(strings.TrimSpace(name)))
+	if err != nil || len(filters) == 0 {
+		return nil, err
+	}
+	return &filters[0], nil
+}
+
+// ListFilters returns every saved filter by name.
+func (d *DB) ListFilters() ([]SavedFilter, error) {
+	return d.scanFilters(`SELECT id, name, spec, created_at, updated_at FROM saved_filters ORDER BY name`)
+}
+
+// DeleteFilter removes a saved filter, reporting whether it existed.
+func (d *DB) DeleteFilter(name string) (bool, error) {
+	res, err := d.conn.Exec(`DELETE FROM saved_filters WHERE name = ?`, strings.ToLower(strings.TrimSpace(name)))
+	if err != nil {
+		return false, fmt.Errorf("deleting filter %s: %w", name, err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// RunFilter returns the things matching f as of today (YYYY-MM-DD, the
+// user's local date) and the total before f.Limit is applied.
+func (d *DB) RunFilter(f SavedFilter, today string) ([]Thing, int, error) {
+	q := ThingQuery{Status: f.Status, Priority: f.Priority, AnyTags: f.Tags, Sort: f.Sort, Limit: f.Limit}
+	if f.DueWithinDays > 0 {
+		t, err := time.Parse(time.DateOnly, today)
+		if err != nil {
+			return nil, 0, fmt.Errorf("parsing today %q: %w", today, err)
+		}
+		q.DueBy = t.AddDate(0, 0, f.DueWithinDays).Format(time.DateOnly)
+	}
+	return d.QueryThings(q)
+}
+
+func (d *DB) scanFilters(query string, args ...any) ([]SavedFilter, error) {
+	rows, err := d.conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying filters: %w", err)
+	}
+	defer rows.Close()
+	var filters []SavedFilter
+	for rows.Next() {
+		var f SavedFilter
+		var spec string
+		if err := rows.Scan(&f.ID, &f.Name, &spec, &f.CreatedAt, &f.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scanning filter: %w", err)
+		}
+		var s filterSpec
+		_ = json.Unmarshal([]byte(spec), &s)
+		f.Status, f.Priority, f.Tags, f.DueWithinDays = s.Status, s.Priority, s.Tags, s.DueWithinDays
+		f.Sort, f.Limit, f.CheckIn = s.Sort, s.Limit, s.CheckIn
+		filters = append(filters, f)
+	}
+	return filters, rows.Err()
+}
@@ -0,0 +1,52 @@
+package db
+
+import "testing"
+
+func TestSaveAndRunFilter(t *testing.T) {
+	d := openTestDB(t)
+
+	d.CreateThing("Buy stamps", "", "normal", "2026-03-11", []string{"errands"})
+	d.CreateThing("Return library books", "", "normal", "2026-03-30", []string{"errands"})
+	d.CreateThing("Pick up dry cleaning", "", "normal", "2026-03-05", []string{"shopping"})
+	d.CreateThing("Write report", "", "normal", "2026-03-11", []string{"work"})
+
+	id, err := d.SaveFilter(SavedFilter{Name: "Errands", Tags: []string{"errands", "shopping"}, DueWithinDays: 7, Sort: "due_date", CheckIn: true})
+	if err != nil {
+		t.Fatalf("SaveFilter: %v", err)
+	}
+
+	f, err := d.GetFilter("errands")
+	if err != nil || f == nil {
+		t.Fatalf("GetFilter: %v, %v", f, err)
+	}
+	if f.ID != id || len(f.Tags) != 2 || f.DueWithinDays != 7 || !f.CheckIn {
+		t.Errorf("round-tripped filter = %+v", f)
+	}
+
+	things, total, err := d.RunFilter(*f, "2026-03-10")
+	if err != nil {
+		t.Fatalf("RunFilter: %v", err)
+	}
+	if total != 2 || things[0].Title != "Pick up dry cleaning" || things[1].Title != "Buy stamps" {
+		t.Errorf("RunFilter = %d things (total %d): %+v", len(things), total, things)
+	}
+
+	// Saving the same name replaces the spec.
+	if again, _ := d.SaveFilter(SavedFilter{Name: "errands", Tags: []string{"errands"}}); again != id {
+		t.Errorf("expected same ID on replace, got %d want %d", again, id)
+	}
+	f, _ = d.GetFilter("errands")
+	if things, _, _ := d.RunFilter(*f, "2026-03-10"); len(things) != 2 || f.CheckIn {
+		t.Errorf("replaced filter = %+v, ran %d things", f, len(things))
+	}
+
+	if _, err := d.SaveFilter(SavedFilter{Name: "bad", Sort: "random"}); err == nil {
+		t.Error("expected error for unknown sort")
+	}
+	if found, _ := d.DeleteFilter("errands"); !found {
+		t.Error("expected filter to be deleted")
+	}
+	if f, _ := d.GetFilter("errands"); f != nil {
+		t.Errorf("expected nil after delete, got %+v", f)
+	}
+}
@@ -0,0 +1,86 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// GuildSettings restricts where and for whom jot answers in a Discord server,
+// and which tool profile its turns there run under.
+type GuildSettings struct {
+	GuildID      string   `json:"guild_id"`
+	Channels     []string `json:"channels"`      // channel IDs; empty means every channel
+	RequiredRole string   `json:"required_role"` // role ID; empty means anyone
+	Profile      string   `json:"profile"`
+	ReplyDM      bool     `json:"reply_dm"`     // answer mentions by DM
+	DeleteAfter  int      `json:"delete_after"` // minutes before channel replies are deleted; 0 keeps them
+	UpdatedAt    string   `json:"updated_at"`
+}
+
+// DefaultGuildProfile is the tool profile for guilds without settings.
+const DefaultGuildProfile = "discord"
+
+// GetGuildSettings returns a guild's settings, or the defaults (every
+// channel, anyone, the discord profile) if none are saved.
+func (d *DB) GetGuildSettings(guildID string) (*GuildSettings, error) {
+	guilds, err := d.scanGuildSettings(`SELECT guild_id, channels, required_role, profile, reply_dm, delete_after, updated_at
+		FROM guild_settings WHERE guild_id = ?`, guildID)
+	if err != nil {
+		return nil, err
+	}
+	if len(guilds) == 0 {
+		return &GuildSettings{GuildID: guildID, Channels: []string{}, Profile: DefaultGuildProfile}, nil
+	}
+	return &guilds[0], nil
+}
+
+// ListGuildSettings returns every guild with saved settings.
+func (d *DB) ListGuildSettings() ([]GuildSettings, error) {
+	return d.scanGuildSettings(`SELECT guild_id, channels, required_role, profile, reply_dm, delete_after, updated_at
+		FROM guild_settings ORDER BY guild_id`)
+}
+
+// SaveGuildSettings inserts or replaces a guild's settings.
+func (d *DB) SaveGuildSettings(g *GuildSettings) error {
+	if g.Profile == "" {
+		g.Profile = DefaultGuildProfile
+	}
+	if g.Channels == nil {
+		g.Channels = []string{}
+	}
+	channels, err := json.Marshal(g.Channels)
+	if err != nil {
+		return fmt.Errorf("encoding guild channels: %w", err)
+	}
+	_, err = d.conn.Exec(`INSERT INTO guild_settings (guild_id, channels, required_role, profile, reply_dm, delete_after)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(guild_id) DO UPDATE SET channels = excluded.channels, required_role = excluded.required_role,
+			profile = excluded.profile, reply_dm = excluded.reply_dm, delete_after = excluded.delete_after,
+			updated_at = datetime('now')`,
+		g.GuildID, string(channels), g.RequiredRole, g.Profile, g.ReplyDM, max(g.DeleteAfter, 0))
+	if err != nil {
+		return fmt.Errorf("saving guild settings: %w", err)
+	}
+	return nil
+}
+
+func (d *DB) scanGuildSettings(query string, args ...any) ([]GuildSettings, error) {
+	rows, err := d.conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying guild settings: %w", err)
+	}
+	defer rows.Close()
+	var guilds []GuildSettings
+	for rows.Next() {
+		var g GuildSettings
+		var channels string
+		if err := rows.Scan(&g.GuildID, &channels, &g.RequiredRole, &g.Profile, &g.ReplyDM, &g.DeleteAfter, &g.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scanning guild settings: %w", err)
+		}
+		if err := json.Unmarshal([]byte(channels), &g.Channels); err != nil {
+			return nil, fmt.Errorf("decoding guild channels: %w", err)
+		}
+		guilds = append(guilds, g)
+	}
+	return guilds, rows.Err()
+}
@@ -0,0 +1,40 @@
+package db
+
+import "testing"
+
+func TestGuildSettings(t *testing.T) {
+	d := openTestDB(t)
+
+	g, err := d.GetGuildSettings("g1")
+	if err != nil {
+		t.Fatalf("GetGuildSettings: %v", err)
+	}
+	if len(g.Channels) != 0 || g.RequiredRole != "" || g.Profile != DefaultGuildProfile {
+		t.Errorf("expected defaults for unsaved guild, got %+v", g)
+	}
+
+	g.Channels = []string{"c1", "c2"}
+	g.RequiredRole = "r1"
+	g.Profile = "guild"
+	g.ReplyDM = true
+	g.DeleteAfter = 10
+	if err := d.SaveGuildSettings(g); err != nil {
+		t.Fatalf("SaveGuildSettings: %v", err)
+	}
+	g.Channels = g.Channels[:1]
+	if err := d.SaveGuildSettings(g); err != nil {
+		t.Fatalf("SaveGuildSettings again: %v", err)
+	}
+
+	got, err := d.GetGuildSettings("g1")
+	if err != nil {
+		t.Fatalf("GetGuildSettings: %v", err)
+	}
+	if len(got.Channels) != 1 || got.Channels[0] != "c1" || got.RequiredRole != "r1" || got.Profile != "guild" ||
+		!got.ReplyDM || got.DeleteAfter != 10 {
+		t.Errorf("unexpected settings after save: %+v", got)
+	}
+	if all, _ := d.ListGuildSettings(); len(all) != 1 {
+		t.Errorf("expected one guild, got %+v", all)
+	}
+}
@@ -1,17 +1,29 @@
 package db
 
+//go:generate go run ./gen
+
 import (
+	"database/sql"
+	"encoding/json"
 	"fmt"
 	"strings"
+	"unicode/utf8"
 )
 
-var allowedColumns = map[string]map[string]bool{
-	"things":   {"title": true, "notes": true, "status": true, "priority": true, "due_date": true, "tags": true, "completed_at": true},
-	"memories": {"content": true, "category": true, "tags": true, "expires_at": true},
-	"watches":  {"prompt": true, "urls": true, "cron_expr": true, "enabled": true},
+// execer is satisfied by *sql.DB and *sql.Tx, so inserts can run alone or
+// inside a transaction.
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// queryExecer adds Query to execer, for inserts that read first.
+type queryExecer interface {
+	execer
+	Query(query string, args ...any) (*sql.Rows, error)
 }
 
-// updateRow is a generic helper for updating a row's fields.
+// updateRow is a generic helper for updating a row's fields. Values for
+// @enum columns are checked with CheckValue.
 func (d *DB) updateRow(table string, id int64, fields map[string]any) error {
 	if len(fields) == 0 {
 		return nil
@@ -26,6 +38,11 @@ func (d *DB) updateRow(table string, id int64, fields map[string]any) error {
 		if !allowed[col] {
 			return fmt.Errorf("disallowed column %q for table %s", col, table)
 		}
+		if s, ok := val.(string); ok {
+			if err := CheckValue(table, col, s); err != nil {
+				return err
+			}
+		}
 		setClauses = append(setClauses, col+" = ?")
 		args = append(args, val)
 	}
@@ -49,3 +66,30 @@ func nullStr(s string) any {
 	}
 	return s
 }
+
+// decodeTags parses a tags column. Rows without tags skip the JSON decoder,
+// and the usual escape-free `["a","b"]` is split directly; scans of tens of
+// thousands of rows spend most of their time here otherwise.
+func decodeTags(s string) []string {
+	if s == "" || s == "[]" {
+		return nil
+	}
+	if len(s) >= 4 && strings.HasPrefix(s, `["`) && strings.HasSuffix(s, `"]`) && !strings.ContainsRune(s, '\\') {
+		return strings.Split(s[2:len(s)-2], `","`)
+	}
+	var tags []string
+	_ = json.Unmarshal([]byte(s), &tags)
+	return tags
+}
+
+// Truncate cuts s to at most n bytes plus "...", backing up to a rune
+// boundary so a multi-byte character is never split.
+func Truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "..."
+}
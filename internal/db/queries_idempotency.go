@@ -0,0 +1,49 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// GetToolResult returns the stored result for an idempotency key if it was
+// recorded within the last windowSeconds. The bool is false when no live entry exists.
+func (d *DB) GetToolResult(key string, windowSeconds int) (string, bool, error) {
+	var result string
+	err := d.conn.QueryRow(
+		"SELECT result FROM tool_idempotency WHERE key = ? AND created_at > datetime('now', ?)",
+		key, fmt.Sprintf("-%d seconds", windowSeconds),
+	).Scan(&result)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("getting tool result: %w", err)
+	}
+	return result, true, nil
+}
+
+// SaveToolResult records the result of a write tool under its idempotency key,
+// replacing any expired entry with the same key.
+func (d *DB) SaveToolResult(key, tool, result string) error {
+	_, err := d.conn.Exec(
+		`INSERT INTO tool_idempotency (key, tool, result) VALUES (?, ?, ?)
+		 ON CONFLICT(key) DO UPDATE SET tool = excluded.tool, result = excluded.result, created_at = datetime('now')`,
+		key, tool, result,
+	)
+	if err != nil {
+		return fmt.Errorf("saving tool result: %w", err)
+	}
+	return nil
+}
+
+// PruneToolResults deletes idempotency entries older than the given number of days.
+func (d *DB) PruneToolResults(olderThanDays int) (int64, error) {
+	res, err := d.conn.Exec(
+		"DELETE FROM tool_idempotency WHERE created_at < datetime('now', ?)",
+		fmt.Sprintf("-%d days", olderThanDays),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("pruning tool results: %w", err)
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,70 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSaveAndGetToolResult(t *testing.T) {
+	d := openTestDB(t)
+
+	if _, ok, err := d.GetToolResult("k1", 600); err != nil || ok {
+		t.Fatalf("expected no result before save, got ok=%v err=%v", ok, err)
+	}
+
+	if err := d.SaveToolResult("k1", "create_thing", `{"id":1,"status":"created"}`); err != nil {
+		t.Fatalf("SaveToolResult: %v", err)
+	}
+
+	got, ok, err := d.GetToolResult("k1", 600)
+	if err != nil {
+		t.Fatalf("GetToolResult: %v", err)
+	}
+	if !ok {
+		t.Fatal("expected stored result")
+	}
+	if got != `{"id":1,"status":"created"}` {
+		t.Errorf("unexpected result: %s", got)
+	}
+}
+
+func TestGetToolResultOutsideWindow(t *testing.T) {
+	d := openTestDB(t)
+
+	old := time.Now().UTC().Add(-time.Hour).Format(time.DateTime)
+	d.conn.Exec(`INSERT INTO tool_idempotency (key, tool, result, created_at) VALUES (?, ?, ?, ?)`,
+		"stale", "create_thing", `{"id":1}`, old)
+
+	if _, ok, _ := d.GetToolResult("stale", 600); ok {
+		t.Error("expected entry outside window to be ignored")
+	}
+
+	// Re-saving an expired key replaces it and makes it live again.
+	if err := d.SaveToolResult("stale", "create_thing", `{"id":2}`); err != nil {
+		t.Fatalf("SaveToolResult: %v", err)
+	}
+	got, ok, _ := d.GetToolResult("stale", 600)
+	if !ok || got != `{"id":2}` {
+		t.Errorf("expected refreshed entry, got (%q, %v)", got, ok)
+	}
+}
+
+func TestPruneToolResults(t *testing.T) {
+	d := openTestDB(t)
+
+	old := time.Now().UTC().AddDate(0, 0, -3).Format(time.DateTime)
+	d.conn.Exec(`INSERT INTO tool_idempotency (key, tool, result, created_at) VALUES (?, ?, ?, ?)`,
+		"old", "save_memory", `{}`, old)
+	d.SaveToolResult("new", "save_memory", `{}`)
+
+	n, err := d.PruneToolResults(1)
+	if err != nil {
+		t.Fatalf("PruneToolResults: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("expected 1 pruned, got %d", n)
+	}
+	if _, ok, _ := d.GetToolResult("new", 600); !ok {
+		t.Error("recent entry should survive prune")
+	}
+}
@@ -0,0 +1,58 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Importer writes an import inside the transaction opened by DB.Import.
+type Importer struct {
+	tx *sql.Tx
+}
+
+// Import runs fn in one transaction and commits only if it returns nil, so a
+// failed import leaves nothing behind and can simply be retried.
+func (d *DB) Import(fn func(*Importer) error) error {
+	tx, err := d.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning import: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(&Importer{tx: tx}); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing import: %w", err)
+	}
+	d.thingsChanged()
+	return nil
+}
+
+// CreateThings is DB.CreateThings inside the import.
+func (im *Importer) CreateThings(things []NewThing) ([]int64, error) {
+	return insertThings(im.tx, things)
+}
+
+// SaveMemories is DB.BulkSaveMemories inside the import.
+func (im *Importer) SaveMemories(mems []NewMemory) ([]SavedMemory, error) {
+	return insertMemories(im.tx, mems)
+}
+
+// LogHabits is DB.BulkLogHabits inside the import.
+func (im *Importer) LogHabits(logs []HabitLog) ([]int64, error) {
+	mems, err := habitMemories(logs)
+	if err != nil {
+		return nil, err
+	}
+	saved, err := insertMemories(im.tx, mems)
+	if err != nil {
+		return nil, err
+	}
+	return savedIDs(saved), nil
+}
+
+// SetNote is DB.SetNote inside the import.
+func (im *Importer) SetNote(key, value string) error {
+	return setNote(im.tx, key, value)
+}
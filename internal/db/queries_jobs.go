@@ -0,0 +1,118 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+const jobColumns = `id, title, prompt, status, result, error, created_at, COALESCE(started_at,''), COALESCE(finished_at,'')`
+
+// EnqueueJob queues a background job and returns its ID.
+func (d *DB) EnqueueJob(title, prompt string) (int64, error) {
+	title, prompt = strings.TrimSpace(title), strings.TrimSpace(prompt)
+	if prompt == "" {
+		return 0, fmt.Errorf("job prompt is required")
+	}
+	if title == "" {
+		title = truncateTitle(prompt)
+	}
+	res, err := d.conn.Exec(`INSERT INTO jobs (title, prompt) VALUES (?, ?)`, title, prompt)
+	if err != nil {
+		return 0, fmt.Errorf("enqueuing job: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// ClaimJob marks the oldest queued job running and returns it, or nil if
+// the queue is empty. The claim is a single UPDATE, so two workers never
+// get the same job.
+func (d *DB) ClaimJob() (*Job, error) {
+	jobs, err := d.scanJobs(`UPDATE jobs SET status = 'running', started_at = datetime('now')
+		WHERE id = (SELECT id FROM jobs WHERE status = 'queued' ORDER BY id LIMIT 1)
+		RETURNING ` + jobColumns)
+	if err != nil {
+		return nil, fmt.Errorf("claiming job: %w", err)
+	}
+	if len(jobs) == 0 {
+		return nil, nil
+	}
+	return &jobs[0], nil
+}
+
+// FinishJob records a job's result, or its failure if errMsg is non-empty.
+func (d *DB) FinishJob(id int64, result, errMsg string) error {
+	status := "done"
+	if errMsg != "" {
+		status = "failed"
+	}
+	if _, err := d.conn.Exec(`UPDATE jobs SET status = ?, result = ?, error = ?, finished_at = datetime('now') WHERE id = ?`,
+		status, result, errMsg, id); err != nil {
+		return fmt.Errorf("finishing job %d: %w", id, err)
+	}
+	return nil
+}
+
+// RequeueRunningJobs puts jobs left running by a restart back in the queue.
+func (d *DB) RequeueRunningJobs() (int64, error) {
+	res, err := d.conn.Exec(`UPDATE jobs SET status = 'queued', started_at = NULL WHERE status = 'running'`)
+	if err != nil {
+		return 0, fmt.Errorf("requeuing jobs: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// GetJob returns a job by ID, or nil if there is none.
+func (d *DB) GetJob(id int64) (*Job, error) {
+	jobs, err := d.scanJobs(`SELECT `+jobColumns+` FROM jobs WHERE id = ?`, id)
+	if err != nil || len(jobs) == 0 {
+		return nil, err
+	}
+	return &jobs[0], nil
+}
+
+// ListJobs returns the newest jobs first, optionally filtered by status.
+func (d *DB) ListJobs(status string, limit int) ([]Job, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	q := `SELECT ` + jobColumns + ` FROM jobs`
+	var args []any
+	if status != "" {
+		q += ` WHERE status = ?`
+		args = append(args, status)
+	}
+	q += ` ORDER BY id DESC LIMIT ?`
+	return d.scanJobs(q, append(args, limit)...)
+}
+
+// PruneJobs deletes finished jobs older than the given number of days.
+func (d *DB) PruneJobs(olderThanDays int) (int64, error) {
+	res, err := d.conn.Exec(`DELETE FROM jobs WHERE status IN ('done', 'failed') AND finished_at < datetime('now', ?)`,
+		fmt.Sprintf("-%d days", olderThanDays))
+	if err != nil {
+		return 0, fmt.Errorf("pruning jobs: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+func (d *DB) scanJobs(query string, args ...any) ([]Job, error) {
+	rows, err := d.conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying jobs: %w", err)
+	}
+	defer rows.Close()
+	var jobs []Job
+	for rows.Next() {
+		var j Job
+		if err := rows.Scan(&j.ID, &j.Title, &j.Prompt, &j.Status, &j.Result, &j.Error, &j.CreatedAt, &j.StartedAt, &j.FinishedAt); err != nil {
+			return nil, fmt.Errorf("scanning job: %w", err)
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, rows.Err()
+}
+
+// truncateTitle derives a short job title from its prompt.
+func truncateTitle(prompt string) string {
+	return Truncate(strings.Join(strings.Fields(prompt), " "), 60)
+}
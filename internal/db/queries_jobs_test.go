@@ -0,0 +1,95 @@
+package db
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestJobLifecycle(t *testing.T) {
+	d := openTestDB(t)
+
+	if _, err := d.EnqueueJob("", "  "); err == nil {
+		t.Error("expected error for empty prompt")
+	}
+	first, err := d.EnqueueJob("", "Research standing desks under $500 and summarize the top three")
+	if err != nil {
+		t.Fatalf("EnqueueJob: %v", err)
+	}
+	second, _ := d.EnqueueJob("Trip plan", "Plan a weekend in Portland")
+
+	job, err := d.ClaimJob()
+	if err != nil || job == nil {
+		t.Fatalf("ClaimJob: %v, %v", job, err)
+	}
+	if job.ID != first || job.Status != "running" || job.StartedAt == "" {
+		t.Errorf("expected oldest job claimed and running, got %+v", job)
+	}
+	if job.Title != "Research standing desks under $500 and summarize the top thr..." {
+		t.Errorf("expected title derived from prompt, got %q", job.Title)
+	}
+
+	// A restart requeues the running job ahead of the second.
+	if n, _ := d.RequeueRunningJobs(); n != 1 {
+		t.Errorf("expected 1 requeued job, got %d", n)
+	}
+	job, _ = d.ClaimJob()
+	if job.ID != first {
+		t.Errorf("expected requeued job first, got %d", job.ID)
+	}
+	if err := d.FinishJob(job.ID, "1. Uplift ...", ""); err != nil {
+		t.Fatalf("FinishJob: %v", err)
+	}
+	job, _ = d.ClaimJob()
+	if job.ID != second {
+		t.Fatalf("expected second job, got %d", job.ID)
+	}
+	d.FinishJob(job.ID, "", "rate limited")
+	if job, _ := d.ClaimJob(); job != nil {
+		t.Errorf("expected empty queue, got %+v", job)
+	}
+
+	if got, _ := d.GetJob(first); got.Status != "done" || got.Result != "1. Uplift ..." || got.FinishedAt == "" {
+		t.Errorf("unexpected finished job: %+v", got)
+	}
+	failed, _ := d.ListJobs("failed", 0)
+	if len(failed) != 1 || failed[0].Error != "rate limited" {
+		t.Errorf("expected one failed job, got %+v", failed)
+	}
+
+	d.conn.Exec(`UPDATE jobs SET finished_at = datetime('now', '-40 days') WHERE id = ?`, first)
+	if n, _ := d.PruneJobs(30); n != 1 {
+		t.Errorf("expected 1 pruned job, got %d", n)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{"hello", 10, "hello"},
+		{"hello", 5, "hello"},
+		{"hello world", 5, "hello..."},
+		{"", 5, ""},
+		{"héllo", 2, "h..."},
+	}
+	for _, tt := range tests {
+		if got := Truncate(tt.s, tt.n); got != tt.want {
+			t.Errorf("Truncate(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestTruncateTitle(t *testing.T) {
+	// 59 ASCII bytes then a two-byte rune straddling the 60-byte cut.
+	prompt := strings.Repeat("a", 59) + "ésumé and more"
+	got := truncateTitle(prompt)
+	if !utf8.ValidString(got) || got != strings.Repeat("a", 59)+"..." {
+		t.Errorf("truncateTitle split a rune: %q", got)
+	}
+	if got := truncateTitle("  short\n prompt "); got != "short prompt" {
+		t.Errorf("truncateTitle = %q", got)
+	}
+}
@@ -1,30 +1,238 @@
 package db
 
 import (
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
+	"unicode"
 )
 
-// SaveMemory stores a new memory and returns its ID.
-func (d *DB) SaveMemory(content, category, source string, tags []string, thingID *int64, expiresAt string) (int64, error) {
+// memoryDedupWindow is how far back (in hours) SaveMemory looks for an
+// existing memory with the same or nearly the same content.
+const memoryDedupWindow = 24
+
+// memoryDedupThreshold is the minimum word-set Jaccard similarity for two
+// memories to count as near-duplicates.
+const memoryDedupThreshold = 0.8
+
+// SaveMemory stores a new memory and returns its ID. If an exact or
+// near-duplicate memory was saved within the dedup window, no row is inserted;
+// the existing ID is returned with duplicate set to true. Habit logs are
+// meant to repeat and are never deduplicated.
+func (d *DB) SaveMemory(content, category, source string, tags []string, thingID *int64, expiresAt string) (id int64, duplicate bool, err error) {
+	return insertMemory(d.conn, NewMemory{Content: content, Category: category, Source: source, Tags: tags, ThingID: thingID, ExpiresAt: expiresAt})
+}
+
+// ProposeMemory stores a memory in the "proposed" state. Proposed memories are
+// hidden from search and listings until the user approves them. Dedup works
+// the same as SaveMemory.
+func (d *DB) ProposeMemory(content, category, source string, tags []string, thingID *int64, expiresAt string) (id int64, duplicate bool, err error) {
+	return insertMemory(d.conn, NewMemory{Content: content, Category: category, Source: source, Status: "proposed", Tags: tags, ThingID: thingID, ExpiresAt: expiresAt})
+}
+
+// BulkSaveMemories stores several memories in one transaction, deduplicating
+// each like SaveMemory (against earlier entries of the batch too). If any
+// insert fails, none are saved.
+func (d *DB) BulkSaveMemories(mems []NewMemory) ([]SavedMemory, error) {
+	tx, err := d.conn.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("beginning bulk save: %w", err)
+	}
+	defer tx.Rollback()
+
+	saved, err := insertMemories(tx, mems)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("committing memories: %w", err)
+	}
+	return saved, nil
+}
+
+func insertMemories(x queryExecer, mems []NewMemory) ([]SavedMemory, error) {
+	saved := make([]SavedMemory, 0, len(mems))
+	for i, m := range mems {
+		id, dup, err := insertMemory(x, m)
+		if err != nil {
+			return nil, fmt.Errorf("memory %d: %w", i+1, err)
+		}
+		saved = append(saved, SavedMemory{ID: id, Duplicate: dup})
+	}
+	return saved, nil
+}
+
+// BulkLogHabits records habit occurrences in one transaction: each becomes a
+// habit memory tagged with its habit name, dated At. Returns the new IDs in
+// order.
+func (d *DB) BulkLogHabits(logs []HabitLog) ([]int64, error) {
+	mems, err := habitMemories(logs)
+	if err != nil {
+		return nil, err
+	}
+	saved, err := d.BulkSaveMemories(mems)
+	if err != nil {
+		return nil, err
+	}
+	return savedIDs(saved), nil
+}
+
+// habitMemories turns habit logs into the memories that record them.
+func habitMemories(logs []HabitLog) ([]NewMemory, error) {
+	mems := make([]NewMemory, len(logs))
+	for i, l := range logs {
+		habit := strings.ToLower(strings.TrimSpace(l.Habit))
+		if habit == "" {
+			return nil, fmt.Errorf("habit log %d has no habit", i+1)
+		}
+		m := NewMemory{Content: l.Note, Category: "habit", Source: l.Source, Tags: []string{habit}, CreatedAt: l.At}
+		if m.Content == "" {
+			m.Content = habit
+		}
+		if m.Source == "" {
+			m.Source = "import"
+		}
+		mems[i] = m
+	}
+	return mems, nil
+}
+
+func savedIDs(saved []SavedMemory) []int64 {
+	ids := make([]int64, len(saved))
+	for i, s := range saved {
+		ids[i] = s.ID
+	}
+	return ids
+}
+
+func insertMemory(x queryExecer, m NewMemory) (id int64, duplicate bool, err error) {
+	if err := CheckValue("memories", "category", m.Category); err != nil {
+		return 0, false, err
+	}
+	if m.Status == "" {
+		m.Status = "active"
+	}
+	if m.Category != "habit" {
+		existing, err := findDuplicateMemory(x, m)
+		if err != nil {
+			return 0, false, err
+		}
+		if existing != 0 {
+			return existing, true, nil
+		}
+	}
+
 	var tagsJSON string
-	if len(tags) > 0 {
-		b, _ := json.Marshal(tags)
+	if len(m.Tags) > 0 {
+		b, _ := json.Marshal(m.Tags)
 		tagsJSON = string(b)
 	}
-	res, err := d.conn.Exec(
-		"INSERT INTO memories (content, category, source, tags, thing_id, expires_at) VALUES (?, ?, ?, ?, ?, ?)",
-		content, category, source, nullStr(tagsJSON), thingID, nullStr(expiresAt),
+	res, err := x.Exec(
+		"INSERT INTO memories (content, category, source, status, tags, thing_id, expires_at, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, COALESCE(?, datetime('now')))",
+		m.Content, m.Category, m.Source, m.Status, nullStr(tagsJSON), m.ThingID, nullStr(m.ExpiresAt), nullStr(m.CreatedAt),
 	)
 	if err != nil {
-		return 0, fmt.Errorf("saving memory: %w", err)
+		return 0, false, fmt.Errorf("saving memory: %w", err)
+	}
+	id, err = res.LastInsertId()
+	return id, false, err
+}
+
+// findDuplicateMemory returns the ID of a recent, unexpired memory whose
+// content matches m's exactly (after normalization) or nearly (by word
+// overlap), or 0. Only memories with the same category, status, and thing
+// count, so a blocker isn't swallowed by a matching observation and a
+// proposal doesn't stand in for an active memory. Candidates come from the
+// FTS index so only memories sharing words are compared.
+func findDuplicateMemory(x queryExecer, m NewMemory) (int64, error) {
+	words := memoryWords(m.Content)
+	if len(words) == 0 {
+		return 0, nil
+	}
+	quoted := make([]string, len(words))
+	for i, w := range words {
+		quoted[i] = `"` + w + `"`
+	}
+	rows, err := x.Query(`SELECT m.id, m.content
+		FROM memories_fts f
+		JOIN memories m ON m.id = f.rowid
+		WHERE memories_fts MATCH ?
+		  AND m.category = ? AND m.status = ? AND m.thing_id IS ?
+		  AND m.created_at > datetime('now', ?)
+		  AND (m.expires_at IS NULL OR m.expires_at > datetime('now'))
+		ORDER BY rank LIMIT 20`,
+		strings.Join(quoted, " OR "), m.Category, m.Status, m.ThingID, fmt.Sprintf("-%d hours", memoryDedupWindow),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("finding duplicate memory: %w", err)
+	}
+	defer rows.Close()
+
+	hash := contentHash(words)
+	var best int64
+	bestScore := 0.0
+	for rows.Next() {
+		var id int64
+		var existing string
+		if err := rows.Scan(&id, &existing); err != nil {
+			return 0, fmt.Errorf("scanning duplicate candidate: %w", err)
+		}
+		other := memoryWords(existing)
+		if contentHash(other) == hash {
+			return id, nil
+		}
+		if score := jaccard(words, other); score >= memoryDedupThreshold && score > bestScore {
+			best, bestScore = id, score
+		}
 	}
-	return res.LastInsertId()
+	return best, rows.Err()
+}
+
+// memoryWords lowercases content and splits it into words, dropping punctuation.
+func memoryWords(content string) []string {
+	return strings.FieldsFunc(strings.ToLower(content), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// contentHash returns a SHA-256 hex digest of normalized words.
+func contentHash(words []string) string {
+	h := sha256.Sum256([]byte(strings.Join(words, " ")))
+	return fmt.Sprintf("%x", h)
+}
+
+// jaccard returns the Jaccard similarity of two word sets.
+func jaccard(a, b []string) float64 {
+	set := make(map[string]bool, len(a))
+	for _, w := range a {
+		set[w] = true
+	}
+	union := len(set)
+	inter := 0
+	seen := make(map[string]bool, len(b))
+	for _, w := range b {
+		if seen[w] {
+			continue
+		}
+		seen[w] = true
+		if set[w] {
+			inter++
+		} else {
+			union++
+		}
+	}
+	if union == 0 {
+		return 0
+	}
+	return float64(inter) / float64(union)
 }
 
 // SearchMemories searches memories by text query, category, tag, thing, and date.
 // When a text query is provided, it uses FTS5 for ranked full-text search.
-// Falls back to LIKE if FTS fails (defensive).
+// If that finds nothing, misspelled words are swapped for close indexed
+// terms and the search is retried. Falls back to LIKE if FTS fails (defensive).
 func (d *DB) SearchMemories(query, category, tag string, thingID *int64, since string, limit int) ([]Memory, error) {
 	if limit <= 0 {
 		limit = 10
@@ -33,13 +241,18 @@ func (d *DB) SearchMemories(query, category, tag string, thingID *int64, since s
 	// Use FTS5 when a text query is provided.
 	if query != "" {
 		results, err := d.searchMemoriesFTS(query, category, tag, thingID, since, limit)
+		if err == nil && len(results) == 0 {
+			if fuzzy, ok := d.fuzzyQuery("memories_vocab", query); ok {
+				return d.searchMemoriesFTS(fuzzy, category, tag, thingID, since, limit)
+			}
+		}
 		if err == nil {
 			return results, nil
 		}
 		// FTS failed — fall through to LIKE search.
 	}
 
-	q := "SELECT id, content, category, COALESCE(tags,'[]'), thing_id, source, COALESCE(expires_at,''), created_at FROM memories WHERE (expires_at IS NULL OR expires_at > datetime('now'))"
+	q := "SELECT id, content, category, COALESCE(tags,'[]'), thing_id, source, COALESCE(expires_at,''), created_at FROM memories WHERE status = 'active' AND (expires_at IS NULL OR expires_at > datetime('now'))"
 	var args []any
 	if query != "" {
 		q += " AND content LIKE ?"
@@ -73,6 +286,7 @@ func (d *DB) searchMemoriesFTS(query, category, tag string, thingID *int64, sinc
 		FROM memories_fts f
 		JOIN memories m ON m.id = f.rowid
 		WHERE memories_fts MATCH ?
+		  AND m.status = 'active'
 		  AND (m.expires_at IS NULL OR m.expires_at > datetime('now'))`
 	args := []any{query}
 	if category != "" {
@@ -101,7 +315,7 @@ func (d *DB) ListRecentMemories(category string, limit int) ([]Memory, error) {
 	if limit <= 0 {
 		limit = 10
 	}
-	q := "SELECT id, content, category, COALESCE(tags,'[]'), thing_id, source, COALESCE(expires_at,''), created_at FROM memories WHERE (expires_at IS NULL OR expires_at > datetime('now'))"
+	q := "SELECT id, content, category, COALESCE(tags,'[]'), thing_id, source, COALESCE(expires_at,''), created_at FROM memories WHERE status = 'active' AND (expires_at IS NULL OR expires_at > datetime('now'))"
 	var args []any
 	if category != "" {
 		q += " AND category = ?"
@@ -112,17 +326,43 @@ func (d *DB) ListRecentMemories(category string, limit int) ([]Memory, error) {
 	return d.scanMemories(q, args...)
 }
 
-// GetRecentMemoriesForCheckIn returns memories from the last N days, prioritizing blockers and decisions.
-func (d *DB) GetRecentMemoriesForCheckIn(days int) ([]Memory, error) {
-	q := `SELECT id, content, category, COALESCE(tags,'[]'), thing_id, source, COALESCE(expires_at,''), created_at
-		FROM memories
-		WHERE created_at > datetime('now', '-' || ? || ' days')
-		  AND (expires_at IS NULL OR expires_at > datetime('now'))
+// Check-in memory window defaults, used when a schedule doesn't set its own.
+const (
+	CheckInMemoryDays  = 7
+	CheckInMemoryLimit = 20
+)
+
+// GetRecentMemoriesForCheckIn returns up to limit memories from the last
+// days days, most important first. Importance is a category weight (blockers
+// and decisions highest, habits lowest), plus one for memories linked to an
+// open thing, minus up to one for age across the window, so a monthly review
+// keeps an early-month decision over last week's observations. Zero days or
+// limit use the defaults (7 days, 20 memories).
+func (d *DB) GetRecentMemoriesForCheckIn(days, limit int) ([]Memory, error) {
+	if days <= 0 {
+		days = CheckInMemoryDays
+	}
+	if limit <= 0 {
+		limit = CheckInMemoryLimit
+	}
+	q := `SELECT m.id, m.content, m.category, COALESCE(m.tags,'[]'), m.thing_id, m.source, COALESCE(m.expires_at,''), m.created_at
+		FROM memories m
+		WHERE m.created_at > datetime('now', '-' || ? || ' days')
+		  AND m.status = 'active'
+		  AND (m.expires_at IS NULL OR m.expires_at > datetime('now'))
 		ORDER BY
-		  CASE category WHEN 'blocker' THEN 0 WHEN 'decision' THEN 1 WHEN 'event' THEN 2 ELSE 3 END,
-		  created_at DESC
-		LIMIT 20`
-	return d.scanMemories(q, days)
+		  CASE m.category
+		    WHEN 'blocker' THEN 3 WHEN 'decision' THEN 2.5 WHEN 'event' THEN 2
+		    WHEN 'preference' THEN 1.5 WHEN 'habit' THEN 0.5 ELSE 1 END
+		  + EXISTS (SELECT 1 FROM things t WHERE t.id = m.thing_id AND t.status IN ('open', 'active'))
+		  - (julianday('now') - julianday(m.created_at)) / ? DESC,
+		  m.created_at DESC, m.id DESC
+		LIMIT ?`
+	memories, err := d.scanMemories(q, days, float64(days), limit)
+	if err != nil {
+		return nil, fmt.Errorf("getting check-in memories: %w", err)
+	}
+	return memories, nil
 }
 
 // UpdateMemory updates specific fields on a memory by ID.
@@ -141,7 +381,7 @@ func (d *DB) DeleteMemory(id int64) error {
 	if n == 0 {
 		return fmt.Errorf("memory %d not found", id)
 	}
-	return nil
+	return clearContextCard(d.conn)
 }
 
 // ResolveMemory marks a memory (typically a blocker) as resolved by changing
@@ -161,6 +401,191 @@ func (d *DB) ResolveMemory(id int64, resolution string) error {
 	return nil
 }
 
+// ListProposedMemories returns memories awaiting user approval, oldest first.
+func (d *DB) ListProposedMemories() ([]Memory, error) {
+	q := `SELECT id, content, category, COALESCE(tags,'[]'), thing_id, source, COALESCE(expires_at,''), created_at
+		FROM memories WHERE status = 'proposed' ORDER BY created_at ASC, id ASC`
+	return d.scanMemories(q)
+}
+
+// CountProposedMemories returns how many memories are awaiting user approval.
+func (d *DB) CountProposedMemories() (int, error) {
+	var n int
+	if err := d.conn.QueryRow("SELECT COUNT(*) FROM memories WHERE status = 'proposed'").Scan(&n); err != nil {
+		return 0, fmt.Errorf("counting proposed memories: %w", err)
+	}
+	return n, nil
+}
+
+// MemoryStats summarizes the shape of stored memories without listing them.
+type MemoryStats struct {
+	Total              int            `json:"total"`
+	ByCategory         map[string]int `json:"by_category"`
+	Proposed           int            `json:"proposed"`
+	Expiring           int            `json:"expiring"`                      // active memories with a pending expires_at
+	UnresolvedBlockers int            `json:"unresolved_blockers"`           // blockers that still block (see unresolvedBlocker)
+	OldestBlockerDays  int            `json:"oldest_blocker_days,omitempty"` // age of the oldest unresolved blocker
+}
+
+// GetMemoryStats counts active, unexpired memories by category, along with
+// proposed memories, memories awaiting expiry, and the number and oldest age
+// in days of unresolved blockers. by_category counts every blocker, including
+// those whose thing is finished; unresolved_blockers and oldest_blocker_days
+// share one predicate, so they always agree.
+func (d *DB) GetMemoryStats() (*MemoryStats, error) {
+	s := &MemoryStats{ByCategory: make(map[string]int)}
+	rows, err := d.conn.Query(`SELECT category, COUNT(*) FROM memories
+		WHERE status = 'active' AND (expires_at IS NULL OR expires_at > datetime('now'))
+		GROUP BY category`)
+	if err != nil {
+		return nil, fmt.Errorf("counting memories: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var category string
+		var n int
+		if err := rows.Scan(&category, &n); err != nil {
+			return nil, fmt.Errorf("scanning memory count: %w", err)
+		}
+		s.ByCategory[category] = n
+		s.Total += n
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	err = d.conn.QueryRow(`SELECT
+		(SELECT COUNT(*) FROM memories WHERE status = 'proposed'),
+		(SELECT COUNT(*) FROM memories WHERE status = 'active' AND expires_at > datetime('now')),
+		(SELECT COUNT(*) FROM memories m WHERE `+unresolvedBlocker+`),
+		(SELECT COALESCE(CAST(julianday('now') - julianday(MIN(created_at)) AS INTEGER), 0) FROM memories m
+			WHERE `+unresolvedBlocker+`)`,
+	).Scan(&s.Proposed, &s.Expiring, &s.UnresolvedBlockers, &s.OldestBlockerDays)
+	if err != nil {
+		return nil, fmt.Errorf("getting memory stats: %w", err)
+	}
+	return s, nil
+}
+
+// unresolvedBlocker matches blocker memories (aliased m) that still block:
+// active, unexpired, and not linked to a thing that is done or dropped.
+// ResolveMemory moves a blocker out of the category entirely.
+const unresolvedBlocker = `m.category = 'blocker' AND m.status = 'active'
+	AND (m.expires_at IS NULL OR m.expires_at > datetime('now'))
+	AND NOT EXISTS (SELECT 1 FROM things t WHERE t.id = m.thing_id AND t.status IN ('done', 'dropped'))`
+
+// AgingBlocker is an unresolved blocker memory and how long it has been open.
+type AgingBlocker struct {
+	Memory
+	AgeDays int `json:"age_days"`
+}
+
+// ListAgingBlockers returns unresolved blockers at least minDays old, oldest
+// first, so check-ins can escalate them.
+func (d *DB) ListAgingBlockers(minDays int) ([]AgingBlocker, error) {
+	rows, err := d.conn.Query(`SELECT m.id, m.content, m.category, COALESCE(m.tags,'[]'), m.thing_id, m.source, COALESCE(m.expires_at,''), m.created_at,
+			CAST(julianday('now') - julianday(m.created_at) AS INTEGER) AS age
+		FROM memories m
+		WHERE `+unresolvedBlocker+`
+		  AND m.created_at <= datetime('now', '-' || ? || ' days')
+		ORDER BY m.created_at ASC, m.id ASC`, minDays)
+	if err != nil {
+		return nil, fmt.Errorf("listing aging blockers: %w", err)
+	}
+	defer rows.Close()
+	var blockers []AgingBlocker
+	for rows.Next() {
+		var b AgingBlocker
+		var tagsJSON string
+		if err := rows.Scan(&b.ID, &b.Content, &b.Category, &tagsJSON, &b.ThingID, &b.Source, &b.ExpiresAt, &b.CreatedAt, &b.AgeDays); err != nil {
+			return nil, fmt.Errorf("scanning blocker: %w", err)
+		}
+		b.Tags = decodeTags(tagsJSON)
+		blockers = append(blockers, b)
+	}
+	return blockers, rows.Err()
+}
+
+// Line renders s as one compact line, categories largest first, e.g.
+// "42 memories: 20 observation, 12 decision, 3 blocker (oldest 9d); 2 expiring; 1 proposed".
+func (s *MemoryStats) Line() string {
+	cats := make([]string, 0, len(s.ByCategory))
+	for c := range s.ByCategory {
+		cats = append(cats, c)
+	}
+	sort.Slice(cats, func(i, j int) bool {
+		if s.ByCategory[cats[i]] != s.ByCategory[cats[j]] {
+			return s.ByCategory[cats[i]] > s.ByCategory[cats[j]]
+		}
+		return cats[i] < cats[j]
+	})
+	parts := make([]string, len(cats))
+	for i, c := range cats {
+		parts[i] = fmt.Sprintf("%d %s", s.ByCategory[c], c)
+		if c != "blocker" {
+			continue
+		}
+		var notes []string
+		if s.UnresolvedBlockers != s.ByCategory[c] {
+			notes = append(notes, fmt.Sprintf("%d unresolved", s.UnresolvedBlockers))
+		}
+		if s.OldestBlockerDays > 0 {
+			notes = append(notes, fmt.Sprintf("oldest %dd", s.OldestBlockerDays))
+		}
+		if len(notes) > 0 {
+			parts[i] += " (" + strings.Join(notes, ", ") + ")"
+		}
+	}
+	line := fmt.Sprintf("%d memories", s.Total)
+	if len(parts) > 0 {
+		line += ": " + strings.Join(parts, ", ")
+	}
+	if s.Expiring > 0 {
+		line += fmt.Sprintf("; %d expiring", s.Expiring)
+	}
+	if s.Proposed > 0 {
+		line += fmt.Sprintf("; %d proposed", s.Proposed)
+	}
+	return line
+}
+
+// ApproveMemories moves proposed memories to active. An empty ids slice
+// approves every proposed memory. Returns the number approved.
+func (d *DB) ApproveMemories(ids []int64) (int64, error) {
+	q, args := proposedWhere(ids)
+	res, err := d.conn.Exec("UPDATE memories SET status = 'active', updated_at = datetime('now') WHERE "+q, args...)
+	if err != nil {
+		return 0, fmt.Errorf("approving memories: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// RejectMemories deletes proposed memories. An empty ids slice rejects every
+// proposed memory. Active memories are never touched. Returns the number rejected.
+func (d *DB) RejectMemories(ids []int64) (int64, error) {
+	q, args := proposedWhere(ids)
+	res, err := d.conn.Exec("DELETE FROM memories WHERE "+q, args...)
+	if err != nil {
+		return 0, fmt.Errorf("rejecting memories: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// proposedWhere builds a WHERE clause matching proposed memories, limited to ids if given.
+func proposedWhere(ids []int64) (string, []any) {
+	q := "status = 'proposed'"
+	if len(ids) == 0 {
+		return q, nil
+	}
+	placeholders := make([]string, len(ids))
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+	return q + " AND id IN (" + strings.Join(placeholders, ",") + ")", args
+}
+
 // PruneExpiredMemories deletes memories past their expiry.
 func (d *DB) PruneExpiredMemories() (int64, error) {
 	res, err := d.conn.Exec("DELETE FROM memories WHERE expires_at IS NOT NULL AND expires_at < datetime('now')")
@@ -183,7 +608,7 @@ func (d *DB) scanMemories(query string, args ...any) ([]Memory, error) {
 		if err := rows.Scan(&m.ID, &m.Content, &m.Category, &tagsJSON, &m.ThingID, &m.Source, &m.ExpiresAt, &m.CreatedAt); err != nil {
 			return nil, fmt.Errorf("scanning memory: %w", err)
 		}
-		_ = json.Unmarshal([]byte(tagsJSON), &m.Tags)
+		m.Tags = decodeTags(tagsJSON)
 		memories = append(memories, m)
 	}
 	return memories, rows.Err()
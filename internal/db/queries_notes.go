@@ -5,6 +5,19 @@ import (
 	"fmt"
 )
 
+// ContextCardNote holds the agent's cached daily context card. It quotes
+// thing titles, reminders, and pinned memories, so every path that deletes
+// those clears it and the next turn rebuilds it.
+const ContextCardNote = "context_card"
+
+// clearContextCard drops the cached context card.
+func clearContextCard(e execer) error {
+	if _, err := e.Exec("DELETE FROM notes WHERE key = ?", ContextCardNote); err != nil {
+		return fmt.Errorf("clearing context card: %w", err)
+	}
+	return nil
+}
+
 // GetNote retrieves a note by key.
 func (d *DB) GetNote(key string) (string, error) {
 	var value string
@@ -20,7 +33,11 @@ func (d *DB) GetNote(key string) (string, error) {
 
 // SetNote stores or updates a note by key.
 func (d *DB) SetNote(key, value string) error {
-	_, err := d.conn.Exec(
+	return setNote(d.conn, key, value)
+}
+
+func setNote(x execer, key, value string) error {
+	_, err := x.Exec(
 		"INSERT INTO notes (key, value) VALUES (?, ?) ON CONFLICT(key) DO UPDATE SET value = ?, updated_at = datetime('now')",
 		key, value, value,
 	)
@@ -0,0 +1,261 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// PurgeCandidates is everything matching a purge query, grouped by table.
+type PurgeCandidates struct {
+	Query         string                `json:"query"`
+	Memories      []Memory              `json:"memories,omitempty"`
+	Things        []Thing               `json:"things,omitempty"`
+	Summaries     []ConversationSummary `json:"conversation_summaries,omitempty"`
+	Transcript    []TranscriptMessage   `json:"transcript,omitempty"`
+	ScheduleRuns  []ScheduleRun         `json:"schedule_runs,omitempty"`
+	Jobs          []Job                 `json:"jobs,omitempty"`
+	Deliveries    []Delivery            `json:"deliveries,omitempty"`
+	Audit         []AuditEntry          `json:"audit_log,omitempty"`
+	Conversations []string              `json:"conversations,omitempty"` // user IDs whose live history mentions the query
+}
+
+// Count returns the total number of matching rows.
+func (c *PurgeCandidates) Count() int {
+	return len(c.Memories) + len(c.Things) + len(c.Summaries) + len(c.Transcript) + len(c.ScheduleRuns) + len(c.Jobs) + len(c.Deliveries) + len(c.Audit) + len(c.Conversations)
+}
+
+// PurgeResult reports how many rows a purge deleted from each table.
+type PurgeResult struct {
+	Memories      int64 `json:"memories"`
+	Things        int64 `json:"things"`
+	Summaries     int64 `json:"conversation_summaries"`
+	Transcript    int64 `json:"transcript"`
+	ScheduleRuns  int64 `json:"schedule_runs"`
+	Jobs          int64 `json:"jobs"`
+	Deliveries    int64 `json:"deliveries"`
+	Audit         int64 `json:"audit_log"`
+	Conversations int64 `json:"conversations"`
+}
+
+// FindPurgeCandidates lists every stored row that mentions query: memories
+// (via FTS, including proposed and expired ones), things (title, notes, tags),
+// conversation summaries, transcript messages, schedule run output, background
+// jobs (title, prompt, result, error), queued deliveries, audit log entries,
+// and live conversation history.
+// Nothing is deleted.
+func (d *DB) FindPurgeCandidates(query string) (*PurgeCandidates, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("purge query is required")
+	}
+	c := &PurgeCandidates{Query: query}
+	like := likePattern(query)
+
+	var err error
+	c.Memories, err = d.scanMemories(`SELECT m.id, m.content, m.category, COALESCE(m.tags,'[]'), m.thing_id, m.source, COALESCE(m.expires_at,''), m.created_at
+		FROM memories m
+		WHERE m.id IN (SELECT rowid FROM memories_fts WHERE memories_fts MATCH ?)
+		   OR m.tags LIKE ? ESCAPE '\'
+		ORDER BY m.created_at`,
+		ftsPhrase(query), like,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("finding memories to purge: %w", err)
+	}
+
+	c.Things, err = d.scanThings(`SELECT id, title, COALESCE(notes,''), status, priority,
+		COALESCE(tags,'[]'), COALESCE(due_date,''), created_at, updated_at,
+		COALESCE(completed_at,'') FROM things
+		WHERE title LIKE ? ESCAPE '\' OR notes LIKE ? ESCAPE '\' OR tags LIKE ? ESCAPE '\'
+		ORDER BY created_at`,
+		like, like, like,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("finding things to purge: %w", err)
+	}
+
+	rows, err := d.conn.Query(`SELECT id, user_id, summary, COALESCE(message_count, 0), created_at
+		FROM conversation_summaries WHERE summary LIKE ? ESCAPE '\' ORDER BY created_at`, like)
+	if err != nil {
+		return nil, fmt.Errorf("finding summaries to purge: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var s ConversationSummary
+		if err := rows.Scan(&s.ID, &s.UserID, &s.Summary, &s.MessageCount, &s.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning summary: %w", err)
+		}
+		c.Summaries = append(c.Summaries, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	c.Transcript, err = d.scanTranscript(`SELECT id, user_id, role, content, created_at
+		FROM conversation_messages
+		WHERE id IN (SELECT rowid FROM conversation_messages_fts WHERE conversation_messages_fts MATCH ?)
+		ORDER BY created_at`, ftsPhrase(query))
+	if err != nil {
+		return nil, fmt.Errorf("finding transcript messages to purge: %w", err)
+	}
+
+	c.ScheduleRuns, err = d.scanScheduleRuns(`SELECT id, schedule_name, check_in, output, created_at
+		FROM schedule_runs WHERE output LIKE ? ESCAPE '\' ORDER BY created_at`, like)
+	if err != nil {
+		return nil, fmt.Errorf("finding schedule runs to purge: %w", err)
+	}
+
+	c.Jobs, err = d.scanJobs(`SELECT `+jobColumns+` FROM jobs
+		WHERE title LIKE ? ESCAPE '\' OR prompt LIKE ? ESCAPE '\' OR result LIKE ? ESCAPE '\' OR error LIKE ? ESCAPE '\' ORDER BY id`,
+		like, like, like, like)
+	if err != nil {
+		return nil, fmt.Errorf("finding jobs to purge: %w", err)
+	}
+
+	c.Deliveries, err = d.scanDeliveries(`SELECT `+deliveryColumns+` FROM deliveries
+		WHERE content LIKE ? ESCAPE '\' OR label LIKE ? ESCAPE '\' ORDER BY id`, like, like)
+	if err != nil {
+		return nil, fmt.Errorf("finding deliveries to purge: %w", err)
+	}
+
+	c.Audit, err = d.scanAudit(`SELECT id, tool, action, target, detail, profile, created_at
+		FROM audit_log WHERE target LIKE ? ESCAPE '\' OR detail LIKE ? ESCAPE '\' ORDER BY created_at, id`, like, like)
+	if err != nil {
+		return nil, fmt.Errorf("finding audit entries to purge: %w", err)
+	}
+
+	convRows, err := d.conn.Query(`SELECT user_id FROM conversations WHERE messages LIKE ? ESCAPE '\' ORDER BY user_id`, like)
+	if err != nil {
+		return nil, fmt.Errorf("finding conversations to purge: %w", err)
+	}
+	defer convRows.Close()
+	for convRows.Next() {
+		var userID string
+		if err := convRows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("scanning conversation: %w", err)
+		}
+		c.Conversations = append(c.Conversations, userID)
+	}
+	return c, convRows.Err()
+}
+
+// Purge deletes the given candidates in one transaction. Memories linked to a
+// purged thing are deleted with it. Matching conversations are cleared rather
+// than deleted, so the user's row survives. The cached context card is
+// dropped. Afterwards the FTS indexes are optimized so deleted text doesn't
+// linger in old index segments.
+func (d *DB) Purge(c *PurgeCandidates) (PurgeResult, error) {
+	var res PurgeResult
+	tx, err := d.conn.Begin()
+	if err != nil {
+		return res, fmt.Errorf("beginning purge: %w", err)
+	}
+	defer tx.Rollback()
+
+	var memoryIDs, thingIDs, summaryIDs, transcriptIDs, runIDs, jobIDs, deliveryIDs, auditIDs []any
+	for _, m := range c.Memories {
+		memoryIDs = append(memoryIDs, m.ID)
+	}
+	for _, t := range c.Things {
+		thingIDs = append(thingIDs, t.ID)
+	}
+	for _, s := range c.Summaries {
+		summaryIDs = append(summaryIDs, s.ID)
+	}
+	for _, m := range c.Transcript {
+		transcriptIDs = append(transcriptIDs, m.ID)
+	}
+	for _, r := range c.ScheduleRuns {
+		runIDs = append(runIDs, r.ID)
+	}
+	for _, j := range c.Jobs {
+		jobIDs = append(jobIDs, j.ID)
+	}
+	for _, dl := range c.Deliveries {
+		deliveryIDs = append(deliveryIDs, dl.ID)
+	}
+	for _, e := range c.Audit {
+		auditIDs = append(auditIDs, e.ID)
+	}
+
+	if res.Memories, err = execIn(tx, "DELETE FROM memories WHERE id IN", memoryIDs); err != nil {
+		return res, fmt.Errorf("purging memories: %w", err)
+	}
+	linked, err := execIn(tx, "DELETE FROM memories WHERE thing_id IN", thingIDs)
+	if err != nil {
+		return res, fmt.Errorf("purging linked memories: %w", err)
+	}
+	res.Memories += linked
+	if res.Things, err = execIn(tx, "DELETE FROM things WHERE id IN", thingIDs); err != nil {
+		return res, fmt.Errorf("purging things: %w", err)
+	}
+	if res.Summaries, err = execIn(tx, "DELETE FROM conversation_summaries WHERE id IN", summaryIDs); err != nil {
+		return res, fmt.Errorf("purging summaries: %w", err)
+	}
+	if res.Transcript, err = execIn(tx, "DELETE FROM conversation_messages WHERE id IN", transcriptIDs); err != nil {
+		return res, fmt.Errorf("purging transcript: %w", err)
+	}
+	if res.ScheduleRuns, err = execIn(tx, "DELETE FROM schedule_runs WHERE id IN", runIDs); err != nil {
+		return res, fmt.Errorf("purging schedule runs: %w", err)
+	}
+	if res.Jobs, err = execIn(tx, "DELETE FROM jobs WHERE id IN", jobIDs); err != nil {
+		return res, fmt.Errorf("purging jobs: %w", err)
+	}
+	if res.Deliveries, err = execIn(tx, "DELETE FROM deliveries WHERE id IN", deliveryIDs); err != nil {
+		return res, fmt.Errorf("purging deliveries: %w", err)
+	}
+	if res.Audit, err = execIn(tx, "DELETE FROM audit_log WHERE id IN", auditIDs); err != nil {
+		return res, fmt.Errorf("purging audit log: %w", err)
+	}
+	for _, userID := range c.Conversations {
+		r, err := tx.Exec(`UPDATE conversations SET messages = '[]', updated_at = datetime('now') WHERE user_id = ?`, userID)
+		if err != nil {
+			return res, fmt.Errorf("clearing conversation %s: %w", userID, err)
+		}
+		n, _ := r.RowsAffected()
+		res.Conversations += n
+	}
+
+	if err := clearContextCard(tx); err != nil {
+		return res, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return res, fmt.Errorf("committing purge: %w", err)
+	}
+	d.thingsChanged()
+	for _, fts := range ftsTables {
+		if _, err := d.conn.Exec(fmt.Sprintf(`INSERT INTO %s(%s) VALUES('optimize')`, fts.Name, fts.Name)); err != nil {
+			return res, fmt.Errorf("optimizing %s: %w", fts.Name, err)
+		}
+	}
+	return res, nil
+}
+
+// execIn runs "<stmt> (?, ?, ...)" with ids, returning rows affected.
+// An empty ids slice is a no-op.
+func execIn(tx *sql.Tx, stmt string, ids []any) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+	r, err := tx.Exec(stmt+" ("+placeholders+")", ids...)
+	if err != nil {
+		return 0, err
+	}
+	return r.RowsAffected()
+}
+
+// likePattern matches s anywhere in a column, with LIKE's wildcards and the
+// escape character in s taken literally. Use it with ESCAPE '\'.
+func likePattern(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return "%" + r.Replace(s) + "%"
+}
+
+// ftsPhrase quotes s as a single FTS5 phrase so punctuation and operators in
+// user input are matched literally.
+func ftsPhrase(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
@@ -0,0 +1,128 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chris/jot/internal/llm"
+)
+
+func TestFindPurgeCandidates(t *testing.T) {
+	d := openTestDB(t)
+
+	d.SaveMemory("Started at Acme Corp in March", "event", "agent", nil, nil, "")
+	d.SaveMemory("unrelated note about gardening", "observation", "agent", nil, nil, "")
+	d.ProposeMemory("Manager at Acme was difficult", "observation", "agent", nil, nil, "")
+	d.CreateThing("Return Acme laptop", "", "", "", nil)
+	d.CreateThing("Buy milk", "", "", "", nil)
+	d.SaveConversationSummary("user1", "Talked about leaving Acme.", 4)
+	d.SaveConversation("user1", []llm.Message{{Role: "user", Content: "I quit acme today"}})
+	d.SaveConversation("user2", []llm.Message{{Role: "user", Content: "hello"}})
+	d.LogScheduleRun(Schedule{Name: "morning-checkin"}, "Acme exit is this week.")
+
+	c, err := d.FindPurgeCandidates("Acme")
+	if err != nil {
+		t.Fatalf("FindPurgeCandidates: %v", err)
+	}
+	if len(c.Memories) != 2 {
+		t.Errorf("expected 2 memories (active + proposed), got %d", len(c.Memories))
+	}
+	if len(c.Things) != 1 || c.Things[0].Title != "Return Acme laptop" {
+		t.Errorf("unexpected things: %+v", c.Things)
+	}
+	if len(c.Summaries) != 1 {
+		t.Errorf("expected 1 summary, got %d", len(c.Summaries))
+	}
+	if len(c.Conversations) != 1 || c.Conversations[0] != "user1" {
+		t.Errorf("unexpected conversations: %v", c.Conversations)
+	}
+	if len(c.ScheduleRuns) != 1 {
+		t.Errorf("expected 1 schedule run, got %d", len(c.ScheduleRuns))
+	}
+	if c.Count() != 6 {
+		t.Errorf("Count = %d, want 6", c.Count())
+	}
+
+	if _, err := d.FindPurgeCandidates("  "); err == nil {
+		t.Error("expected error for empty query")
+	}
+}
+
+func TestFindPurgeCandidatesLiteral(t *testing.T) {
+	d := openTestDB(t)
+	d.CreateThing("Raise rates 50%", "", "", "", nil)
+	d.CreateThing("Sell 500 shares", "", "", "", nil)
+	d.CreateThing("Rename file_a", "", "", "", nil)
+	d.CreateThing("Rename filexa", "", "", "", nil)
+	d.CreateThing(`Back up C:\temp`, "", "", "", nil)
+	d.CreateThing("Back up C:temp", "", "", "", nil)
+
+	for query, want := range map[string]string{
+		"50%":     "Raise rates 50%",
+		"file_a":  "Rename file_a",
+		`C:\temp`: `Back up C:\temp`,
+	} {
+		c, err := d.FindPurgeCandidates(query)
+		if err != nil {
+			t.Fatalf("FindPurgeCandidates(%q): %v", query, err)
+		}
+		if len(c.Things) != 1 || c.Things[0].Title != want {
+			t.Errorf("%q matched %+v, want only %q", query, c.Things, want)
+		}
+	}
+}
+
+func TestPurge(t *testing.T) {
+	d := openTestDB(t)
+
+	d.SaveMemory("Acme offer details", "event", "agent", nil, nil, "")
+	thingID, _ := d.CreateThing("Acme exit interview", "", "", "", nil)
+	d.SaveMemory("Bring badge to the interview", "observation", "agent", nil, &thingID, "")
+	d.SaveMemory("keep this one", "observation", "agent", nil, nil, "")
+	d.SaveConversationSummary("user1", "Discussed Acme.", 2)
+	d.SaveConversation("user1", []llm.Message{{Role: "user", Content: "Acme called"}})
+	d.AppendTranscript("user1", []llm.Message{{Role: "user", Content: "Acme called"}, {Role: "assistant", Content: "Noted."}})
+	d.LogAudit(AuditEntry{Tool: "create_thing", Action: "create", Target: "thing #1", Detail: "Acme exit interview"})
+	jobID, _ := d.EnqueueJob("Severance", "Compare severance offers")
+	d.FinishJob(jobID, "Acme's offer is the better one.", "")
+	d.EnqueueJob("Desks", "Research standing desks")
+	d.EnqueueDelivery("scheduler[morning]", "Acme exit interview is today.")
+	d.EnqueueDelivery("reminder[2]", "Water the plants")
+	d.LogAudit(AuditEntry{Tool: "complete_thing", Action: "update", Target: "thing #2", Detail: "marked done"})
+
+	c, _ := d.FindPurgeCandidates("Acme")
+	res, err := d.Purge(c)
+	if err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	if res.Memories != 2 || res.Things != 1 || res.Summaries != 1 || res.Transcript != 1 || res.Jobs != 1 || res.Deliveries != 1 || res.Audit != 1 || res.Conversations != 1 {
+		t.Errorf("unexpected purge result: %+v", res)
+	}
+
+	remaining, _ := d.ListRecentMemories("", 10)
+	if len(remaining) != 1 || remaining[0].Content != "keep this one" {
+		t.Errorf("expected only the unrelated memory to survive, got %+v", remaining)
+	}
+	if results, _ := d.SearchMemories("Acme", "", "", nil, "", 10); len(results) != 0 {
+		t.Errorf("expected FTS to forget purged memories, got %d", len(results))
+	}
+	if results, _ := d.SearchConversations("user1", "Noted", "", 10); len(results) != 1 {
+		t.Errorf("expected unrelated transcript message to survive, got %d", len(results))
+	}
+	if jobs, _ := d.ListJobs("", 10); len(jobs) != 1 || jobs[0].Title != "Desks" {
+		t.Errorf("expected only the unrelated job to survive, got %+v", jobs)
+	}
+	if due, _ := d.DueDeliveries(10); len(due) != 1 || due[0].Content != "Water the plants" {
+		t.Errorf("expected only the unrelated delivery to survive, got %+v", due)
+	}
+	if entries, _ := d.ListAudit(time.Time{}); len(entries) != 1 || entries[0].Detail != "marked done" {
+		t.Errorf("expected only the unrelated audit entry to survive, got %+v", entries)
+	}
+	msgs, _, _ := d.LoadConversation("user1")
+	if len(msgs) != 0 {
+		t.Errorf("expected conversation cleared, got %d messages", len(msgs))
+	}
+	if again, _ := d.FindPurgeCandidates("Acme"); again.Count() != 0 {
+		t.Errorf("expected nothing left to purge, got %d", again.Count())
+	}
+}
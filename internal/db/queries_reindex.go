@@ -0,0 +1,57 @@
+package db
+
+import "fmt"
+
+// ftsTables lists every FTS5 index and the table it indexes. Add new
+// external-content indexes here so purge and reindex cover them.
+var ftsTables = []struct{ Name, Source string }{
+	{"memories_fts", "memories"},
+	{"conversation_messages_fts", "conversation_messages"},
+}
+
+// ReindexResult reports one FTS index rebuild.
+type ReindexResult struct {
+	Table   string `json:"table"`
+	Source  int    `json:"source_rows"`
+	Before  int    `json:"indexed_before"`
+	Indexed int    `json:"indexed_after"`
+}
+
+// Reindex rebuilds every FTS index from its source table and verifies the
+// result with FTS5's integrity check. Use it after trigger drift or bulk
+// imports that bypassed the triggers.
+func (d *DB) Reindex() ([]ReindexResult, error) {
+	var results []ReindexResult
+	for _, fts := range ftsTables {
+		r := ReindexResult{Table: fts.Name}
+		var err error
+		if r.Before, err = d.count(fts.Name + "_docsize"); err != nil {
+			return results, err
+		}
+		if _, err := d.conn.Exec(fmt.Sprintf(`INSERT INTO %s(%s) VALUES('rebuild')`, fts.Name, fts.Name)); err != nil {
+			return results, fmt.Errorf("rebuilding %s: %w", fts.Name, err)
+		}
+		if _, err := d.conn.Exec(fmt.Sprintf(`INSERT INTO %s(%s, rank) VALUES('integrity-check', 1)`, fts.Name, fts.Name)); err != nil {
+			return results, fmt.Errorf("verifying %s: %w", fts.Name, err)
+		}
+		if r.Source, err = d.count(fts.Source); err != nil {
+			return results, err
+		}
+		if r.Indexed, err = d.count(fts.Name + "_docsize"); err != nil {
+			return results, err
+		}
+		results = append(results, r)
+		if r.Indexed != r.Source {
+			return results, fmt.Errorf("%s has %d rows after rebuild, %s has %d", fts.Name, r.Indexed, fts.Source, r.Source)
+		}
+	}
+	return results, nil
+}
+
+func (d *DB) count(table string) (int, error) {
+	var n int
+	if err := d.conn.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&n); err != nil {
+		return 0, fmt.Errorf("counting %s: %w", table, err)
+	}
+	return n, nil
+}
@@ -0,0 +1,47 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/chris/jot/internal/llm"
+)
+
+func TestReindexRepairsDrift(t *testing.T) {
+	d := openTestDB(t)
+
+	d.SaveMemory("the landlord fixed the boiler", "event", "user", nil, nil, "")
+	d.SaveMemory("renewed the lease for a year", "decision", "user", nil, nil, "")
+	d.AppendTranscript("u1", []llm.Message{{Role: "user", Content: "ask the landlord about parking"}})
+
+	// Simulate drift: wipe the indexes and add a row the triggers never saw.
+	for _, fts := range ftsTables {
+		if _, err := d.conn.Exec(`INSERT INTO ` + fts.Name + `(` + fts.Name + `) VALUES('delete-all')`); err != nil {
+			t.Fatalf("clearing %s: %v", fts.Name, err)
+		}
+	}
+	if mems, _ := d.SearchMemories("landlord", "", "", nil, "", 10); len(mems) != 0 {
+		t.Fatalf("expected no FTS hits after wiping index, got %d", len(mems))
+	}
+
+	results, err := d.Reindex()
+	if err != nil {
+		t.Fatalf("Reindex: %v", err)
+	}
+	if len(results) != len(ftsTables) {
+		t.Fatalf("expected %d results, got %+v", len(ftsTables), results)
+	}
+	if r := results[0]; r.Table != "memories_fts" || r.Before != 0 || r.Indexed != 2 || r.Source != 2 {
+		t.Errorf("memories result = %+v", r)
+	}
+	if mems, _ := d.SearchMemories("landlord", "", "", nil, "", 10); len(mems) != 1 {
+		t.Errorf("expected FTS hit after reindex, got %d", len(mems))
+	}
+	if msgs, _ := d.SearchConversations("u1", "parking", "", 10); len(msgs) != 1 {
+		t.Errorf("expected transcript hit after reindex, got %d", len(msgs))
+	}
+
+	// Reindexing a healthy index is a no-op.
+	if results, err := d.Reindex(); err != nil || results[0].Before != 2 {
+		t.Errorf("second reindex = %+v, %v", results, err)
+	}
+}
@@ -1,35 +1,25 @@
 package db
 
 import (
-	"database/sql"
 	"fmt"
 	"strings"
 )
 
+// scheduleColumns is the SELECT list scanSchedules expects.
+const scheduleColumns = `id, name, cron_expr, prompt, enabled, COALESCE(last_run,''), COALESCE(fire_at,''), fired, check_in, COALESCE(memory_days,0), COALESCE(memory_limit,0), COALESCE(digest,0), created_at`
+
 // ListSchedules returns all schedules, optionally only enabled ones.
 func (d *DB) ListSchedules(enabledOnly bool) ([]Schedule, error) {
-	q := "SELECT id, name, cron_expr, prompt, enabled, COALESCE(last_run,''), COALESCE(fire_at,''), fired, created_at FROM schedules"
+	q := "SELECT " + scheduleColumns + " FROM schedules"
 	if enabledOnly {
 		q += " WHERE enabled = 1"
 	}
 	q += " ORDER BY created_at ASC"
-	rows, err := d.conn.Query(q)
+	schedules, err := d.scanSchedules(q)
 	if err != nil {
 		return nil, fmt.Errorf("listing schedules: %w", err)
 	}
-	defer rows.Close()
-	var out []Schedule
-	for rows.Next() {
-		var s Schedule
-		var enabled, fired int
-		if err := rows.Scan(&s.ID, &s.Name, &s.CronExpr, &s.Prompt, &enabled, &s.LastRun, &s.FireAt, &fired, &s.CreatedAt); err != nil {
-			return nil, fmt.Errorf("scanning schedule: %w", err)
-		}
-		s.Enabled = enabled == 1
-		s.Fired = fired == 1
-		out = append(out, s)
-	}
-	return out, rows.Err()
+	return schedules, nil
 }
 
 // CreateSchedule creates a new recurring schedule and returns its ID.
@@ -58,14 +48,14 @@ func (d *DB) CreateOneShot(name, prompt, fireAt string) (int64, error) {
 
 // ListPendingOneShots returns one-shot schedules that are due and not yet fired.
 func (d *DB) ListPendingOneShots() ([]Schedule, error) {
-	q := `SELECT id, name, cron_expr, prompt, enabled, COALESCE(last_run,''), COALESCE(fire_at,''), fired, created_at
+	q := `SELECT ` + scheduleColumns + `
 		FROM schedules WHERE fire_at IS NOT NULL AND fire_at <= datetime('now') AND fired = 0`
 	return d.scanSchedules(q)
 }
 
 // ListUpcomingOneShots returns one-shot schedules that haven't fired yet and are in the future.
 func (d *DB) ListUpcomingOneShots() ([]Schedule, error) {
-	q := `SELECT id, name, cron_expr, prompt, enabled, COALESCE(last_run,''), COALESCE(fire_at,''), fired, created_at
+	q := `SELECT ` + scheduleColumns + `
 		FROM schedules WHERE fire_at IS NOT NULL AND fire_at > datetime('now') AND fired = 0
 		ORDER BY fire_at ASC`
 	return d.scanSchedules(q)
@@ -86,7 +76,7 @@ func (d *DB) MarkOneShotFired(id int64) error {
 
 // UpdateSchedule updates fields on a schedule by ID.
 func (d *DB) UpdateSchedule(id int64, fields map[string]any) error {
-	allowed := map[string]bool{"cron_expr": true, "prompt": true, "enabled": true}
+	allowed := allowedColumns["schedules"]
 	if len(fields) == 0 {
 		return nil
 	}
@@ -114,7 +104,7 @@ func (d *DB) DeleteSchedule(name string) error {
 	if err != nil {
 		return fmt.Errorf("deleting schedule: %w", err)
 	}
-	return nil
+	return clearContextCard(d.conn)
 }
 
 // RecordScheduleRun updates last_run to now for a schedule.
@@ -128,6 +118,61 @@ func (d *DB) RecordScheduleRun(id int64) error {
 	return nil
 }
 
+// scheduleRunsKept caps how many runs are kept per schedule: about three
+// years of a daily check-in, or six weeks of an hourly one.
+const scheduleRunsKept = 1000
+
+// LogScheduleRun stores the output of a recurring schedule run, dropping the
+// schedule's oldest runs beyond scheduleRunsKept.
+func (d *DB) LogScheduleRun(s Schedule, output string) error {
+	tx, err := d.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("logging schedule run: %w", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`INSERT INTO schedule_runs (schedule_name, check_in, output) VALUES (?, ?, ?)`,
+		s.Name, s.CheckIn, output); err != nil {
+		return fmt.Errorf("logging schedule run: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM schedule_runs WHERE schedule_name = ? AND id NOT IN (
+		SELECT id FROM schedule_runs WHERE schedule_name = ? ORDER BY id DESC LIMIT ?)`,
+		s.Name, s.Name, scheduleRunsKept); err != nil {
+		return fmt.Errorf("pruning schedule runs: %w", err)
+	}
+	return tx.Commit()
+}
+
+// ListScheduleRuns returns schedule runs since the given UTC datetime (all if
+// empty), oldest first, limited to the named schedules if any are given.
+func (d *DB) ListScheduleRuns(since string, names []string) ([]ScheduleRun, error) {
+	q := `SELECT id, schedule_name, check_in, output, created_at FROM schedule_runs WHERE created_at >= ?`
+	args := []any{since}
+	if len(names) > 0 {
+		q += ` AND schedule_name IN (` + strings.TrimSuffix(strings.Repeat("?,", len(names)), ",") + `)`
+		for _, n := range names {
+			args = append(args, n)
+		}
+	}
+	return d.scanScheduleRuns(q+` ORDER BY created_at, id`, args...)
+}
+
+func (d *DB) scanScheduleRuns(query string, args ...any) ([]ScheduleRun, error) {
+	rows, err := d.conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing schedule runs: %w", err)
+	}
+	defer rows.Close()
+	var runs []ScheduleRun
+	for rows.Next() {
+		var r ScheduleRun
+		if err := rows.Scan(&r.ID, &r.ScheduleName, &r.CheckIn, &r.Output, &r.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning schedule run: %w", err)
+		}
+		runs = append(runs, r)
+	}
+	return runs, rows.Err()
+}
+
 func (d *DB) scanSchedules(query string, args ...any) ([]Schedule, error) {
 	rows, err := d.conn.Query(query, args...)
 	if err != nil {
@@ -137,12 +182,14 @@ func (d *DB) scanSchedules(query string, args ...any) ([]Schedule, error) {
 	var out []Schedule
 	for rows.Next() {
 		var s Schedule
-		var enabled, fired int
-		if err := rows.Scan(&s.ID, &s.Name, &s.CronExpr, &s.Prompt, &enabled, &s.LastRun, &s.FireAt, &fired, &s.CreatedAt); err != nil {
+		var enabled, fired, checkIn, digest int
+		if err := rows.Scan(&s.ID, &s.Name, &s.CronExpr, &s.Prompt, &enabled, &s.LastRun, &s.FireAt, &fired, &checkIn, &s.MemoryDays, &s.MemoryLimit, &digest, &s.CreatedAt); err != nil {
 			return nil, fmt.Errorf("scanning schedule: %w", err)
 		}
 		s.Enabled = enabled == 1
 		s.Fired = fired == 1
+		s.CheckIn = checkIn == 1
+		s.Digest = digest == 1
 		out = append(out, s)
 	}
 	return out, rows.Err()
@@ -150,18 +197,12 @@ func (d *DB) scanSchedules(query string, args ...any) ([]Schedule, error) {
 
 // GetScheduleByName returns a schedule by name, or nil if not found.
 func (d *DB) GetScheduleByName(name string) (*Schedule, error) {
-	q := `SELECT id, name, cron_expr, prompt, enabled, COALESCE(last_run,''), COALESCE(fire_at,''), fired, created_at
-		FROM schedules WHERE name = ?`
-	var s Schedule
-	var enabled, fired int
-	err := d.conn.QueryRow(q, name).Scan(&s.ID, &s.Name, &s.CronExpr, &s.Prompt, &enabled, &s.LastRun, &s.FireAt, &fired, &s.CreatedAt)
-	if err == sql.ErrNoRows {
-		return nil, nil
-	}
+	schedules, err := d.scanSchedules("SELECT "+scheduleColumns+" FROM schedules WHERE name = ?", name)
 	if err != nil {
 		return nil, fmt.Errorf("getting schedule %q: %w", name, err)
 	}
-	s.Enabled = enabled == 1
-	s.Fired = fired == 1
-	return &s, nil
+	if len(schedules) == 0 {
+		return nil, nil
+	}
+	return &schedules[0], nil
 }
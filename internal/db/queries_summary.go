@@ -0,0 +1,153 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SummaryOptions configures GetSummary. Zero values fall back to defaults.
+type SummaryOptions struct {
+	RecentDays   int      // things created within this many days are "recent" (default 7)
+	RecentLimit  int      // max recent things returned (default 5)
+	ListLimit    int      // max things per due bucket (default 10)
+	OpenStatuses []string // statuses that count as open (default open, active)
+	Today        string   // user's local date, YYYY-MM-DD (default: today in UTC)
+	Tag          string   // limit everything to things with this tag
+	Area         string   // limit everything to things with a tag in this area
+}
+
+// Summary is an overview of things: counts plus due-date buckets and recent items.
+type Summary struct {
+	Counts      map[string]int `json:"counts"` // things per status
+	Open        int            `json:"open"`   // things in any open status
+	Overdue     []Thing        `json:"overdue"`
+	DueToday    []Thing        `json:"due_today"`
+	DueThisWeek []Thing        `json:"due_this_week"` // due in the 6 days after today
+	Recent      []Thing        `json:"recent"`
+	RecentDays  int            `json:"recent_days"`
+	Tag         string         `json:"tag,omitempty"`
+	Area        string         `json:"area,omitempty"`
+	Areas       []AreaRollup   `json:"areas,omitempty"` // unscoped summaries only; completed = last 7 days
+}
+
+func (o SummaryOptions) withDefaults() SummaryOptions {
+	if o.RecentDays <= 0 {
+		o.RecentDays = 7
+	}
+	if o.RecentLimit <= 0 {
+		o.RecentLimit = 5
+	}
+	if o.ListLimit <= 0 {
+		o.ListLimit = 10
+	}
+	if len(o.OpenStatuses) == 0 {
+		o.OpenStatuses = []string{"open", "active"}
+	}
+	if o.Today == "" {
+		o.Today = time.Now().UTC().Format(time.DateOnly)
+	}
+	return o
+}
+
+// GetSummary returns status counts, overdue / due-today / due-this-week
+// buckets of open things, and things created in the recent window, optionally
+// limited to one tag or area. Unscoped summaries also roll up each area.
+//
+// Results are cached in process until things or areas change (or for a
+// minute at most), since nearly every conversation asks for one. Callers
+// must not modify the returned Summary.
+func (d *DB) GetSummary(opts SummaryOptions) (*Summary, error) {
+	opts = opts.withDefaults()
+	key := summaryKey(opts)
+	s, version := d.summaries.get(key)
+	if s != nil {
+		return s, nil
+	}
+	s, err := d.getSummary(opts)
+	if err != nil {
+		return nil, err
+	}
+	d.summaries.put(key, version, s)
+	return s, nil
+}
+
+func (d *DB) getSummary(opts SummaryOptions) (*Summary, error) {
+	today, err := time.Parse(time.DateOnly, opts.Today)
+	if err != nil {
+		return nil, fmt.Errorf("parsing today %q: %w", opts.Today, err)
+	}
+	weekEnd := today.AddDate(0, 0, 6).Format(time.DateOnly)
+
+	// scope narrows every query to a tag and/or area; it's a no-op without them.
+	scope, scopeArgs := "1=1", []any{}
+	if opts.Tag != "" {
+		scope += " AND tags LIKE ?"
+		scopeArgs = append(scopeArgs, "%\""+opts.Tag+"\"%")
+	}
+	if opts.Area != "" {
+		scope += ` AND EXISTS (SELECT 1 FROM json_each(COALESCE(things.tags,'[]')) j
+			JOIN area_tags at ON at.tag = lower(j.value) JOIN areas a ON a.id = at.area_id WHERE a.name = ?)`
+		scopeArgs = append(scopeArgs, strings.ToLower(opts.Area))
+	}
+
+	s := &Summary{Counts: make(map[string]int), RecentDays: opts.RecentDays, Tag: opts.Tag, Area: opts.Area}
+	rows, err := d.conn.Query(`SELECT status, COUNT(*) FROM things WHERE `+scope+` GROUP BY status`, scopeArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("counting things: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var status string
+		var n int
+		if err := rows.Scan(&status, &n); err != nil {
+			return nil, fmt.Errorf("scanning count: %w", err)
+		}
+		s.Counts[status] = n
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for _, st := range opts.OpenStatuses {
+		s.Open += s.Counts[st]
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(opts.OpenStatuses)), ",")
+	openArgs := append([]any{}, scopeArgs...)
+	for _, st := range opts.OpenStatuses {
+		openArgs = append(openArgs, st)
+	}
+	base := `SELECT id, title, COALESCE(notes,''), status, priority,
+		COALESCE(tags,'[]'), COALESCE(due_date,''), created_at, updated_at,
+		COALESCE(completed_at,'') FROM things WHERE ` + scope + ` AND status IN (` + placeholders + `)`
+
+	bucket := func(cond string, args ...any) ([]Thing, error) {
+		q := base + ` AND due_date IS NOT NULL AND due_date != '' AND ` + cond + `
+			ORDER BY due_date, CASE priority WHEN 'urgent' THEN 0 WHEN 'high' THEN 1 WHEN 'normal' THEN 2 WHEN 'low' THEN 3 END
+			LIMIT ?`
+		all := append(append(append([]any{}, openArgs...), args...), opts.ListLimit)
+		return d.scanThings(q, all...)
+	}
+	if s.Overdue, err = bucket("due_date < ?", opts.Today); err != nil {
+		return nil, fmt.Errorf("listing overdue: %w", err)
+	}
+	if s.DueToday, err = bucket("due_date = ?", opts.Today); err != nil {
+		return nil, fmt.Errorf("listing due today: %w", err)
+	}
+	if s.DueThisWeek, err = bucket("due_date > ? AND due_date <= ?", opts.Today, weekEnd); err != nil {
+		return nil, fmt.Errorf("listing due this week: %w", err)
+	}
+
+	recentArgs := append(append([]any{}, openArgs...), fmt.Sprintf("-%d days", opts.RecentDays), opts.RecentLimit)
+	if s.Recent, err = d.scanThings(base+` AND created_at > datetime('now', ?) ORDER BY created_at DESC, id DESC LIMIT ?`, recentArgs...); err != nil {
+		return nil, fmt.Errorf("listing recent: %w", err)
+	}
+
+	if opts.Tag == "" && opts.Area == "" {
+		weekStart := today.AddDate(0, 0, -6).Format(time.DateOnly)
+		if s.Areas, err = d.AreaRollups(opts.OpenStatuses, opts.Today, weekStart); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
@@ -0,0 +1,135 @@
+package db
+
+import "testing"
+
+func TestGetSummaryBuckets(t *testing.T) {
+	d := openTestDB(t)
+
+	d.CreateThing("Overdue", "", "normal", "2026-03-01", nil)
+	d.CreateThing("Due today", "", "normal", "2026-03-10", nil)
+	d.CreateThing("Due Friday", "", "normal", "2026-03-13", nil)
+	d.CreateThing("Due next month", "", "normal", "2026-04-10", nil)
+	d.CreateThing("No date", "", "normal", "", nil)
+	done, _ := d.CreateThing("Done today", "", "normal", "2026-03-10", nil)
+	d.CompleteThing(done)
+
+	s, err := d.GetSummary(SummaryOptions{Today: "2026-03-10"})
+	if err != nil {
+		t.Fatalf("GetSummary: %v", err)
+	}
+	if s.Counts["open"] != 5 || s.Counts["done"] != 1 || s.Open != 5 {
+		t.Errorf("counts = %v, open = %d", s.Counts, s.Open)
+	}
+	check := func(name string, got []Thing, want ...string) {
+		t.Helper()
+		if len(got) != len(want) {
+			t.Fatalf("%s: got %d things, want %d", name, len(got), len(want))
+		}
+		for i, w := range want {
+			if got[i].Title != w {
+				t.Errorf("%s[%d] = %q, want %q", name, i, got[i].Title, w)
+			}
+		}
+	}
+	check("overdue", s.Overdue, "Overdue")
+	check("due today", s.DueToday, "Due today")
+	check("due this week", s.DueThisWeek, "Due Friday")
+	if len(s.Recent) != 5 || s.RecentDays != 7 {
+		t.Errorf("recent = %d things over %d days, want 5 over 7", len(s.Recent), s.RecentDays)
+	}
+}
+
+func TestGetSummaryOptions(t *testing.T) {
+	d := openTestDB(t)
+
+	for _, title := range []string{"a", "b", "c"} {
+		d.CreateThing(title, "", "normal", "", nil)
+	}
+	waiting, _ := d.CreateThing("Waiting on reply", "", "normal", "2026-03-01", nil)
+	d.UpdateThing(waiting, map[string]any{"status": "dropped"})
+
+	s, err := d.GetSummary(SummaryOptions{Today: "2026-03-10", RecentLimit: 2, OpenStatuses: []string{"open"}})
+	if err != nil {
+		t.Fatalf("GetSummary: %v", err)
+	}
+	if len(s.Recent) != 2 {
+		t.Errorf("recent = %d, want 2", len(s.Recent))
+	}
+	if s.Open != 3 || len(s.Overdue) != 0 {
+		t.Errorf("open = %d, overdue = %d; dropped thing should not count", s.Open, len(s.Overdue))
+	}
+
+	s, _ = d.GetSummary(SummaryOptions{Today: "2026-03-10", OpenStatuses: []string{"open", "dropped"}})
+	if s.Open != 4 || len(s.Overdue) != 1 {
+		t.Errorf("open = %d, overdue = %d with dropped counted as open", s.Open, len(s.Overdue))
+	}
+
+	if _, err := d.GetSummary(SummaryOptions{Today: "10/03/2026"}); err == nil {
+		t.Error("expected error for malformed today")
+	}
+}
+
+func TestGetSummaryTagScope(t *testing.T) {
+	d := openTestDB(t)
+
+	d.CreateThing("Fix gutter", "", "normal", "2026-03-01", []string{"house"})
+	d.CreateThing("Paint fence", "", "normal", "2026-03-10", []string{"house", "garden"})
+	d.CreateThing("Quarterly report", "", "normal", "2026-03-01", []string{"work"})
+	d.CreateThing("Housewarming gift", "", "normal", "", []string{"housewarming"})
+
+	s, err := d.GetSummary(SummaryOptions{Today: "2026-03-10", Tag: "house"})
+	if err != nil {
+		t.Fatalf("GetSummary: %v", err)
+	}
+	if s.Counts["open"] != 2 || s.Tag != "house" {
+		t.Errorf("counts = %v, tag = %q; want 2 open house things", s.Counts, s.Tag)
+	}
+	if len(s.Overdue) != 1 || s.Overdue[0].Title != "Fix gutter" {
+		t.Errorf("overdue = %+v, want only Fix gutter", s.Overdue)
+	}
+	if len(s.DueToday) != 1 || len(s.Recent) != 2 {
+		t.Errorf("due today = %d, recent = %d; want 1 and 2", len(s.DueToday), len(s.Recent))
+	}
+}
+
+func TestGetSummaryCache(t *testing.T) {
+	d := openTestDB(t)
+	opts := SummaryOptions{Today: "2026-03-10"}
+	id, _ := d.CreateThing("Pay rent", "", "normal", "2026-03-10", []string{"home"})
+
+	first, _ := d.GetSummary(opts)
+	if again, _ := d.GetSummary(opts); again != first {
+		t.Error("expected the cached summary on a repeat call")
+	}
+	if other, _ := d.GetSummary(SummaryOptions{Today: "2026-03-11"}); other == first {
+		t.Error("different options should not share a cache entry")
+	}
+
+	// Each write to things or areas busts the cache.
+	for name, change := range map[string]func(){
+		"update":   func() { d.UpdateThing(id, map[string]any{"priority": "high"}) },
+		"complete": func() { d.CompleteThing(id) },
+		"create":   func() { d.CreateThing("Call mum", "", "", "", nil) },
+		"area":     func() { d.SetArea("home", []string{"home"}) },
+	} {
+		before, _ := d.GetSummary(opts)
+		change()
+		if after, _ := d.GetSummary(opts); after == before {
+			t.Errorf("%s: expected a fresh summary", name)
+		}
+	}
+	if s, _ := d.GetSummary(opts); s.Counts["done"] != 1 || s.Counts["open"] != 1 {
+		t.Errorf("counts = %v, want 1 done and 1 open", s.Counts)
+	}
+
+	// A summary computed across a change isn't cached.
+	s, version := d.summaries.get("k")
+	if s != nil {
+		t.Fatal("unexpected entry")
+	}
+	d.thingsChanged()
+	d.summaries.put("k", version, &Summary{})
+	if s, _ := d.summaries.get("k"); s != nil {
+		t.Error("stale summary was cached")
+	}
+}
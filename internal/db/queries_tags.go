@@ -0,0 +1,28 @@
+package db
+
+import "fmt"
+
+// ListTags returns every distinct tag used on things or memories, most used first.
+func (d *DB) ListTags() ([]string, error) {
+	rows, err := d.conn.Query(`SELECT value, COUNT(*) AS n FROM (
+			SELECT j.value FROM things, json_each(things.tags) j WHERE json_valid(things.tags)
+			UNION ALL
+			SELECT j.value FROM memories, json_each(memories.tags) j WHERE json_valid(memories.tags)
+		)
+		WHERE value != ''
+		GROUP BY value ORDER BY n DESC, value`)
+	if err != nil {
+		return nil, fmt.Errorf("listing tags: %w", err)
+	}
+	defer rows.Close()
+	var tags []string
+	for rows.Next() {
+		var tag string
+		var n int
+		if err := rows.Scan(&tag, &n); err != nil {
+			return nil, fmt.Errorf("scanning tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
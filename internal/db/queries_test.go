@@ -1,6 +1,7 @@
 package db
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 	"time"
@@ -162,6 +163,40 @@ func TestUpdateThingRejectsBogusColumn(t *testing.T) {
 
 // --- Notes ---
 
+func TestGetThing(t *testing.T) {
+	d := openTestDB(t)
+
+	id, _ := d.CreateThing("Renew passport", "bring photos", "high", "", []string{"admin"})
+	thing, err := d.GetThing(id)
+	if err != nil {
+		t.Fatalf("GetThing: %v", err)
+	}
+	if thing == nil || thing.Title != "Renew passport" || thing.Notes != "bring photos" {
+		t.Errorf("unexpected thing: %+v", thing)
+	}
+
+	missing, err := d.GetThing(999)
+	if err != nil || missing != nil {
+		t.Errorf("expected (nil, nil) for missing thing, got (%v, %v)", missing, err)
+	}
+}
+
+func TestListTags(t *testing.T) {
+	d := openTestDB(t)
+
+	d.CreateThing("a", "", "", "", []string{"house", "work"})
+	d.CreateThing("b", "", "", "", []string{"house"})
+	d.SaveMemory("c", "observation", "agent", []string{"health"}, nil, "")
+
+	tags, err := d.ListTags()
+	if err != nil {
+		t.Fatalf("ListTags: %v", err)
+	}
+	if strings.Join(tags, ",") != "house,health,work" {
+		t.Errorf("expected tags by usage then name, got %v", tags)
+	}
+}
+
 func TestGetSetNote(t *testing.T) {
 	d := openTestDB(t)
 
@@ -238,7 +273,7 @@ func TestListThingsOverdue(t *testing.T) {
 func TestSaveAndSearchMemories(t *testing.T) {
 	d := openTestDB(t)
 
-	id, err := d.SaveMemory("blocked on API review", "blocker", "agent", []string{"api"}, nil, "")
+	id, _, err := d.SaveMemory("blocked on API review", "blocker", "agent", []string{"api"}, nil, "")
 	if err != nil {
 		t.Fatalf("SaveMemory: %v", err)
 	}
@@ -353,6 +388,249 @@ func TestListRecentMemoriesFilterByCategory(t *testing.T) {
 	}
 }
 
+// --- Memory Dedup ---
+
+func TestSaveMemoryDedup(t *testing.T) {
+	tests := []struct {
+		name     string
+		first    string
+		second   string
+		category string
+		wantDup  bool
+	}{
+		{"exact", "Rent is due on the 1st", "Rent is due on the 1st", "observation", true},
+		{"case and punctuation", "Rent is due on the 1st.", "rent is DUE on the 1st", "observation", true},
+		{"near duplicate", "the landlord said the plumber comes thursday morning at nine", "the landlord said the plumber comes thursday morning around nine", "observation", true},
+		{"different content", "blocked on API review", "blocked on API review from the platform team", "blocker", false},
+		{"habit logs repeat", "gym: done", "gym: done", "habit", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := openTestDB(t)
+			id1, dup, err := d.SaveMemory(tt.first, tt.category, "agent", nil, nil, "")
+			if err != nil || dup {
+				t.Fatalf("first SaveMemory: dup=%v err=%v", dup, err)
+			}
+			id2, dup, err := d.SaveMemory(tt.second, tt.category, "agent", nil, nil, "")
+			if err != nil {
+				t.Fatalf("second SaveMemory: %v", err)
+			}
+			if dup != tt.wantDup {
+				t.Errorf("expected duplicate=%v, got %v", tt.wantDup, dup)
+			}
+			if tt.wantDup && id2 != id1 {
+				t.Errorf("expected existing ID %d, got %d", id1, id2)
+			}
+			memories, _ := d.ListRecentMemories("", 10)
+			wantCount := 2
+			if tt.wantDup {
+				wantCount = 1
+			}
+			if len(memories) != wantCount {
+				t.Errorf("expected %d stored memories, got %d", wantCount, len(memories))
+			}
+		})
+	}
+}
+
+func TestSaveMemoryDedupWindow(t *testing.T) {
+	d := openTestDB(t)
+
+	old := time.Now().UTC().Add(-48 * time.Hour).Format(time.DateTime)
+	d.conn.Exec(`INSERT INTO memories (content, category, source, created_at) VALUES (?, ?, ?, ?)`,
+		"weekly sync moved to fridays", "event", "agent", old)
+
+	_, dup, err := d.SaveMemory("weekly sync moved to fridays", "event", "agent", nil, nil, "")
+	if err != nil {
+		t.Fatalf("SaveMemory: %v", err)
+	}
+	if dup {
+		t.Error("memory outside the dedup window should not count as a duplicate")
+	}
+}
+
+func TestMemoryCategoryChecked(t *testing.T) {
+	d := openTestDB(t)
+
+	if _, _, err := d.SaveMemory("call the bank", "todo", "agent", nil, nil, ""); err == nil {
+		t.Error("expected SaveMemory to reject an unknown category")
+	}
+	if _, err := d.BulkSaveMemories([]NewMemory{{Content: "call the bank", Category: "todo", Source: "agent"}}); err == nil {
+		t.Error("expected BulkSaveMemories to reject an unknown category")
+	}
+	id, _, _ := d.SaveMemory("call the bank", "observation", "agent", nil, nil, "")
+	if err := d.UpdateMemory(id, map[string]any{"category": "todo"}); err == nil {
+		t.Error("expected UpdateMemory to reject an unknown category")
+	}
+	if err := d.UpdateMemory(id, map[string]any{"category": "decision"}); err != nil {
+		t.Errorf("UpdateMemory with a valid category: %v", err)
+	}
+	if mems, _ := d.ListRecentMemories("", 10); len(mems) != 1 || mems[0].Category != "decision" {
+		t.Errorf("expected one decision memory, got %+v", mems)
+	}
+}
+
+func TestSaveMemoryDedupScope(t *testing.T) {
+	d := openTestDB(t)
+	thing, _ := d.CreateThing("Kitchen remodel", "", "", "", nil)
+	other, _ := d.CreateThing("Bathroom remodel", "", "", "", nil)
+	content := "waiting on the contractor quote"
+	first, _, _ := d.SaveMemory(content, "blocker", "agent", nil, &thing, "")
+
+	tests := []struct {
+		name    string
+		mem     NewMemory
+		wantDup bool
+	}{
+		{"same scope", NewMemory{Content: content, Category: "blocker", Source: "agent", ThingID: &thing}, true},
+		{"other category", NewMemory{Content: content, Category: "observation", Source: "agent", ThingID: &thing}, false},
+		{"other thing", NewMemory{Content: content, Category: "blocker", Source: "agent", ThingID: &other}, false},
+		{"no thing", NewMemory{Content: content, Category: "blocker", Source: "agent"}, false},
+		{"proposed", NewMemory{Content: content, Category: "blocker", Source: "agent", ThingID: &thing, Status: "proposed"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, dup, err := insertMemory(d.conn, tt.mem)
+			if err != nil {
+				t.Fatalf("insertMemory: %v", err)
+			}
+			if dup != tt.wantDup || (dup && id != first) {
+				t.Errorf("got id=%d dup=%v, want dup=%v of %d", id, dup, tt.wantDup, first)
+			}
+			if !dup {
+				d.conn.Exec(`DELETE FROM memories WHERE id = ?`, id)
+			}
+		})
+	}
+
+	// An expired memory doesn't count.
+	d.conn.Exec(`UPDATE memories SET expires_at = datetime('now', '-1 hour') WHERE id = ?`, first)
+	if _, dup, _ := d.SaveMemory(content, "blocker", "agent", nil, &thing, ""); dup {
+		t.Error("expired memory should not count as a duplicate")
+	}
+}
+
+func TestBulkSaveMemories(t *testing.T) {
+	d := openTestDB(t)
+	d.SaveMemory("decided to switch the team to weekly planning", "decision", "agent", nil, nil, "")
+
+	saved, err := d.BulkSaveMemories([]NewMemory{
+		{Content: "dentist moved to thursday", Category: "event", Source: "import"},
+		{Content: "decided to switch the team to weekly planning", Category: "decision", Source: "import"},
+		{Content: "Dentist moved to Thursday!", Category: "event", Source: "import"},
+		{Content: "old note", Category: "observation", Source: "import", CreatedAt: "2024-01-02 03:04:05", Tags: []string{"archive"}},
+	})
+	if err != nil {
+		t.Fatalf("BulkSaveMemories: %v", err)
+	}
+	if len(saved) != 4 || saved[0].Duplicate || !saved[1].Duplicate || !saved[2].Duplicate || saved[2].ID != saved[0].ID {
+		t.Fatalf("saved = %+v, want the 2nd and 3rd as duplicates (the 3rd of the 1st)", saved)
+	}
+	mems, _ := d.SearchMemories("old note", "", "", nil, "", 5)
+	if len(mems) != 1 || mems[0].CreatedAt != "2024-01-02 03:04:05" || len(mems[0].Tags) != 1 {
+		t.Errorf("expected the import's original time and tags, got %+v", mems)
+	}
+}
+
+func TestBulkLogHabits(t *testing.T) {
+	d := openTestDB(t)
+	ids, err := d.BulkLogHabits([]HabitLog{
+		{Habit: "Gym", At: "2026-03-01 08:00:00"},
+		{Habit: "gym", At: "2026-03-02 08:00:00"},
+		{Habit: "read", Note: "read 30 pages"},
+	})
+	if err != nil {
+		t.Fatalf("BulkLogHabits: %v", err)
+	}
+	if len(ids) != 3 {
+		t.Fatalf("expected 3 logs (habits repeat), got %d", len(ids))
+	}
+	gym, _ := d.SearchMemories("", "habit", "gym", nil, "", 10)
+	if len(gym) != 2 || gym[0].Content != "gym" || gym[0].Source != "import" {
+		t.Errorf("gym logs = %+v", gym)
+	}
+
+	// A log without a habit rejects the batch.
+	if _, err := d.BulkLogHabits([]HabitLog{{Habit: "walk"}, {Note: "no habit"}}); err == nil {
+		t.Error("expected an error for a log without a habit")
+	}
+	if walks, _ := d.SearchMemories("", "habit", "walk", nil, "", 10); len(walks) != 0 {
+		t.Errorf("expected nothing logged from a rejected batch, got %d", len(walks))
+	}
+}
+
+// --- Memory Approval ---
+
+func TestProposedMemoriesHidden(t *testing.T) {
+	d := openTestDB(t)
+
+	d.SaveMemory("approved fact", "observation", "agent", nil, nil, "")
+	id, dup, err := d.ProposeMemory("pending fact", "observation", "agent", nil, nil, "")
+	if err != nil || dup {
+		t.Fatalf("ProposeMemory: dup=%v err=%v", dup, err)
+	}
+
+	recent, _ := d.ListRecentMemories("", 10)
+	if len(recent) != 1 || recent[0].Content != "approved fact" {
+		t.Errorf("expected only the active memory in listings, got %+v", recent)
+	}
+	if results, _ := d.SearchMemories("pending", "", "", nil, "", 10); len(results) != 0 {
+		t.Errorf("expected proposed memory hidden from search, got %d", len(results))
+	}
+
+	proposed, err := d.ListProposedMemories()
+	if err != nil {
+		t.Fatalf("ListProposedMemories: %v", err)
+	}
+	if len(proposed) != 1 || proposed[0].ID != id {
+		t.Fatalf("expected proposed memory %d, got %+v", id, proposed)
+	}
+	if n, _ := d.CountProposedMemories(); n != 1 {
+		t.Errorf("expected 1 proposed, got %d", n)
+	}
+}
+
+func TestApproveAndRejectMemories(t *testing.T) {
+	d := openTestDB(t)
+
+	keep, _, _ := d.ProposeMemory("keep this one", "observation", "agent", nil, nil, "")
+	drop, _, _ := d.ProposeMemory("drop that one", "observation", "agent", nil, nil, "")
+	active, _, _ := d.SaveMemory("already active", "observation", "agent", nil, nil, "")
+
+	n, err := d.ApproveMemories([]int64{keep})
+	if err != nil || n != 1 {
+		t.Fatalf("ApproveMemories: n=%d err=%v", n, err)
+	}
+	// Rejecting an active memory is a no-op.
+	n, err = d.RejectMemories([]int64{drop, active})
+	if err != nil || n != 1 {
+		t.Fatalf("RejectMemories: n=%d err=%v", n, err)
+	}
+
+	recent, _ := d.ListRecentMemories("", 10)
+	if len(recent) != 2 {
+		t.Fatalf("expected 2 active memories, got %d", len(recent))
+	}
+	if n, _ := d.CountProposedMemories(); n != 0 {
+		t.Errorf("expected no proposed memories left, got %d", n)
+	}
+}
+
+func TestApproveAllMemories(t *testing.T) {
+	d := openTestDB(t)
+
+	d.ProposeMemory("first pending", "observation", "agent", nil, nil, "")
+	d.ProposeMemory("second pending", "decision", "agent", nil, nil, "")
+
+	n, err := d.ApproveMemories(nil)
+	if err != nil {
+		t.Fatalf("ApproveMemories(all): %v", err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 approved, got %d", n)
+	}
+}
+
 // --- FTS Search ---
 
 func TestSearchMemoriesFTS(t *testing.T) {
@@ -407,7 +685,7 @@ func TestFTSSyncOnInsert(t *testing.T) {
 func TestFTSSyncOnDelete(t *testing.T) {
 	d := openTestDB(t)
 
-	id, _ := d.SaveMemory("ephemeral thought", "observation", "agent", nil, nil, "")
+	id, _, _ := d.SaveMemory("ephemeral thought", "observation", "agent", nil, nil, "")
 
 	// Should find it
 	results, _ := d.SearchMemories("ephemeral", "", "", nil, "", 10)
@@ -431,7 +709,7 @@ func TestFTSSyncOnDelete(t *testing.T) {
 func TestUpdateMemory(t *testing.T) {
 	d := openTestDB(t)
 
-	id, _ := d.SaveMemory("original content", "observation", "agent", []string{"tag1"}, nil, "")
+	id, _, _ := d.SaveMemory("original content", "observation", "agent", []string{"tag1"}, nil, "")
 
 	err := d.UpdateMemory(id, map[string]any{"content": "updated content", "category": "decision"})
 	if err != nil {
@@ -463,7 +741,7 @@ func TestUpdateMemoryNotFound(t *testing.T) {
 func TestDeleteMemory(t *testing.T) {
 	d := openTestDB(t)
 
-	id, _ := d.SaveMemory("to be deleted", "observation", "agent", nil, nil, "")
+	id, _, _ := d.SaveMemory("to be deleted", "observation", "agent", nil, nil, "")
 
 	err := d.DeleteMemory(id)
 	if err != nil {
@@ -486,7 +764,7 @@ func TestDeleteMemory(t *testing.T) {
 func TestResolveMemory(t *testing.T) {
 	d := openTestDB(t)
 
-	id, _ := d.SaveMemory("blocked on code review", "blocker", "agent", nil, nil, "")
+	id, _, _ := d.SaveMemory("blocked on code review", "blocker", "agent", nil, nil, "")
 
 	err := d.ResolveMemory(id, "review completed by Sarah")
 	if err != nil {
@@ -506,6 +784,108 @@ func TestResolveMemory(t *testing.T) {
 	}
 }
 
+func TestGetMemoryStats(t *testing.T) {
+	d := openTestDB(t)
+
+	d.SaveMemory("prefers mornings for deep work", "preference", "agent", nil, nil, "")
+	d.SaveMemory("chose Postgres over MySQL", "decision", "agent", nil, nil, "")
+	d.SaveMemory("decided to skip the conference", "decision", "agent", nil, nil, "")
+	blocker, _, _ := d.SaveMemory("waiting on legal sign-off", "blocker", "agent", nil, nil, "")
+	d.conn.Exec("UPDATE memories SET created_at = datetime('now', '-9 days') WHERE id = ?", blocker)
+	resolved, _, _ := d.SaveMemory("blocked on VPN access", "blocker", "agent", nil, nil, "")
+	d.ResolveMemory(resolved, "IT fixed it")
+	d.SaveMemory("dentist moved to Friday", "event", "agent", nil, nil, "2099-01-01 00:00:00")
+	d.SaveMemory("old news", "event", "agent", nil, nil, "2000-01-01 00:00:00")
+	d.ProposeMemory("might like tea", "preference", "agent", nil, nil, "")
+
+	s, err := d.GetMemoryStats()
+	if err != nil {
+		t.Fatalf("GetMemoryStats: %v", err)
+	}
+	if s.Total != 6 || s.ByCategory["decision"] != 2 || s.ByCategory["blocker"] != 1 || s.ByCategory["resolved"] != 1 {
+		t.Errorf("unexpected counts: %+v", s)
+	}
+	if s.Proposed != 1 || s.Expiring != 1 {
+		t.Errorf("expected 1 proposed and 1 expiring, got %d and %d", s.Proposed, s.Expiring)
+	}
+	if s.UnresolvedBlockers != 1 || s.OldestBlockerDays != 9 {
+		t.Errorf("expected 1 unresolved blocker, 9 days old, got %d and %d", s.UnresolvedBlockers, s.OldestBlockerDays)
+	}
+	want := "6 memories: 2 decision, 1 blocker (oldest 9d), 1 event, 1 preference, 1 resolved; 1 expiring; 1 proposed"
+	if got := s.Line(); got != want {
+		t.Errorf("Line() = %q, want %q", got, want)
+	}
+}
+
+func TestListAgingBlockers(t *testing.T) {
+	d := openTestDB(t)
+
+	age := func(id int64, days int) {
+		d.conn.Exec("UPDATE memories SET created_at = datetime('now', ?) WHERE id = ?", fmt.Sprintf("-%d days", days), id)
+	}
+	old, _, _ := d.SaveMemory("API review blocked on security team", "blocker", "agent", nil, nil, "")
+	age(old, 12)
+	fresh, _, _ := d.SaveMemory("waiting on a quote", "blocker", "agent", nil, nil, "")
+	age(fresh, 2)
+	resolved, _, _ := d.SaveMemory("blocked on VPN access", "blocker", "agent", nil, nil, "")
+	age(resolved, 20)
+	d.ResolveMemory(resolved, "IT fixed it")
+	thingID, _ := d.CreateThing("Migrate billing", "", "", "", nil)
+	linked, _, _ := d.SaveMemory("billing migration blocked on vendor", "blocker", "agent", nil, &thingID, "")
+	age(linked, 30)
+	d.CompleteThing(thingID)
+
+	blockers, err := d.ListAgingBlockers(7)
+	if err != nil {
+		t.Fatalf("ListAgingBlockers: %v", err)
+	}
+	if len(blockers) != 1 || blockers[0].ID != old {
+		t.Fatalf("expected only the 12-day blocker, got %+v", blockers)
+	}
+	if blockers[0].AgeDays != 12 {
+		t.Errorf("expected age 12 days, got %d", blockers[0].AgeDays)
+	}
+	s, _ := d.GetMemoryStats()
+	if s.ByCategory["blocker"] != 3 || s.UnresolvedBlockers != 2 || s.OldestBlockerDays != 12 {
+		t.Errorf("expected 3 blockers, 2 unresolved, oldest 12 days; got %+v", s)
+	}
+	if want := "4 memories: 3 blocker (2 unresolved, oldest 12d), 1 resolved"; s.Line() != want {
+		t.Errorf("Line() = %q, want %q", s.Line(), want)
+	}
+}
+
+func TestGetRecentMemoriesForCheckIn(t *testing.T) {
+	d := openTestDB(t)
+
+	age := func(id int64, days int) {
+		d.conn.Exec("UPDATE memories SET created_at = datetime('now', ?) WHERE id = ?", fmt.Sprintf("-%d days", days), id)
+	}
+	decision, _, _ := d.SaveMemory("moving the launch to Q3", "decision", "agent", nil, nil, "")
+	age(decision, 20)
+	for i, c := range []string{"standup ran long", "coffee machine broken", "new desk arrived"} {
+		id, _, _ := d.SaveMemory(c, "observation", "agent", nil, nil, "")
+		age(id, i+1)
+	}
+	gym, _, _ := d.SaveMemory("gym: done", "habit", "agent", nil, nil, "")
+	age(gym, 1)
+
+	week, err := d.GetRecentMemoriesForCheckIn(0, 0)
+	if err != nil {
+		t.Fatalf("GetRecentMemoriesForCheckIn: %v", err)
+	}
+	if len(week) != 4 {
+		t.Fatalf("expected 4 memories in the default 7-day window, got %d", len(week))
+	}
+	if week[0].Category != "observation" || week[len(week)-1].Category != "habit" {
+		t.Errorf("expected habits ranked below observations, got %+v", week)
+	}
+
+	month, _ := d.GetRecentMemoriesForCheckIn(31, 2)
+	if len(month) != 2 || month[0].ID != decision {
+		t.Errorf("expected the month-old decision to outrank recent observations, got %+v", month)
+	}
+}
+
 // --- Schedules ---
 
 func TestCreateAndListSchedules(t *testing.T) {
@@ -538,6 +918,27 @@ func TestCreateAndListSchedules(t *testing.T) {
 	}
 }
 
+func TestScheduleCheckIn(t *testing.T) {
+	d := openTestDB(t)
+
+	id, _ := d.CreateSchedule("morning", "0 9 * * *", "Check in.")
+	if s, _ := d.GetScheduleByName("morning"); s == nil || s.CheckIn {
+		t.Fatalf("expected check_in off by default, got %+v", s)
+	}
+	if err := d.UpdateSchedule(id, map[string]any{"check_in": 1}); err != nil {
+		t.Fatalf("UpdateSchedule: %v", err)
+	}
+	if s, _ := d.GetScheduleByName("morning"); s == nil || !s.CheckIn {
+		t.Errorf("expected check_in on after update, got %+v", s)
+	}
+	if err := d.UpdateSchedule(id, map[string]any{"memory_days": float64(31), "memory_limit": 40}); err != nil {
+		t.Fatalf("UpdateSchedule: %v", err)
+	}
+	if s, _ := d.GetScheduleByName("morning"); s.MemoryDays != 31 || s.MemoryLimit != 40 {
+		t.Errorf("expected 31-day window of 40 memories, got %+v", s)
+	}
+}
+
 func TestCreateScheduleDuplicateName(t *testing.T) {
 	d := openTestDB(t)
 
@@ -597,6 +998,47 @@ func TestRecordScheduleRun(t *testing.T) {
 	}
 }
 
+func TestScheduleRunLog(t *testing.T) {
+	d := openTestDB(t)
+
+	if err := d.LogScheduleRun(Schedule{Name: "morning-checkin", CheckIn: true}, "Morning!"); err != nil {
+		t.Fatalf("LogScheduleRun: %v", err)
+	}
+	if err := d.LogScheduleRun(Schedule{Name: "weekly-review"}, "Week done."); err != nil {
+		t.Fatalf("LogScheduleRun: %v", err)
+	}
+
+	runs, err := d.ListScheduleRuns("", nil)
+	if err != nil {
+		t.Fatalf("ListScheduleRuns: %v", err)
+	}
+	if len(runs) != 2 || runs[0].Output != "Morning!" || !runs[0].CheckIn {
+		t.Errorf("unexpected runs: %+v", runs)
+	}
+	runs, _ = d.ListScheduleRuns("", []string{"weekly-review"})
+	if len(runs) != 1 || runs[0].ScheduleName != "weekly-review" {
+		t.Errorf("expected only weekly-review, got %+v", runs)
+	}
+	if runs, _ = d.ListScheduleRuns("2999-01-01", nil); len(runs) != 0 {
+		t.Errorf("expected no runs in the future, got %+v", runs)
+	}
+
+	// Writing a run past the cap drops that schedule's oldest.
+	for i := 0; i < scheduleRunsKept; i++ {
+		d.conn.Exec(`INSERT INTO schedule_runs (schedule_name, output) VALUES ('hourly', ?)`, fmt.Sprint(i))
+	}
+	if err := d.LogScheduleRun(Schedule{Name: "hourly"}, "newest"); err != nil {
+		t.Fatalf("LogScheduleRun: %v", err)
+	}
+	runs, _ = d.ListScheduleRuns("", []string{"hourly"})
+	if len(runs) != scheduleRunsKept || runs[0].Output != "1" || runs[len(runs)-1].Output != "newest" {
+		t.Errorf("expected the newest %d hourly runs, got %d from %q", scheduleRunsKept, len(runs), runs[0].Output)
+	}
+	if runs, _ = d.ListScheduleRuns("", []string{"morning-checkin", "weekly-review"}); len(runs) != 2 {
+		t.Errorf("expected other schedules' runs kept, got %d", len(runs))
+	}
+}
+
 func TestDeleteSchedule(t *testing.T) {
 	d := openTestDB(t)
 
@@ -745,3 +1187,96 @@ func TestGetScheduleByName(t *testing.T) {
 		t.Errorf("expected nil for nonexistent, got %+v", s)
 	}
 }
+
+func TestCreateThingsRollsBack(t *testing.T) {
+	d := openTestDB(t)
+
+	ids, err := d.CreateThings([]NewThing{{Title: "Step one"}, {Title: "Step two", Priority: "high", Tags: []string{"move"}}})
+	if err != nil {
+		t.Fatalf("CreateThings: %v", err)
+	}
+	if len(ids) != 2 || ids[1] != ids[0]+1 {
+		t.Fatalf("ids = %v, want two sequential IDs", ids)
+	}
+	if th, _ := d.GetThing(ids[1]); th.Priority != "high" || len(th.Tags) != 1 {
+		t.Errorf("second thing = %+v", th)
+	}
+
+	// Make the second insert of the next batch fail; the first must not stick.
+	if _, err := d.conn.Exec(`CREATE TEMP TRIGGER reject_boom BEFORE INSERT ON things
+		WHEN new.title = 'boom' BEGIN SELECT RAISE(ABORT, 'boom'); END`); err != nil {
+		t.Fatalf("creating trigger: %v", err)
+	}
+	if _, err := d.CreateThings([]NewThing{{Title: "Step three"}, {Title: "boom"}}); err == nil {
+		t.Fatal("expected error from failing insert")
+	}
+	if things, _ := d.ListThings("", "", ""); len(things) != 2 {
+		t.Errorf("expected batch to roll back, got %d things", len(things))
+	}
+}
+
+func TestQueryThingsSortAndPage(t *testing.T) {
+	d := openTestDB(t)
+
+	d.CreateThing("First", "", "low", "2026-05-01", nil)
+	d.CreateThing("Second", "", "urgent", "", nil)
+	d.CreateThing("Third", "", "normal", "2026-04-01", nil)
+	d.CreateThing("Fourth", "", "high", "2026-04-15", nil)
+
+	titles := func(things []Thing) string {
+		var out []string
+		for _, th := range things {
+			out = append(out, th.Title)
+		}
+		return strings.Join(out, ",")
+	}
+	tests := []struct {
+		q     ThingQuery
+		want  string
+		total int
+	}{
+		{ThingQuery{}, "Second,Fourth,Third,First", 4},
+		{ThingQuery{Sort: "due_date"}, "Third,Fourth,First,Second", 4},
+		{ThingQuery{Sort: "oldest", Limit: 2}, "First,Second", 4},
+		{ThingQuery{Sort: "newest", Limit: 2, Offset: 1}, "Third,Second", 4},
+		{ThingQuery{Sort: "oldest", Offset: 3}, "Fourth", 4},
+		{ThingQuery{Priority: "low", Limit: 10}, "First", 1},
+	}
+	for _, tt := range tests {
+		got, total, err := d.QueryThings(tt.q)
+		if err != nil {
+			t.Fatalf("QueryThings(%+v): %v", tt.q, err)
+		}
+		if titles(got) != tt.want || total != tt.total {
+			t.Errorf("QueryThings(%+v) = %s (total %d), want %s (total %d)", tt.q, titles(got), total, tt.want, tt.total)
+		}
+	}
+
+	if _, _, err := d.QueryThings(ThingQuery{Sort: "random"}); err == nil {
+		t.Error("expected error for unknown sort")
+	}
+}
+
+func TestListCompletedBetween(t *testing.T) {
+	d := openTestDB(t)
+
+	for title, at := range map[string]string{
+		"Last week":  "2026-03-02 18:00:00",
+		"Monday":     "2026-03-09 08:00:00",
+		"Friday":     "2026-03-13 23:59:59",
+		"Next month": "2026-04-01 09:00:00",
+	} {
+		id, _ := d.CreateThing(title, "", "", "", nil)
+		d.UpdateThing(id, map[string]any{"status": "done", "completed_at": at})
+	}
+	d.CreateThing("Still open", "", "", "", nil)
+
+	from := time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)
+	things, err := d.ListCompletedBetween(from, from.AddDate(0, 0, 7))
+	if err != nil {
+		t.Fatalf("ListCompletedBetween: %v", err)
+	}
+	if len(things) != 2 || things[0].Title != "Friday" || things[1].Title != "Monday" {
+		t.Errorf("expected Friday, Monday; got %+v", things)
+	}
+}
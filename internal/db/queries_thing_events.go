@@ -0,0 +1,160 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+// Due-date escalation windows, in days before the due date.
+const (
+	escalateHighDays   = 3 // due within 3 days → at least high
+	escalateUrgentDays = 1 // due tomorrow, today, or overdue → urgent
+)
+
+var priorityRank = map[string]int{"low": 0, "normal": 1, "high": 2, "urgent": 3}
+
+// EscalateDueThings raises the priority of open and active things as their
+// due date approaches and records an "escalated" event for each change.
+// today is the user's local date (YYYY-MM-DD). Priority never goes down, and
+// updated_at is left alone so automatic changes don't hide staleness.
+func (d *DB) EscalateDueThings(today string) ([]ThingEvent, error) {
+	day, err := time.Parse(time.DateOnly, today)
+	if err != nil {
+		return nil, fmt.Errorf("parsing today %q: %w", today, err)
+	}
+	things, err := d.scanThings(`SELECT id, title, COALESCE(notes,''), status, priority,
+		COALESCE(tags,'[]'), COALESCE(due_date,''), created_at, updated_at,
+		COALESCE(completed_at,'') FROM things
+		WHERE status IN ('open', 'active') AND due_date IS NOT NULL AND due_date != '' AND priority != 'urgent'`)
+	if err != nil {
+		return nil, fmt.Errorf("listing things to escalate: %w", err)
+	}
+
+	var events []ThingEvent
+	for _, t := range things {
+		due, err := time.Parse(time.DateOnly, t.DueDate)
+		if err != nil {
+			continue // malformed due date; nothing to infer
+		}
+		target := ""
+		switch days := int(due.Sub(day).Hours() / 24); {
+		case days <= escalateUrgentDays:
+			target = "urgent"
+		case days <= escalateHighDays:
+			target = "high"
+		}
+		if target == "" || priorityRank[target] <= priorityRank[t.Priority] {
+			continue
+		}
+		if _, err := d.conn.Exec(`UPDATE things SET priority = ? WHERE id = ?`, target, t.ID); err != nil {
+			return events, fmt.Errorf("escalating thing %d: %w", t.ID, err)
+		}
+		d.thingsChanged()
+		detail := fmt.Sprintf("%s → %s (due %s)", t.Priority, target, t.DueDate)
+		e, err := d.recordThingEvent(t.ID, t.Title, "escalated", detail)
+		if err != nil {
+			return events, err
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+// FlagStaleThings records a "stale" event for each active thing not updated
+// in olderThanDays. A thing is flagged once per idle stretch: updating it
+// resets the clock.
+func (d *DB) FlagStaleThings(olderThanDays int) ([]ThingEvent, error) {
+	rows, err := d.conn.Query(`SELECT t.id, t.title, t.updated_at FROM things t
+		WHERE t.status = 'active'
+		  AND t.updated_at < datetime('now', ?)
+		  AND NOT EXISTS (
+			SELECT 1 FROM thing_events e
+			WHERE e.thing_id = t.id AND e.kind = 'stale' AND e.created_at >= t.updated_at
+		  )`,
+		fmt.Sprintf("-%d days", olderThanDays),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("listing stale things: %w", err)
+	}
+	type stale struct {
+		id             int64
+		title, updated string
+	}
+	var found []stale
+	for rows.Next() {
+		var s stale
+		if err := rows.Scan(&s.id, &s.title, &s.updated); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scanning stale thing: %w", err)
+		}
+		found = append(found, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var events []ThingEvent
+	for _, s := range found {
+		e, err := d.recordThingEvent(s.id, s.title, "stale", "active, untouched since "+s.updated)
+		if err != nil {
+			return events, err
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+// ListThingEvents returns events from the last sinceDays days, newest first,
+// optionally filtered by kind.
+func (d *DB) ListThingEvents(sinceDays int, kind string) ([]ThingEvent, error) {
+	if sinceDays <= 0 {
+		sinceDays = 7
+	}
+	q := `SELECT e.id, e.thing_id, t.title, e.kind, e.detail, e.created_at
+		FROM thing_events e JOIN things t ON t.id = e.thing_id
+		WHERE e.created_at > datetime('now', ?)`
+	args := []any{fmt.Sprintf("-%d days", sinceDays)}
+	if kind != "" {
+		q += " AND e.kind = ?"
+		args = append(args, kind)
+	}
+	q += " ORDER BY e.created_at DESC, e.id DESC"
+	return d.scanThingEvents(q, args...)
+}
+
+func (d *DB) scanThingEvents(query string, args ...any) ([]ThingEvent, error) {
+	rows, err := d.conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing thing events: %w", err)
+	}
+	defer rows.Close()
+	var events []ThingEvent
+	for rows.Next() {
+		var e ThingEvent
+		if err := rows.Scan(&e.ID, &e.ThingID, &e.Title, &e.Kind, &e.Detail, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning thing event: %w", err)
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// PruneThingEvents deletes events older than the given number of days.
+func (d *DB) PruneThingEvents(olderThanDays int) (int64, error) {
+	res, err := d.conn.Exec(`DELETE FROM thing_events WHERE created_at < datetime('now', ?)`,
+		fmt.Sprintf("-%d days", olderThanDays))
+	if err != nil {
+		return 0, fmt.Errorf("pruning thing events: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+func (d *DB) recordThingEvent(thingID int64, title, kind, detail string) (ThingEvent, error) {
+	res, err := d.conn.Exec(`INSERT INTO thing_events (thing_id, kind, detail) VALUES (?, ?, ?)`, thingID, kind, detail)
+	if err != nil {
+		return ThingEvent{}, fmt.Errorf("recording %s event for thing %d: %w", kind, thingID, err)
+	}
+	id, _ := res.LastInsertId()
+	return ThingEvent{ID: id, ThingID: thingID, Title: title, Kind: kind, Detail: detail}, nil
+}
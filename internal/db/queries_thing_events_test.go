@@ -0,0 +1,90 @@
+package db
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEscalateDueThings(t *testing.T) {
+	d := openTestDB(t)
+
+	overdue, _ := d.CreateThing("Pay rent", "", "normal", "2026-03-01", nil)
+	soon, _ := d.CreateThing("Book flights", "", "low", "2026-03-12", nil)
+	d.CreateThing("Plan trip", "", "normal", "2026-04-30", nil)
+	d.CreateThing("Already urgent", "", "urgent", "2026-03-10", nil)
+	d.CreateThing("High stays high", "", "high", "2026-03-12", nil)
+	done, _ := d.CreateThing("Done thing", "", "low", "2026-03-10", nil)
+	d.CompleteThing(done)
+
+	events, err := d.EscalateDueThings("2026-03-10")
+	if err != nil {
+		t.Fatalf("EscalateDueThings: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 escalations, got %d: %+v", len(events), events)
+	}
+
+	if th, _ := d.GetThing(overdue); th.Priority != "urgent" {
+		t.Errorf("overdue thing priority = %s, want urgent", th.Priority)
+	}
+	if th, _ := d.GetThing(soon); th.Priority != "high" {
+		t.Errorf("due-soon thing priority = %s, want high", th.Priority)
+	}
+
+	// Running again is a no-op.
+	if again, _ := d.EscalateDueThings("2026-03-10"); len(again) != 0 {
+		t.Errorf("expected no further escalations, got %d", len(again))
+	}
+
+	listed, err := d.ListThingEvents(1, "escalated")
+	if err != nil {
+		t.Fatalf("ListThingEvents: %v", err)
+	}
+	if len(listed) != 2 || !strings.Contains(listed[0].Detail+listed[1].Detail, "low → high") {
+		t.Errorf("unexpected events: %+v", listed)
+	}
+
+	if _, err := d.EscalateDueThings("not-a-date"); err == nil {
+		t.Error("expected error for invalid date")
+	}
+}
+
+func TestFlagStaleThings(t *testing.T) {
+	d := openTestDB(t)
+
+	stale, _ := d.CreateThing("Write report", "", "", "", nil)
+	fresh, _ := d.CreateThing("Fresh work", "", "", "", nil)
+	d.UpdateThing(stale, map[string]any{"status": "active"})
+	d.UpdateThing(fresh, map[string]any{"status": "active"})
+	d.conn.Exec("UPDATE things SET updated_at = datetime('now', '-20 days') WHERE id = ?", stale)
+
+	events, err := d.FlagStaleThings(14)
+	if err != nil {
+		t.Fatalf("FlagStaleThings: %v", err)
+	}
+	if len(events) != 1 || events[0].ThingID != stale {
+		t.Fatalf("expected stale flag on %d, got %+v", stale, events)
+	}
+
+	// Flagged once per idle stretch.
+	if again, _ := d.FlagStaleThings(14); len(again) != 0 {
+		t.Errorf("expected no repeat flag, got %d", len(again))
+	}
+}
+
+func TestThingEventsCascadeOnDelete(t *testing.T) {
+	d := openTestDB(t)
+
+	id, _ := d.CreateThing("Pay rent", "", "normal", "2026-03-01", nil)
+	d.EscalateDueThings("2026-03-10")
+
+	c, _ := d.FindPurgeCandidates("rent")
+	if _, err := d.Purge(c); err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	var n int
+	d.conn.QueryRow("SELECT COUNT(*) FROM thing_events WHERE thing_id = ?", id).Scan(&n)
+	if n != 0 {
+		t.Errorf("expected events deleted with thing, got %d", n)
+	}
+}
@@ -3,45 +3,169 @@ package db
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 )
 
 // ListThings returns things, optionally filtered by status, priority, or tag.
 // Each thing with a due_date in the past (and not done/dropped) is marked Overdue.
 func (d *DB) ListThings(status, priority, tag string) ([]Thing, error) {
+	things, _, err := d.QueryThings(ThingQuery{Status: status, Priority: priority, Tag: tag})
+	return things, err
+}
+
+// ThingQuery filters, sorts, and pages QueryThings.
+type ThingQuery struct {
+	Status   string
+	Priority string
+	Tag      string
+	AnyTags  []string // things with at least one of these tags
+	DueBy    string   // due on or before this date (YYYY-MM-DD), overdue included
+	Sort     string   // priority (default), due_date, newest, oldest
+	Limit    int      // 0 = no limit
+	Offset   int
+}
+
+// thingSorts maps ThingQuery.Sort to ORDER BY clauses. Things without a due
+// date sort after those with one.
+var thingSorts = map[string]string{
+	"priority": "CASE priority WHEN 'urgent' THEN 0 WHEN 'high' THEN 1 WHEN 'normal' THEN 2 WHEN 'low' THEN 3 END, updated_at DESC",
+	"due_date": "COALESCE(due_date,'') = '', due_date, CASE priority WHEN 'urgent' THEN 0 WHEN 'high' THEN 1 WHEN 'normal' THEN 2 WHEN 'low' THEN 3 END",
+	"newest":   "created_at DESC, id DESC",
+	"oldest":   "created_at, id",
+}
+
+// QueryThings returns the page of things matching q and the total number of
+// matches before paging.
+func (d *DB) QueryThings(q ThingQuery) ([]Thing, int, error) {
+	order, ok := thingSorts[q.Sort]
+	if q.Sort == "" {
+		order, ok = thingSorts["priority"], true
+	}
+	if !ok {
+		return nil, 0, fmt.Errorf("unknown sort %q (use priority, due_date, newest, or oldest)", q.Sort)
+	}
+
+	where := " WHERE 1=1"
+	var args []any
+	if q.Status != "" {
+		where += " AND status = ?"
+		args = append(args, q.Status)
+	}
+	if q.Priority != "" {
+		where += " AND priority = ?"
+		args = append(args, q.Priority)
+	}
+	if q.Tag != "" {
+		where += " AND tags LIKE ?"
+		args = append(args, "%\""+q.Tag+"\"%")
+	}
+	if len(q.AnyTags) > 0 {
+		var ors []string
+		for _, tag := range q.AnyTags {
+			ors = append(ors, "tags LIKE ?")
+			args = append(args, "%\""+tag+"\"%")
+		}
+		where += " AND (" + strings.Join(ors, " OR ") + ")"
+	}
+	if q.DueBy != "" {
+		where += " AND COALESCE(due_date,'') != '' AND due_date <= ?"
+		args = append(args, q.DueBy)
+	}
+
+	var total int
+	if err := d.conn.QueryRow("SELECT COUNT(*) FROM things"+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("counting things: %w", err)
+	}
+
 	query := `SELECT id, title, COALESCE(notes,''), status, priority,
 		COALESCE(tags,'[]'), COALESCE(due_date,''), created_at, updated_at,
-		COALESCE(completed_at,'') FROM things WHERE 1=1`
-	var args []any
-	if status != "" {
-		query += " AND status = ?"
-		args = append(args, status)
+		COALESCE(completed_at,'') FROM things` + where + " ORDER BY " + order
+	if q.Limit > 0 || q.Offset > 0 {
+		limit := q.Limit
+		if limit <= 0 {
+			limit = -1 // SQLite: no limit
+		}
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, limit, q.Offset)
 	}
-	if priority != "" {
-		query += " AND priority = ?"
-		args = append(args, priority)
+	things, err := d.scanThings(query, args...)
+	return things, total, err
+}
+
+// GetThing returns a thing by ID, or nil if not found.
+func (d *DB) GetThing(id int64) (*Thing, error) {
+	things, err := d.scanThings(`SELECT id, title, COALESCE(notes,''), status, priority,
+		COALESCE(tags,'[]'), COALESCE(due_date,''), created_at, updated_at,
+		COALESCE(completed_at,'') FROM things WHERE id = ?`, id)
+	if err != nil {
+		return nil, fmt.Errorf("getting thing %d: %w", id, err)
 	}
-	if tag != "" {
-		query += " AND tags LIKE ?"
-		args = append(args, "%\""+tag+"\"%")
+	if len(things) == 0 {
+		return nil, nil
 	}
-	query += " ORDER BY CASE priority WHEN 'urgent' THEN 0 WHEN 'high' THEN 1 WHEN 'normal' THEN 2 WHEN 'low' THEN 3 END, updated_at DESC"
-	return d.scanThings(query, args...)
+	return &things[0], nil
 }
 
 // CreateThing creates a new thing and returns its ID.
 func (d *DB) CreateThing(title, notes, priority, dueDate string, tags []string) (int64, error) {
-	if priority == "" {
-		priority = "normal"
+	id, err := insertThing(d.conn, NewThing{Title: title, Notes: notes, Priority: priority, DueDate: dueDate, Tags: tags})
+	if err != nil {
+		return 0, err
+	}
+	d.thingsChanged()
+	return id, nil
+}
+
+// CreateThings creates several things in one transaction and returns their
+// IDs in order. If any insert fails, none are created.
+func (d *DB) CreateThings(things []NewThing) ([]int64, error) {
+	tx, err := d.conn.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("beginning create things: %w", err)
+	}
+	defer tx.Rollback()
+
+	ids, err := insertThings(tx, things)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("committing things: %w", err)
+	}
+	d.thingsChanged()
+	return ids, nil
+}
+
+func insertThings(x execer, things []NewThing) ([]int64, error) {
+	ids := make([]int64, 0, len(things))
+	for i, t := range things {
+		id, err := insertThing(x, t)
+		if err != nil {
+			return nil, fmt.Errorf("thing %d (%q): %w", i+1, t.Title, err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func insertThing(x execer, t NewThing) (int64, error) {
+	if t.Priority == "" {
+		t.Priority = "normal"
 	}
 	var tagsJSON string
-	if len(tags) > 0 {
-		b, _ := json.Marshal(tags)
+	if len(t.Tags) > 0 {
+		b, _ := json.Marshal(t.Tags)
 		tagsJSON = string(b)
 	}
-	res, err := d.conn.Exec(
-		"INSERT INTO things (title, notes, priority, due_date, tags) VALUES (?, ?, ?, ?, ?)",
-		title, nullStr(notes), priority, nullStr(dueDate), nullStr(tagsJSON),
+	if t.Status == "" {
+		t.Status = "open"
+	}
+	res, err := x.Exec(
+		`INSERT INTO things (title, notes, status, priority, due_date, tags, created_at, updated_at, completed_at)
+		VALUES (?, ?, ?, ?, ?, ?, COALESCE(?, datetime('now')), COALESCE(?, datetime('now')), ?)`,
+		t.Title, nullStr(t.Notes), t.Status, t.Priority, nullStr(t.DueDate), nullStr(tagsJSON),
+		nullStr(t.CreatedAt), nullStr(t.CreatedAt), nullStr(t.CompletedAt),
 	)
 	if err != nil {
 		return 0, fmt.Errorf("creating thing: %w", err)
@@ -51,7 +175,11 @@ func (d *DB) CreateThing(title, notes, priority, dueDate string, tags []string)
 
 // UpdateThing updates fields on a thing by ID.
 func (d *DB) UpdateThing(id int64, fields map[string]any) error {
-	return d.updateRow("things", id, fields)
+	if err := d.updateRow("things", id, fields); err != nil {
+		return err
+	}
+	d.thingsChanged()
+	return nil
 }
 
 // CompleteThing marks a thing as done.
@@ -64,10 +192,27 @@ func (d *DB) CompleteThing(id int64) error {
 	if err != nil {
 		return fmt.Errorf("completing thing: %w", err)
 	}
+	d.thingsChanged()
 	return nil
 }
 
 
+// ListCompletedBetween returns things completed in [from, to), most recent
+// first. completed_at is stored in UTC, so callers pass instants (e.g. the
+// user's local midnights) rather than dates.
+func (d *DB) ListCompletedBetween(from, to time.Time) ([]Thing, error) {
+	things, err := d.scanThings(`SELECT id, title, COALESCE(notes,''), status, priority,
+		COALESCE(tags,'[]'), COALESCE(due_date,''), created_at, updated_at,
+		COALESCE(completed_at,'') FROM things
+		WHERE status = 'done' AND completed_at >= ? AND completed_at < ?
+		ORDER BY completed_at DESC, id DESC`,
+		from.UTC().Format(time.DateTime), to.UTC().Format(time.DateTime))
+	if err != nil {
+		return nil, fmt.Errorf("listing completed things: %w", err)
+	}
+	return things, nil
+}
+
 func (d *DB) scanThings(query string, args ...any) ([]Thing, error) {
 	rows, err := d.conn.Query(query, args...)
 	if err != nil {
@@ -82,7 +227,7 @@ func (d *DB) scanThings(query string, args ...any) ([]Thing, error) {
 		if err := rows.Scan(&t.ID, &t.Title, &t.Notes, &t.Status, &t.Priority, &tagsJSON, &t.DueDate, &t.CreatedAt, &t.UpdatedAt, &t.CompletedAt); err != nil {
 			return nil, fmt.Errorf("scanning thing: %w", err)
 		}
-		_ = json.Unmarshal([]byte(tagsJSON), &t.Tags)
+		t.Tags = decodeTags(tagsJSON)
 		if t.DueDate != "" && t.DueDate < now && t.Status != "done" && t.Status != "dropped" {
 			t.Overdue = true
 		}
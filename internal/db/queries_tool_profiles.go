@@ -0,0 +1,98 @@
+package db
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ToolDenial hides tools matching Pattern from the agent when it runs under
+// Profile (an entry point such as "discord", "cli", or "schedule").
+type ToolDenial struct {
+	Profile   string `json:"profile"`
+	Pattern   string `json:"pattern"`
+	CreatedAt string `json:"created_at"`
+}
+
+// defaultToolDenials keeps unattended runs (schedules and background jobs)
+// from deleting anything.
+var defaultToolDenials = map[string][]string{
+	"schedule": {"delete_*", "forget"},
+	"job":      {"delete_*", "forget"},
+}
+
+// DenyTool hides tools matching pattern (a tool name or path.Match glob)
+// from profile. Denying an existing pattern again is a no-op.
+func (d *DB) DenyTool(profile, pattern string) error {
+	profile, pattern = strings.ToLower(strings.TrimSpace(profile)), strings.TrimSpace(pattern)
+	if profile == "" || pattern == "" {
+		return fmt.Errorf("profile and pattern are required")
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid tool pattern %q: %w", pattern, err)
+	}
+	if _, err := d.conn.Exec(`INSERT OR IGNORE INTO tool_profiles (profile, pattern) VALUES (?, ?)`, profile, pattern); err != nil {
+		return fmt.Errorf("denying %s for %s: %w", pattern, profile, err)
+	}
+	return nil
+}
+
+// AllowTool removes a denial, reporting whether it existed.
+func (d *DB) AllowTool(profile, pattern string) (bool, error) {
+	res, err := d.conn.Exec(`DELETE FROM tool_profiles WHERE profile = ? AND pattern = ?`,
+		strings.ToLower(strings.TrimSpace(profile)), strings.TrimSpace(pattern))
+	if err != nil {
+		return false, fmt.Errorf("allowing %s for %s: %w", pattern, profile, err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// ListToolDenials returns every denial by profile and pattern.
+func (d *DB) ListToolDenials() ([]ToolDenial, error) {
+	rows, err := d.conn.Query(`SELECT profile, pattern, created_at FROM tool_profiles ORDER BY profile, pattern`)
+	if err != nil {
+		return nil, fmt.Errorf("listing tool denials: %w", err)
+	}
+	defer rows.Close()
+	var denials []ToolDenial
+	for rows.Next() {
+		var t ToolDenial
+		if err := rows.Scan(&t.Profile, &t.Pattern, &t.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning tool denial: %w", err)
+		}
+		denials = append(denials, t)
+	}
+	return denials, rows.Err()
+}
+
+// DeniedTools returns the patterns denied to profile.
+func (d *DB) DeniedTools(profile string) ([]string, error) {
+	rows, err := d.conn.Query(`SELECT pattern FROM tool_profiles WHERE profile = ? ORDER BY pattern`, profile)
+	if err != nil {
+		return nil, fmt.Errorf("loading tool profile %s: %w", profile, err)
+	}
+	defer rows.Close()
+	var patterns []string
+	for rows.Next() {
+		var p string
+		if err := rows.Scan(&p); err != nil {
+			return nil, fmt.Errorf("scanning tool pattern: %w", err)
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns, rows.Err()
+}
+
+// seedToolProfiles creates the default denials. Open calls it only when the
+// tool_profiles table is new, so denials the user removes stay removed.
+func (d *DB) seedToolProfiles() error {
+	for profile, patterns := range defaultToolDenials {
+		for _, p := range patterns {
+			if err := d.DenyTool(profile, p); err != nil {
+				return fmt.Errorf("seeding tool profile %s: %w", profile, err)
+			}
+		}
+	}
+	return nil
+}
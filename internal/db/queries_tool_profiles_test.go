@@ -0,0 +1,39 @@
+package db
+
+import "testing"
+
+func TestToolProfiles(t *testing.T) {
+	d := openTestDB(t)
+
+	denied, err := d.DeniedTools("schedule")
+	if err != nil {
+		t.Fatalf("DeniedTools: %v", err)
+	}
+	if len(denied) != 2 || denied[0] != "delete_*" || denied[1] != "forget" {
+		t.Errorf("expected seeded schedule denials, got %v", denied)
+	}
+
+	if err := d.DenyTool("Discord", "set_area"); err != nil {
+		t.Fatalf("DenyTool: %v", err)
+	}
+	if err := d.DenyTool("discord", "set_area"); err != nil {
+		t.Fatalf("DenyTool again: %v", err)
+	}
+	if denied, _ := d.DeniedTools("discord"); len(denied) != 1 {
+		t.Errorf("expected one discord denial, got %v", denied)
+	}
+	if err := d.DenyTool("discord", "delete_[a"); err == nil {
+		t.Error("expected error for malformed glob")
+	}
+
+	if ok, err := d.AllowTool("schedule", "forget"); err != nil || !ok {
+		t.Fatalf("AllowTool: %v, %v", ok, err)
+	}
+	if ok, _ := d.AllowTool("schedule", "forget"); ok {
+		t.Error("expected second AllowTool to report nothing removed")
+	}
+	all, _ := d.ListToolDenials()
+	if len(all) != 4 {
+		t.Errorf("expected 4 denials left, got %+v", all)
+	}
+}
@@ -1,14 +1,74 @@
-CREATE TABLE IF NOT EXISTS things (
+-- Things to track: tasks, errands, ideas.
+CREATE TABLE IF NOT EXISTS things (  -- @model
     id INTEGER PRIMARY KEY,
-    title TEXT NOT NULL,
-    notes TEXT,
-    status TEXT DEFAULT 'open',
-    priority TEXT DEFAULT 'normal',
-    tags TEXT,
-    due_date TEXT,
+    title TEXT NOT NULL,  -- @tool
+    notes TEXT,  -- @tool
+    status TEXT DEFAULT 'open',  -- @tool @enum(open,active,done,dropped)
+    priority TEXT DEFAULT 'normal',  -- @tool @enum(low,normal,high,urgent)
+    tags TEXT,  -- @update JSON array of lowercase tags
+    due_date TEXT,  -- @tool YYYY-MM-DD
     created_at TEXT DEFAULT (datetime('now')),
     updated_at TEXT DEFAULT (datetime('now')),
-    completed_at TEXT
+    completed_at TEXT  -- @update
+);
+
+-- Automatic changes and flags on things (priority escalation, staleness),
+-- surfaced in check-ins.
+CREATE TABLE IF NOT EXISTS thing_events (  -- @model
+    id INTEGER PRIMARY KEY,
+    thing_id INTEGER NOT NULL REFERENCES things(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,  -- @enum(escalated,stale)
+    detail TEXT NOT NULL DEFAULT '',
+    created_at TEXT DEFAULT (datetime('now'))
+);
+
+-- Areas of focus (work, health, ...) that tags roll up into. Each tag belongs
+-- to at most one area.
+CREATE TABLE IF NOT EXISTS areas (  -- @model
+    id INTEGER PRIMARY KEY,
+    name TEXT UNIQUE NOT NULL,
+    created_at TEXT DEFAULT (datetime('now'))
+);
+
+-- Which area each tag belongs to.
+CREATE TABLE IF NOT EXISTS area_tags (  -- @model
+    tag TEXT PRIMARY KEY,
+    area_id INTEGER NOT NULL REFERENCES areas(id) ON DELETE CASCADE
+);
+
+-- Background jobs the agent enqueues with start_job; the scheduler's worker
+-- runs them one at a time and delivers the result.
+CREATE TABLE IF NOT EXISTS jobs (  -- @model
+    id INTEGER PRIMARY KEY,
+    title TEXT NOT NULL,
+    prompt TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'queued',  -- @enum(queued,running,done,failed)
+    result TEXT NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    created_at TEXT DEFAULT (datetime('now')),
+    started_at TEXT,
+    finished_at TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_jobs_status ON jobs(status, id);
+
+-- Per-entry-point tool deny-list. profile is an entry point (discord, cli,
+-- schedule); pattern is a tool name or glob ("delete_*").
+CREATE TABLE IF NOT EXISTS tool_profiles (
+    profile TEXT NOT NULL,
+    pattern TEXT NOT NULL,
+    created_at TEXT DEFAULT (datetime('now')),
+    PRIMARY KEY (profile, pattern)
+);
+
+-- Named thing queries ("errands", "this week's focus"). spec is a JSON
+-- SavedFilter minus id/name/timestamps.
+CREATE TABLE IF NOT EXISTS saved_filters (  -- @model
+    id INTEGER PRIMARY KEY,
+    name TEXT UNIQUE NOT NULL,
+    spec TEXT NOT NULL DEFAULT '{}',
+    created_at TEXT DEFAULT (datetime('now')),
+    updated_at TEXT DEFAULT (datetime('now'))
 );
 
 CREATE TABLE IF NOT EXISTS notes (
@@ -18,14 +78,17 @@ CREATE TABLE IF NOT EXISTS notes (
     updated_at TEXT DEFAULT (datetime('now'))
 );
 
-CREATE TABLE IF NOT EXISTS memories (
+-- Things jot remembers: observations, decisions, blockers, habits logged.
+-- Proposed memories await approval and are left out of searches.
+CREATE TABLE IF NOT EXISTS memories (  -- @model
     id INTEGER PRIMARY KEY,
-    content TEXT NOT NULL,
-    category TEXT NOT NULL DEFAULT 'observation',
-    tags TEXT,
+    content TEXT NOT NULL,  -- @tool
+    category TEXT NOT NULL DEFAULT 'observation',  -- @tool @enum(observation,decision,blocker,preference,event,reflection,habit,resolved)
+    tags TEXT,  -- @update JSON array of lowercase tags
     thing_id INTEGER REFERENCES things(id),
-    source TEXT NOT NULL DEFAULT 'agent',
-    expires_at TEXT,
+    source TEXT NOT NULL DEFAULT 'agent',  -- who saved it: agent, cli, import, ...
+    status TEXT NOT NULL DEFAULT 'active',  -- @enum(active,proposed)
+    expires_at TEXT,  -- @tool dropped from results after this time
     created_at TEXT DEFAULT (datetime('now')),
     updated_at TEXT DEFAULT (datetime('now'))
 );
@@ -37,6 +100,9 @@ CREATE VIRTUAL TABLE IF NOT EXISTS memories_fts USING fts5(
     content='memories'
 );
 
+-- Terms in memories_fts, used to correct misspelled search words.
+CREATE VIRTUAL TABLE IF NOT EXISTS memories_vocab USING fts5vocab(memories_fts, 'row');
+
 CREATE TRIGGER IF NOT EXISTS memories_ai AFTER INSERT ON memories BEGIN
     INSERT INTO memories_fts(rowid, content) VALUES (new.id, new.content);
 END;
@@ -50,15 +116,21 @@ CREATE TRIGGER IF NOT EXISTS memories_au AFTER UPDATE ON memories BEGIN
     INSERT INTO memories_fts(rowid, content) VALUES (new.id, new.content);
 END;
 
-CREATE TABLE IF NOT EXISTS schedules (
+-- Prompts run on a cron schedule or once at fire_at, with the reply
+-- delivered to the user.
+CREATE TABLE IF NOT EXISTS schedules (  -- @model
 	id INTEGER PRIMARY KEY,
   name TEXT UNIQUE NOT NULL,
-  cron_expr TEXT NOT NULL DEFAULT '',
-  prompt TEXT NOT NULL,
-  enabled INTEGER DEFAULT 1,
+  cron_expr TEXT NOT NULL DEFAULT '',  -- @tool empty for one-off reminders
+  prompt TEXT NOT NULL,  -- @tool
+  enabled INTEGER DEFAULT 1,  -- @update
   last_run TEXT,
   fire_at TEXT,
   fired INTEGER DEFAULT 0,
+  check_in INTEGER DEFAULT 0,  -- @update
+  digest INTEGER DEFAULT 0,  -- @update append the last 24 hours of audit_log to the reply
+  memory_days INTEGER DEFAULT 0,  -- @tool
+  memory_limit INTEGER DEFAULT 0,  -- @tool
   created_at TEXT DEFAULT (datetime('now'))
 );
 
@@ -78,19 +150,47 @@ CREATE TABLE IF NOT EXISTS conversation_summaries (
     created_at TEXT DEFAULT (datetime('now'))
 );
 
-CREATE TABLE IF NOT EXISTS watches (
+-- Append-only transcript of user/assistant text, kept after the live
+-- conversation is summarized so past conversations stay searchable.
+CREATE TABLE IF NOT EXISTS conversation_messages (
+    id INTEGER PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    role TEXT NOT NULL,
+    content TEXT NOT NULL,
+    created_at TEXT DEFAULT (datetime('now'))
+);
+
+CREATE VIRTUAL TABLE IF NOT EXISTS conversation_messages_fts USING fts5(
+    content,
+    content_rowid='id',
+    content='conversation_messages'
+);
+
+CREATE VIRTUAL TABLE IF NOT EXISTS conversation_messages_vocab USING fts5vocab(conversation_messages_fts, 'row');
+
+CREATE TRIGGER IF NOT EXISTS conversation_messages_ai AFTER INSERT ON conversation_messages BEGIN
+    INSERT INTO conversation_messages_fts(rowid, content) VALUES (new.id, new.content);
+END;
+
+CREATE TRIGGER IF NOT EXISTS conversation_messages_ad AFTER DELETE ON conversation_messages BEGIN
+    INSERT INTO conversation_messages_fts(conversation_messages_fts, rowid, content) VALUES('delete', old.id, old.content);
+END;
+
+-- Pages checked on a schedule; new items found are kept in watch_results.
+CREATE TABLE IF NOT EXISTS watches (  -- @model
     id INTEGER PRIMARY KEY,
     name TEXT UNIQUE NOT NULL,
-    prompt TEXT NOT NULL,
-    urls TEXT NOT NULL DEFAULT '[]',
-    cron_expr TEXT NOT NULL DEFAULT '',
-    enabled INTEGER DEFAULT 1,
+    prompt TEXT NOT NULL,  -- @tool
+    urls TEXT NOT NULL DEFAULT '[]',  -- @update JSON array
+    cron_expr TEXT NOT NULL DEFAULT '',  -- @tool
+    enabled INTEGER DEFAULT 1,  -- @update
     last_run TEXT,
     created_at TEXT DEFAULT (datetime('now')),
     updated_at TEXT DEFAULT (datetime('now'))
 );
 
-CREATE TABLE IF NOT EXISTS watch_results (
+-- Items a watch found, one per distinct content; notified once delivered.
+CREATE TABLE IF NOT EXISTS watch_results (  -- @model
     id INTEGER PRIMARY KEY,
     watch_id INTEGER NOT NULL REFERENCES watches(id) ON DELETE CASCADE,
     content_hash TEXT NOT NULL,
@@ -101,3 +201,76 @@ CREATE TABLE IF NOT EXISTS watch_results (
     notified INTEGER DEFAULT 0,
     UNIQUE(watch_id, content_hash)
 );
+
+CREATE TABLE IF NOT EXISTS tool_idempotency (
+    key TEXT PRIMARY KEY,
+    tool TEXT NOT NULL,
+    result TEXT NOT NULL,
+    created_at TEXT DEFAULT (datetime('now'))
+);
+
+CREATE TABLE IF NOT EXISTS discord_channels (
+    channel_id TEXT PRIMARY KEY,
+    last_message_id TEXT NOT NULL,     -- newest DM handled, for catch-up after downtime
+    updated_at TEXT DEFAULT (datetime('now'))
+);
+
+CREATE TABLE IF NOT EXISTS discord_deletions (
+    id INTEGER PRIMARY KEY,
+    channel_id TEXT NOT NULL,
+    message_id TEXT NOT NULL,          -- bot reply to delete (guild delete_after)
+    due_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_discord_deletions_due ON discord_deletions(due_at);
+
+CREATE TABLE IF NOT EXISTS guild_settings (
+    guild_id TEXT PRIMARY KEY,
+    channels TEXT NOT NULL DEFAULT '[]',   -- JSON channel IDs jot answers in; empty means all
+    required_role TEXT NOT NULL DEFAULT '', -- role ID a member needs; empty means anyone
+    profile TEXT NOT NULL DEFAULT 'discord', -- tool profile for turns in this guild
+    reply_dm INTEGER NOT NULL DEFAULT 0,     -- answer mentions by DM instead of in the channel
+    delete_after INTEGER NOT NULL DEFAULT 0, -- minutes before channel replies are deleted; 0 keeps them
+    updated_at TEXT DEFAULT (datetime('now'))
+);
+
+CREATE TABLE IF NOT EXISTS schedule_runs (
+    id INTEGER PRIMARY KEY,
+    schedule_name TEXT NOT NULL,       -- kept by name so the log survives deleting the schedule
+    check_in INTEGER NOT NULL DEFAULT 0,
+    output TEXT NOT NULL,
+    created_at TEXT DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_schedule_runs_created ON schedule_runs(created_at);
+CREATE INDEX IF NOT EXISTS idx_schedule_runs_name ON schedule_runs(schedule_name, id);
+
+-- Every create, update, and delete the agent made through a tool, for the
+-- daily changes digest.
+CREATE TABLE IF NOT EXISTS audit_log (  -- @model
+    id INTEGER PRIMARY KEY,
+    tool TEXT NOT NULL,
+    action TEXT NOT NULL,  -- @enum(create,update,delete)
+    target TEXT NOT NULL DEFAULT '',  -- what was written, e.g. "thing #12"
+    detail TEXT NOT NULL DEFAULT '',
+    profile TEXT NOT NULL DEFAULT '',  -- entry point the turn ran under
+    created_at TEXT DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_created ON audit_log(created_at);
+
+-- Outbound messages (check-ins, reminders, job results, watch items) waiting
+-- to be delivered. The scheduler's delivery worker sends them in order,
+-- retrying failures with exponential backoff; sent rows are deleted.
+CREATE TABLE IF NOT EXISTS deliveries (
+    id INTEGER PRIMARY KEY,
+    label TEXT NOT NULL,  -- what produced it, e.g. scheduler[morning-checkin]
+    content TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',  -- @enum(pending,failed)
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    next_attempt_at TEXT DEFAULT (datetime('now')),
+    created_at TEXT DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_deliveries_due ON deliveries(status, next_attempt_at, id);
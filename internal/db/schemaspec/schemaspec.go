@@ -0,0 +1,236 @@
+// Package schemaspec reads column annotations from schema.sql and renders
+// the Go whitelists derived from them. schema.sql is the single source of
+// truth: a trailing comment on a column line marks how it may be written.
+//
+//	title TEXT NOT NULL,  -- @update @tool
+//
+// @update allows the column in updateRow (and UpdateSchedule). @tool also
+// lists it in ToolFields, the scalar fields the update_* tools copy straight
+// from tool params; columns needing conversion (tags, urls, enabled) are
+// handled by hand in the agent and carry only @update.
+//
+//	status TEXT DEFAULT 'open',  -- @tool @enum(open,active,done,dropped)
+//
+// @enum lists the values a column may hold. A table whose CREATE line ends
+// in -- @model is part of the data model Describe reports, along with the
+// comment block above it and its columns' comments.
+package schemaspec
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Column is an annotated column.
+type Column struct {
+	Name   string
+	Update bool
+	Tool   bool
+	Values []string // @enum
+}
+
+var (
+	createRe     = regexp.MustCompile(`(?i)^CREATE TABLE IF NOT EXISTS (\w+)\s*\(`)
+	annotationRe = regexp.MustCompile(`@(\w+)(?:\(([^)]*)\))?`)
+	defaultRe    = regexp.MustCompile(`(?i)\bDEFAULT\s+(?:'([^']*)'|(-?\d+))`)
+	referencesRe = regexp.MustCompile(`(?i)\bREFERENCES\s+(\w+)\s*\((\w+)\)`)
+)
+
+// Parse returns the annotated columns of each table, in schema order. It
+// fails on unknown annotations or annotations outside a CREATE TABLE.
+func Parse(schema string) (map[string][]Column, error) {
+	tables := make(map[string][]Column)
+	var table string
+	for n, line := range strings.Split(schema, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if m := createRe.FindStringSubmatch(trimmed); m != nil {
+			table = m[1]
+			continue
+		}
+		if strings.HasPrefix(trimmed, ")") {
+			table = ""
+			continue
+		}
+		code, comment, ok := strings.Cut(trimmed, "--")
+		if !ok || !strings.Contains(comment, "@") {
+			continue
+		}
+		fields := strings.Fields(code)
+		if table == "" || len(fields) == 0 {
+			return nil, fmt.Errorf("line %d: annotation outside a column definition", n+1)
+		}
+		col := Column{Name: fields[0]}
+		for _, m := range annotationRe.FindAllStringSubmatch(comment, -1) {
+			switch m[1] {
+			case "update":
+				col.Update = true
+			case "tool":
+				col.Update, col.Tool = true, true
+			case "enum":
+				if col.Values = splitValues(m[2]); len(col.Values) == 0 {
+					return nil, fmt.Errorf("line %d: @enum needs values", n+1)
+				}
+			default:
+				return nil, fmt.Errorf("line %d: unknown annotation @%s", n+1, m[1])
+			}
+		}
+		tables[table] = append(tables[table], col)
+	}
+	return tables, nil
+}
+
+// Generate renders the Go source for package pkg declaring allowedColumns
+// and ToolFields from the annotations in schema.
+func Generate(pkg, schema string) ([]byte, error) {
+	tables, err := Parse(schema)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(tables))
+	for name := range tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by go run ./gen; DO NOT EDIT.\n// Source: schema.sql column annotations.\n\npackage %s\n\n", pkg)
+	b.WriteString("// allowedColumns lists, per table, the columns updateRow may set (@update).\n")
+	b.WriteString("var allowedColumns = map[string]map[string]bool{\n")
+	for _, name := range names {
+		var update []string
+		for _, c := range tables[name] {
+			if c.Update {
+				update = append(update, fmt.Sprintf("%q: true", c.Name))
+			}
+		}
+		if len(update) > 0 {
+			fmt.Fprintf(&b, "%q: {%s},\n", name, strings.Join(update, ", "))
+		}
+	}
+	b.WriteString("}\n\n")
+	b.WriteString("// ToolFields lists, per table, the scalar columns the update_* tools copy\n// straight from tool params (@tool).\n")
+	b.WriteString("var ToolFields = map[string][]string{\n")
+	for _, name := range names {
+		var tool []string
+		for _, c := range tables[name] {
+			if c.Tool {
+				tool = append(tool, fmt.Sprintf("%q", c.Name))
+			}
+		}
+		if len(tool) > 0 {
+			fmt.Fprintf(&b, "%q: {%s},\n", name, strings.Join(tool, ", "))
+		}
+	}
+	b.WriteString("}\n")
+	return format.Source(b.Bytes())
+}
+
+// Table is a table in the data model.
+type Table struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	Fields      []Field `json:"fields"`
+}
+
+// Field is one column of a data model table.
+type Field struct {
+	Name       string   `json:"name"`
+	Type       string   `json:"type"`
+	Values     []string `json:"values,omitempty"`
+	Default    string   `json:"default,omitempty"`
+	References string   `json:"references,omitempty"` // table.column
+	Note       string   `json:"note,omitempty"`
+}
+
+// Describe returns the tables marked @model, in schema order. A table's
+// description is the comment block directly above its CREATE line; a field's
+// note is its trailing comment minus annotations.
+func Describe(schema string) ([]Table, error) {
+	cols, err := Parse(schema)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		tables  []Table
+		cur     *Table
+		comment []string
+	)
+	for _, line := range strings.Split(schema, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if m := createRe.FindStringSubmatch(trimmed); m != nil {
+			if _, c, _ := strings.Cut(trimmed, "--"); hasAnnotation(c, "model") {
+				tables = append(tables, Table{Name: m[1], Description: strings.Join(comment, " ")})
+				cur = &tables[len(tables)-1]
+			}
+			comment = nil
+			continue
+		}
+		if text, ok := strings.CutPrefix(trimmed, "--"); ok && cur == nil {
+			comment = append(comment, strings.TrimSpace(text))
+			continue
+		}
+		comment = nil
+		if strings.HasPrefix(trimmed, ")") {
+			cur = nil
+			continue
+		}
+		if cur == nil {
+			continue
+		}
+		code, note, _ := strings.Cut(trimmed, "--")
+		fields := strings.Fields(code)
+		if len(fields) < 2 || isConstraint(fields[0]) {
+			continue
+		}
+		f := Field{
+			Name: fields[0],
+			Type: strings.TrimSuffix(fields[1], ","),
+			Note: strings.Join(strings.Fields(annotationRe.ReplaceAllString(note, "")), " "),
+		}
+		if m := defaultRe.FindStringSubmatch(code); m != nil {
+			f.Default = m[1] + m[2]
+		}
+		if m := referencesRe.FindStringSubmatch(code); m != nil {
+			f.References = m[1] + "." + m[2]
+		}
+		for _, c := range cols[cur.Name] {
+			if c.Name == f.Name {
+				f.Values = c.Values
+			}
+		}
+		cur.Fields = append(cur.Fields, f)
+	}
+	return tables, nil
+}
+
+func hasAnnotation(comment, name string) bool {
+	for _, m := range annotationRe.FindAllStringSubmatch(comment, -1) {
+		if m[1] == name {
+			return true
+		}
+	}
+	return false
+}
+
+func isConstraint(word string) bool {
+	word, _, _ = strings.Cut(word, "(")
+	switch strings.ToUpper(word) {
+	case "PRIMARY", "UNIQUE", "FOREIGN", "CHECK", "CONSTRAINT":
+		return true
+	}
+	return false
+}
+
+func splitValues(s string) []string {
+	var vals []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			vals = append(vals, v)
+		}
+	}
+	return vals
+}
@@ -0,0 +1,88 @@
+package schemaspec
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tables, err := Parse(`
+CREATE TABLE IF NOT EXISTS things (
+    id INTEGER PRIMARY KEY,
+    title TEXT NOT NULL,  -- @tool
+    tags TEXT,  -- JSON array @update
+    created_at TEXT -- set on insert
+);
+CREATE TABLE IF NOT EXISTS notes (
+    key TEXT
+);`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	cols := tables["things"]
+	if len(cols) != 2 || len(tables) != 1 {
+		t.Fatalf("tables = %+v", tables)
+	}
+	if c := cols[0]; c.Name != "title" || !c.Update || !c.Tool {
+		t.Errorf("title = %+v, want update+tool", c)
+	}
+	if c := cols[1]; c.Name != "tags" || !c.Update || c.Tool {
+		t.Errorf("tags = %+v, want update only", c)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for name, schema := range map[string]string{
+		"unknown annotation": "CREATE TABLE IF NOT EXISTS t (\n  a TEXT -- @updatable\n);",
+		"outside table":      "-- @update\nCREATE TABLE IF NOT EXISTS t (a TEXT);",
+		"empty enum":         "CREATE TABLE IF NOT EXISTS t (\n  a TEXT -- @enum()\n);",
+	} {
+		if _, err := Parse(schema); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	src, err := Generate("db", "CREATE TABLE IF NOT EXISTS t (\n  a TEXT, -- @tool\n  b TEXT -- @update\n);")
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	for _, want := range []string{"DO NOT EDIT", `"t": {"a": true, "b": true}`, `"t": {"a"}`} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("generated source missing %q:\n%s", want, src)
+		}
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	tables, err := Describe(`
+-- Things to track.
+CREATE TABLE IF NOT EXISTS things (  -- @model
+    id INTEGER PRIMARY KEY,
+    status TEXT DEFAULT 'open',  -- @tool @enum(open, done)
+    thing_id INTEGER REFERENCES things(id),  -- parent
+    UNIQUE(status, thing_id)
+);
+
+-- Internal.
+CREATE TABLE IF NOT EXISTS notes (
+    key TEXT
+);`)
+	if err != nil {
+		t.Fatalf("Describe: %v", err)
+	}
+	if len(tables) != 1 || tables[0].Name != "things" || tables[0].Description != "Things to track." {
+		t.Fatalf("tables = %+v", tables)
+	}
+	f := tables[0].Fields
+	if len(f) != 3 {
+		t.Fatalf("fields = %+v", f)
+	}
+	if s := f[1]; s.Type != "TEXT" || s.Default != "open" || len(s.Values) != 2 || s.Values[1] != "done" || s.Note != "" {
+		t.Errorf("status = %+v", s)
+	}
+	if p := f[2]; p.References != "things.id" || p.Note != "parent" {
+		t.Errorf("thing_id = %+v", p)
+	}
+}
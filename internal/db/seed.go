@@ -0,0 +1,106 @@
+package db
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Pack is a bundle of starter data for a workflow, installed with jot setup.
+// Applying a pack only adds what's missing, so it is safe to re-run and never
+// overwrites the user's own schedules, filters, or area mappings.
+type Pack struct {
+	Name        string
+	Description string
+	Areas       map[string][]string // area name -> tags mapped into it
+	Schedules   []Schedule          // Name, CronExpr, Prompt, CheckIn are used
+	Filters     []SavedFilter
+}
+
+// PackResult counts what ApplyPack added and lists what it left alone.
+type PackResult struct {
+	Areas     int
+	Schedules int
+	Filters   int
+	Skipped   []string // "schedule x", "filter y", "tag z" that already existed
+}
+
+// FindPack returns the built-in pack with the given name, or nil.
+func FindPack(name string) *Pack {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for i := range Packs {
+		if Packs[i].Name == name {
+			return &Packs[i]
+		}
+	}
+	return nil
+}
+
+// ApplyPack installs a pack's areas, schedules, and saved filters. Tags that
+// already belong to an area stay where they are; schedules and filters whose
+// names exist are skipped.
+func (d *DB) ApplyPack(p *Pack) (PackResult, error) {
+	var res PackResult
+
+	areas, err := d.ListAreas()
+	if err != nil {
+		return res, err
+	}
+	mapped := make(map[string]bool)
+	for _, a := range areas {
+		for _, t := range a.Tags {
+			mapped[t] = true
+		}
+	}
+	names := make([]string, 0, len(p.Areas))
+	for name := range p.Areas {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		var tags []string
+		for _, t := range p.Areas[name] {
+			if mapped[t] {
+				res.Skipped = append(res.Skipped, "tag "+t)
+				continue
+			}
+			tags = append(tags, t)
+		}
+		if len(tags) == 0 {
+			continue
+		}
+		if _, err := d.SetArea(name, tags); err != nil {
+			return res, fmt.Errorf("applying pack %s: %w", p.Name, err)
+		}
+		res.Areas++
+	}
+
+	for _, s := range p.Schedules {
+		r, err := d.conn.Exec(`INSERT INTO schedules (name, cron_expr, prompt, check_in) VALUES (?, ?, ?, ?)
+			ON CONFLICT(name) DO NOTHING`, s.Name, s.CronExpr, s.Prompt, s.CheckIn)
+		if err != nil {
+			return res, fmt.Errorf("applying pack %s: adding schedule %s: %w", p.Name, s.Name, err)
+		}
+		if n, _ := r.RowsAffected(); n == 0 {
+			res.Skipped = append(res.Skipped, "schedule "+s.Name)
+			continue
+		}
+		res.Schedules++
+	}
+
+	for _, f := range p.Filters {
+		existing, err := d.GetFilter(f.Name)
+		if err != nil {
+			return res, err
+		}
+		if existing != nil {
+			res.Skipped = append(res.Skipped, "filter "+f.Name)
+			continue
+		}
+		if _, err := d.SaveFilter(f); err != nil {
+			return res, fmt.Errorf("applying pack %s: %w", p.Name, err)
+		}
+		res.Filters++
+	}
+	return res, nil
+}
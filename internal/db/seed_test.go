@@ -0,0 +1,55 @@
+package db
+
+import "testing"
+
+func TestApplyPack(t *testing.T) {
+	d := openTestDB(t)
+
+	p := FindPack("GTD")
+	if p == nil {
+		t.Fatal("expected the gtd pack")
+	}
+	d.CreateSchedule("gtd-weekly-review", "0 9 * * 1", "my own review")
+	d.SetArea("errands", []string{"@errands"})
+
+	res, err := d.ApplyPack(p)
+	if err != nil {
+		t.Fatalf("ApplyPack: %v", err)
+	}
+	if res.Schedules != 1 || res.Filters != 3 || res.Areas != 2 {
+		t.Errorf("unexpected result: %+v", res)
+	}
+	if len(res.Skipped) != 2 {
+		t.Errorf("expected the existing schedule and tag to be skipped, got %v", res.Skipped)
+	}
+	s, _ := d.GetScheduleByName("gtd-weekly-review")
+	if s.Prompt != "my own review" {
+		t.Errorf("existing schedule was overwritten: %q", s.Prompt)
+	}
+	if s, _ := d.GetScheduleByName("gtd-daily-review"); s == nil || !s.CheckIn {
+		t.Errorf("expected daily review with check-in context, got %+v", s)
+	}
+
+	again, err := d.ApplyPack(p)
+	if err != nil {
+		t.Fatalf("ApplyPack again: %v", err)
+	}
+	if again.Schedules+again.Filters+again.Areas != 0 {
+		t.Errorf("re-applying should add nothing, got %+v", again)
+	}
+}
+
+func TestPacksAreValid(t *testing.T) {
+	for _, p := range Packs {
+		for _, f := range p.Filters {
+			if _, ok := thingSorts[f.Sort]; f.Sort != "" && !ok {
+				t.Errorf("pack %s filter %s: unknown sort %q", p.Name, f.Name, f.Sort)
+			}
+		}
+		for _, s := range p.Schedules {
+			if s.Name == "" || s.CronExpr == "" || s.Prompt == "" {
+				t.Errorf("pack %s has an incomplete schedule: %+v", p.Name, s)
+			}
+		}
+	}
+}
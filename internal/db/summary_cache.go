@@ -0,0 +1,64 @@
+package db
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// summaryCacheTTL bounds how stale a cached summary can get from changes the
+// cache can't see: another process (a jot subcommand) writing the same file,
+// and the recent window sliding forward.
+const summaryCacheTTL = time.Minute
+
+// summaryCache holds GetSummary results by options. Every method that writes
+// things or areas calls thingsChanged, which empties it.
+type summaryCache struct {
+	mu      sync.Mutex
+	version uint64 // bumped on every change
+	entries map[string]summaryEntry
+}
+
+type summaryEntry struct {
+	summary *Summary
+	at      time.Time
+}
+
+// thingsChanged invalidates cached summaries. Call it after a write to
+// things, areas, or area_tags has committed.
+func (d *DB) thingsChanged() {
+	c := &d.summaries
+	c.mu.Lock()
+	c.version++
+	c.entries = nil
+	c.mu.Unlock()
+}
+
+// get returns the cached summary for key, if fresh, and the cache
+// version to pass to put.
+func (c *summaryCache) get(key string) (*Summary, uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok && time.Since(e.at) < summaryCacheTTL {
+		return e.summary, c.version
+	}
+	return nil, c.version
+}
+
+// put stores s unless things changed since version was read, in which case s
+// may already be out of date.
+func (c *summaryCache) put(key string, version uint64, s *Summary) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.version != version {
+		return
+	}
+	if c.entries == nil {
+		c.entries = make(map[string]summaryEntry)
+	}
+	c.entries[key] = summaryEntry{summary: s, at: time.Now()}
+}
+
+func summaryKey(o SummaryOptions) string {
+	return fmt.Sprintf("%d|%d|%d|%q|%s|%s|%s", o.RecentDays, o.RecentLimit, o.ListLimit, o.OpenStatuses, o.Today, o.Tag, o.Area)
+}
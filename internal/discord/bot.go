@@ -3,16 +3,27 @@ package discord
 import (
 	"fmt"
 	"log"
+	"sync"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/chris/jot/internal/agent"
 	"github.com/chris/jot/internal/db"
+	"github.com/chris/jot/internal/plaintext"
 )
 
 type Bot struct {
 	session *discordgo.Session
 	agent   *agent.Agent
 	db      *db.DB
+
+	mu             sync.Mutex
+	dms            map[string]*dmChannel // by channel ID
+	disconnectedAt time.Time             // zero while connected
+	gapSeen        map[string]string     // lastSeen when the gap began; see onDisconnect
+	started        bool                  // first Ready handled
+
+	stop chan struct{} // closed by Close to end background loops
 }
 
 func NewBot(token string, ag *agent.Agent, database *db.DB) (*Bot, error) {
@@ -21,8 +32,21 @@ func NewBot(token string, ag *agent.Agent, database *db.DB) (*Bot, error) {
 		return nil, fmt.Errorf("creating Discord session: %w", err)
 	}
 
-	bot := &Bot{session: s, agent: ag, db: database}
+	bot := &Bot{session: s, agent: ag, db: database, dms: make(map[string]*dmChannel), stop: make(chan struct{})}
+	last, err := database.LastMessages()
+	if err != nil {
+		return nil, err
+	}
+	for channelID, messageID := range last {
+		bot.dms[channelID] = &dmChannel{last: messageID}
+	}
+	bot.gapSeen = last // caught up on by the first onReady
 	s.AddHandler(bot.onMessage)
+	s.AddHandler(bot.onDisconnect)
+	s.AddHandler(bot.onReady)
+	s.AddHandler(bot.onResumed)
+	s.AddHandler(bot.onInteraction)
+	s.ShouldReconnectOnError = true
 	s.Identify.Intents = discordgo.IntentsDirectMessages | discordgo.IntentsGuildMessages
 
 	if err := s.Open(); err != nil {
@@ -30,23 +54,42 @@ func NewBot(token string, ag *agent.Agent, database *db.DB) (*Bot, error) {
 	}
 
 	log.Printf("Discord bot connected as %s", s.State.User.Username)
+	go bot.sweepDeletions()
 	return bot, nil
 }
 
 func (b *Bot) Close() {
+	close(b.stop)
 	b.session.Close()
 }
 
-// SendDM sends a message to a Discord user via DM channel.
+// SendDM sends a message to a Discord user via DM channel, as plain text if
+// they asked for it.
 func (b *Bot) SendDM(userID, content string) error {
 	ch, err := b.session.UserChannelCreate(userID)
 	if err != nil {
 		return fmt.Errorf("creating DM channel: %w", err)
 	}
-	for _, chunk := range splitMessage(content, 2000) {
-		if _, err := b.session.ChannelMessageSend(ch.ID, chunk); err != nil {
+	plain := b.agent.PlainText(userID)
+	if plain {
+		content = plaintext.Strip(content)
+	}
+	for _, chunk := range SplitMessage(content, MessageLimit) {
+		if _, err := sendMessage(b.session, ch.ID, chunk, plain); err != nil {
 			return fmt.Errorf("sending DM: %w", err)
 		}
 	}
 	return nil
 }
+
+// sendMessage sends text to a channel. Plain-text messages go out with link
+// previews suppressed, since embeds are noise to a screen reader.
+func sendMessage(s *discordgo.Session, channelID, text string, plain bool) (*discordgo.Message, error) {
+	if !plain {
+		return s.ChannelMessageSend(channelID, text)
+	}
+	return s.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+		Content: text,
+		Flags:   discordgo.MessageFlagsSuppressEmbeds,
+	})
+}
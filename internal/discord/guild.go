@@ -0,0 +1,188 @@
+package discord
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/chris/jot/internal/agent"
+	"github.com/chris/jot/internal/db"
+)
+
+// guildAllows reports whether jot should answer a mention in channelID from a
+// member holding roles, under guild settings g.
+func guildAllows(g *db.GuildSettings, channelID string, roles []string) bool {
+	if len(g.Channels) > 0 && !slices.Contains(g.Channels, channelID) {
+		return false
+	}
+	return g.RequiredRole == "" || slices.Contains(roles, g.RequiredRole)
+}
+
+// guildCommand is the /jot slash command server admins use to configure the
+// bot. Discord only shows it to members with Manage Server.
+var guildCommand = func() *discordgo.ApplicationCommand {
+	manageGuild := int64(discordgo.PermissionManageGuild)
+	zero := 0.0
+	profiles := make([]*discordgo.ApplicationCommandOptionChoice, len(agent.Profiles))
+	for i, p := range agent.Profiles {
+		profiles[i] = &discordgo.ApplicationCommandOptionChoice{Name: p, Value: p}
+	}
+	return &discordgo.ApplicationCommand{
+		Name:                     "jot",
+		Description:              "Configure jot in this server",
+		DefaultMemberPermissions: &manageGuild,
+		Contexts:                 &[]discordgo.InteractionContextType{discordgo.InteractionContextGuild},
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "show",
+				Description: "Show this server's settings",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "channel",
+				Description: "Answer only in listed channels (all channels while the list is empty)",
+				Options: []*discordgo.ApplicationCommandOption{
+					{Type: discordgo.ApplicationCommandOptionChannel, Name: "channel", Description: "Channel to add or remove", Required: true},
+					{Type: discordgo.ApplicationCommandOptionBoolean, Name: "remove", Description: "Remove the channel instead of adding it"},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "role",
+				Description: "Answer only members with a role (omit to allow anyone)",
+				Options: []*discordgo.ApplicationCommandOption{
+					{Type: discordgo.ApplicationCommandOptionRole, Name: "role", Description: "Required role"},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "privacy",
+				Description: "Keep replies out of the channel",
+				Options: []*discordgo.ApplicationCommandOption{
+					{Type: discordgo.ApplicationCommandOptionBoolean, Name: "dm", Description: "Answer mentions by DM"},
+					{Type: discordgo.ApplicationCommandOptionInteger, Name: "delete_after", Description: "Minutes before channel replies are deleted (0 keeps them)", MinValue: &zero, MaxValue: maxDeleteAfter},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "profile",
+				Description: "Tool profile for turns in this server (see jot tools)",
+				Options: []*discordgo.ApplicationCommandOption{
+					{Type: discordgo.ApplicationCommandOptionString, Name: "name", Description: "Profile", Required: true, Choices: profiles},
+				},
+			},
+		},
+	}
+}()
+
+// maxDeleteAfter caps delete_after at a day.
+const maxDeleteAfter = 24 * 60
+
+// registerCommands installs the slash commands globally, replacing any from
+// earlier versions.
+func (b *Bot) registerCommands(s *discordgo.Session) {
+	if _, err := s.ApplicationCommandBulkOverwrite(s.State.User.ID, "", []*discordgo.ApplicationCommand{guildCommand}); err != nil {
+		log.Printf("registering slash commands: %v", err)
+	}
+}
+
+func (b *Bot) onInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand || i.GuildID == "" {
+		return
+	}
+	data := i.ApplicationCommandData()
+	if data.Name != guildCommand.Name || len(data.Options) == 0 {
+		return
+	}
+
+	var reply string
+	if i.Member == nil || i.Member.Permissions&discordgo.PermissionManageGuild == 0 {
+		reply = "You need Manage Server to change jot's settings."
+	} else if r, err := b.configureGuild(i.GuildID, data.Options[0]); err != nil {
+		log.Printf("guild settings: %v", err)
+		reply = "Couldn't update settings: " + err.Error()
+	} else {
+		reply = r
+	}
+
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Content: reply, Flags: discordgo.MessageFlagsEphemeral},
+	})
+	if err != nil {
+		log.Printf("responding to /jot: %v", err)
+	}
+}
+
+// configureGuild applies one /jot subcommand and returns the reply.
+func (b *Bot) configureGuild(guildID string, sub *discordgo.ApplicationCommandInteractionDataOption) (string, error) {
+	g, err := b.db.GetGuildSettings(guildID)
+	if err != nil {
+		return "", err
+	}
+	opts := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(sub.Options))
+	for _, o := range sub.Options {
+		opts[o.Name] = o
+	}
+
+	switch sub.Name {
+	case "show":
+		return describeGuild(g), nil
+	case "channel":
+		id := opts["channel"].ChannelValue(nil).ID
+		g.Channels = slices.DeleteFunc(g.Channels, func(c string) bool { return c == id })
+		if remove := opts["remove"]; remove == nil || !remove.BoolValue() {
+			g.Channels = append(g.Channels, id)
+		}
+	case "role":
+		g.RequiredRole = ""
+		if role := opts["role"]; role != nil {
+			g.RequiredRole = role.RoleValue(nil, "").ID
+		}
+	case "privacy":
+		if dm := opts["dm"]; dm != nil {
+			g.ReplyDM = dm.BoolValue()
+		}
+		if after := opts["delete_after"]; after != nil {
+			g.DeleteAfter = int(after.IntValue())
+		}
+	case "profile":
+		g.Profile = opts["name"].StringValue()
+	default:
+		return "", fmt.Errorf("unknown subcommand %q", sub.Name)
+	}
+
+	if err := b.db.SaveGuildSettings(g); err != nil {
+		return "", err
+	}
+	return "Saved. " + describeGuild(g), nil
+}
+
+func describeGuild(g *db.GuildSettings) string {
+	var sb strings.Builder
+	if len(g.Channels) == 0 {
+		sb.WriteString("Answers mentions in every channel")
+	} else {
+		mentions := make([]string, len(g.Channels))
+		for i, c := range g.Channels {
+			mentions[i] = "<#" + c + ">"
+		}
+		sb.WriteString("Answers mentions in " + strings.Join(mentions, ", "))
+	}
+	if g.RequiredRole == "" {
+		sb.WriteString(" from anyone")
+	} else {
+		sb.WriteString(" from members with <@&" + g.RequiredRole + ">")
+	}
+	fmt.Fprintf(&sb, ", using the %s tool profile.", g.Profile)
+	switch {
+	case g.ReplyDM:
+		sb.WriteString(" Replies go by DM.")
+	case g.DeleteAfter > 0:
+		fmt.Fprintf(&sb, " Replies are deleted after %d minute(s).", g.DeleteAfter)
+	}
+	return sb.String()
+}
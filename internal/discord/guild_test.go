@@ -0,0 +1,31 @@
+package discord
+
+import (
+	"testing"
+
+	"github.com/chris/jot/internal/db"
+)
+
+func TestGuildAllows(t *testing.T) {
+	open := &db.GuildSettings{}
+	if !guildAllows(open, "c1", nil) {
+		t.Error("default settings should allow every channel and member")
+	}
+
+	g := &db.GuildSettings{Channels: []string{"c1"}, RequiredRole: "r1"}
+	tests := []struct {
+		channel string
+		roles   []string
+		want    bool
+	}{
+		{"c1", []string{"r0", "r1"}, true},
+		{"c2", []string{"r1"}, false},
+		{"c1", []string{"r0"}, false},
+		{"c1", nil, false},
+	}
+	for _, tt := range tests {
+		if got := guildAllows(g, tt.channel, tt.roles); got != tt.want {
+			t.Errorf("guildAllows(%q, %v) = %v, want %v", tt.channel, tt.roles, got, tt.want)
+		}
+	}
+}
@@ -2,10 +2,15 @@ package discord
 
 import (
 	"context"
+	"errors"
 	"log"
+	"net/http"
 	"strings"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/chris/jot/internal/agent"
+	"github.com/chris/jot/internal/plaintext"
 )
 
 func (b *Bot) onMessage(s *discordgo.Session, m *discordgo.MessageCreate) {
@@ -28,6 +33,31 @@ func (b *Bot) onMessage(s *discordgo.Session, m *discordgo.MessageCreate) {
 		return
 	}
 
+	profile := agent.ProfileDiscord
+	var replyDM bool
+	var deleteAfter time.Duration
+	if !isDM {
+		g, err := b.db.GetGuildSettings(m.GuildID)
+		if err != nil {
+			log.Printf("loading guild settings: %v", err)
+			return
+		}
+		var roles []string
+		if m.Member != nil {
+			roles = m.Member.Roles
+		}
+		if !guildAllows(g, m.ChannelID, roles) {
+			return
+		}
+		profile = g.Profile
+		replyDM = g.ReplyDM
+		deleteAfter = time.Duration(g.DeleteAfter) * time.Minute
+	}
+
+	if isDM && !b.claim(m.ChannelID, m.ID) {
+		return // already handled, e.g. replayed after a reconnect
+	}
+
 	if isDM {
 		_ = b.db.SetNote("discord_user_id", m.Author.ID)
 	}
@@ -39,29 +69,202 @@ func (b *Bot) onMessage(s *discordgo.Session, m *discordgo.MessageCreate) {
 		return
 	}
 
-	// Show typing indicator
-	s.ChannelTyping(m.ChannelID)
+	// Guilds can keep replies out of the channel: answer by DM, or delete
+	// channel replies after a while.
+	replyTo := m.ChannelID
+	if replyDM {
+		ch, err := s.UserChannelCreate(m.Author.ID)
+		if err != nil {
+			log.Printf("creating DM channel: %v", err)
+			return
+		}
+		replyTo, deleteAfter = ch.ID, 0
+		if err := s.MessageReactionAdd(m.ChannelID, m.ID, "📬"); err != nil {
+			log.Printf("reacting to mention: %v", err)
+		}
+	}
+	// Users who asked for plain text get replies without markdown, emoji,
+	// or link previews.
+	plain := b.agent.PlainText(m.Author.ID)
+	var sent []string
+	send := func(text string) {
+		msg, err := sendMessage(s, replyTo, text, plain)
+		if err != nil {
+			log.Printf("sending reply: %v", err)
+			return
+		}
+		sent = append(sent, msg.ID)
+	}
+	if deleteAfter > 0 {
+		defer func() { b.deleteLater(replyTo, sent, deleteAfter) }()
+	}
+
+	// Memory review is the owner's, so it only works in DMs; in a server any
+	// member could list or approve the proposed memories.
+	if args, ok := agent.ParseReviewCommand(content); ok {
+		reply := reviewDMOnly
+		if isDM {
+			var err error
+			if reply, err = b.agent.ReviewMemories(args); err != nil {
+				reply = "Couldn't review memories: " + err.Error()
+			}
+		}
+		if plain {
+			reply = plaintext.Strip(reply)
+		}
+		send(reply)
+		return
+	}
+
+	// Show the typing indicator until the turn finishes; Discord clears it
+	// after about 10 seconds, so keep refreshing it.
+	typingCtx, stopTyping := context.WithCancel(context.Background())
+	defer stopTyping()
+	go keepTyping(typingCtx, typingInterval, func() {
+		if err := s.ChannelTyping(replyTo); err != nil {
+			log.Printf("sending typing indicator: %v", err)
+		}
+	})
 
-	reply, err := b.agent.RunWithConversation(context.Background(), m.Author.ID, content)
+	// If the turn runs long, post an interim message describing the tools in
+	// use; the reply then replaces it.
+	progress := &turnProgress{}
+	interimSent := make(chan *discordgo.Message, 1)
+	timer := time.AfterFunc(interimDelay, func() {
+		msg, err := s.ChannelMessageSend(replyTo, progress.text())
+		if err != nil {
+			log.Printf("sending progress message: %v", err)
+		}
+		interimSent <- msg
+	})
+
+	ctx := agent.WithToolObserver(agent.WithProfile(context.Background(), profile), progress.observe)
+	reply, err := b.agent.RunWithConversation(ctx, m.Author.ID, content)
+	stopTyping()
+
+	var interim *discordgo.Message
+	if !timer.Stop() {
+		interim = <-interimSent // fired: wait for the send to finish
+	}
 	if err != nil {
 		log.Printf("agent error: %v", err)
-		s.ChannelMessageSend(m.ChannelID, "Something went wrong. Try again?")
+		reply = "Something went wrong. Try again?"
+	}
+	if plain {
+		reply = plaintext.Strip(reply)
+	}
+
+	// Discord caps message length; split if needed
+	chunks := SplitMessage(reply, MessageLimit)
+	if interim != nil {
+		sent = append(sent, interim.ID)
+		edit := discordgo.NewMessageEdit(replyTo, interim.ID).SetContent(chunks[0])
+		if plain {
+			edit.Flags = discordgo.MessageFlagsSuppressEmbeds
+		}
+		if _, err := s.ChannelMessageEditComplex(edit); err != nil {
+			log.Printf("replacing progress message: %v", err)
+		} else {
+			chunks = chunks[1:]
+		}
+	}
+	for _, chunk := range chunks {
+		send(chunk)
+	}
+}
+
+const reviewDMOnly = "Memory review only works in DMs."
+
+// deleteLater schedules the bot's replies for deletion after d. The schedule
+// is stored, so replies due while jot is down are deleted when it starts.
+func (b *Bot) deleteLater(channelID string, ids []string, d time.Duration) {
+	if len(ids) == 0 {
 		return
 	}
+	if err := b.db.ScheduleDeletions(channelID, ids, time.Now().Add(d)); err != nil {
+		log.Printf("scheduling reply deletion: %v", err)
+	}
+}
 
-	// Discord has a 2000 char limit; split if needed
-	for _, chunk := range splitMessage(reply, 2000) {
-		s.ChannelMessageSend(m.ChannelID, chunk)
+const (
+	deletionInterval = time.Minute // how often due reply deletions are swept
+	deletionBatch    = 50
+)
+
+// sweepDeletions deletes due replies right away, catching up on any that
+// fell due while jot was down, then every deletionInterval until Close.
+func (b *Bot) sweepDeletions() {
+	ticker := time.NewTicker(deletionInterval)
+	defer ticker.Stop()
+	for {
+		b.deleteDue()
+		select {
+		case <-b.stop:
+			return
+		case <-ticker.C:
+		}
 	}
 }
 
+// deleteDue deletes replies whose time is up. Messages are deleted one by
+// one: bulk delete needs Manage Messages even for the bot's own messages. A
+// reply that's already gone or out of reach is dropped; any other failure
+// ends the round, and the rest wait for the next sweep.
+func (b *Bot) deleteDue() {
+	for {
+		due, err := b.db.DueDeletions(deletionBatch)
+		if err != nil {
+			log.Printf("listing reply deletions: %v", err)
+			return
+		}
+		for _, p := range due {
+			if err := b.session.ChannelMessageDelete(p.ChannelID, p.MessageID); err != nil {
+				if !undeletable(err) {
+					log.Printf("deleting reply %s: %v; retrying later", p.MessageID, err)
+					return
+				}
+				log.Printf("deleting reply %s: %v; giving up", p.MessageID, err)
+			}
+			if err := b.db.DeletionDone(p.ID); err != nil {
+				log.Printf("reply deletions: %v", err)
+				return
+			}
+		}
+		if len(due) < deletionBatch {
+			return
+		}
+	}
+}
+
+// undeletable reports whether a failed delete can never succeed: the message
+// or channel is gone, or jot lost access to it.
+func undeletable(err error) bool {
+	var rest *discordgo.RESTError
+	if !errors.As(err, &rest) || rest.Response == nil {
+		return false
+	}
+	return rest.Response.StatusCode == http.StatusNotFound || rest.Response.StatusCode == http.StatusForbidden
+}
+
+// interimDelay is how long a turn may run before the bot posts a progress
+// message.
+const interimDelay = 5 * time.Second
+
+// typingInterval refreshes the typing indicator before Discord expires it.
+const typingInterval = 8 * time.Second
+
 func stripMention(s, userID string) string {
 	s = strings.ReplaceAll(s, "<@"+userID+">", "")
 	s = strings.ReplaceAll(s, "<@!"+userID+">", "")
 	return s
 }
 
-func splitMessage(s string, maxLen int) []string {
+// MessageLimit is Discord's maximum message length.
+const MessageLimit = 2000
+
+// SplitMessage splits s into chunks of at most maxLen bytes, breaking after a
+// newline where it can.
+func SplitMessage(s string, maxLen int) []string {
 	if len(s) <= maxLen {
 		return []string{s}
 	}
@@ -1,8 +1,17 @@
 package discord
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
 	"strings"
 	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/chris/jot/internal/agent"
+	"github.com/chris/jot/internal/db"
 )
 
 // --- stripMention ---
@@ -53,10 +62,10 @@ func TestStripMention_Empty(t *testing.T) {
 	}
 }
 
-// --- splitMessage ---
+// --- SplitMessage ---
 
 func TestSplitMessage_Short(t *testing.T) {
-	chunks := splitMessage("hello", 2000)
+	chunks := SplitMessage("hello", 2000)
 	if len(chunks) != 1 || chunks[0] != "hello" {
 		t.Errorf("expected single chunk 'hello', got %v", chunks)
 	}
@@ -64,7 +73,7 @@ func TestSplitMessage_Short(t *testing.T) {
 
 func TestSplitMessage_ExactLimit(t *testing.T) {
 	s := strings.Repeat("a", 2000)
-	chunks := splitMessage(s, 2000)
+	chunks := SplitMessage(s, 2000)
 	if len(chunks) != 1 {
 		t.Errorf("expected 1 chunk, got %d", len(chunks))
 	}
@@ -73,7 +82,7 @@ func TestSplitMessage_ExactLimit(t *testing.T) {
 func TestSplitMessage_SplitsAtNewline(t *testing.T) {
 	// 15 chars of "a", then newline, then 15 chars of "b" = 31 chars total
 	s := strings.Repeat("a", 15) + "\n" + strings.Repeat("b", 15)
-	chunks := splitMessage(s, 20)
+	chunks := SplitMessage(s, 20)
 
 	if len(chunks) != 2 {
 		t.Fatalf("expected 2 chunks, got %d: %v", len(chunks), chunks)
@@ -90,7 +99,7 @@ func TestSplitMessage_SplitsAtNewline(t *testing.T) {
 func TestSplitMessage_NoNewlineFallback(t *testing.T) {
 	// No newlines — should hard-split at maxLen
 	s := strings.Repeat("x", 50)
-	chunks := splitMessage(s, 20)
+	chunks := SplitMessage(s, 20)
 	if len(chunks) != 3 {
 		t.Fatalf("expected 3 chunks, got %d", len(chunks))
 	}
@@ -106,7 +115,7 @@ func TestSplitMessage_NoNewlineFallback(t *testing.T) {
 }
 
 func TestSplitMessage_Empty(t *testing.T) {
-	chunks := splitMessage("", 2000)
+	chunks := SplitMessage("", 2000)
 	if len(chunks) != 1 || chunks[0] != "" {
 		t.Errorf("expected single empty chunk, got %v", chunks)
 	}
@@ -115,10 +124,96 @@ func TestSplitMessage_Empty(t *testing.T) {
 func TestSplitMessage_MultipleNewlines(t *testing.T) {
 	// Should prefer the LAST newline before the limit
 	s := "line1\nline2\nline3\nline4"
-	chunks := splitMessage(s, 12)
+	chunks := SplitMessage(s, 12)
 
 	// "line1\nline2\n" is 12 chars — should split right there
 	if chunks[0] != "line1\nline2\n" {
 		t.Errorf("chunk[0] = %q, want %q", chunks[0], "line1\nline2\n")
 	}
 }
+
+// --- undeletable ---
+
+func TestUndeletable(t *testing.T) {
+	rest := func(code int) error {
+		return fmt.Errorf("deleting: %w", &discordgo.RESTError{Response: &http.Response{StatusCode: code}})
+	}
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"not found", rest(http.StatusNotFound), true},
+		{"forbidden", rest(http.StatusForbidden), true},
+		{"server error", rest(http.StatusBadGateway), false},
+		{"network", errors.New("connection reset"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := undeletable(tt.err); got != tt.want {
+				t.Errorf("undeletable = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// --- onMessage ---
+
+// sentMessages is an http.RoundTripper standing in for the Discord API: it
+// records the content of every message sent and answers with a stub.
+type sentMessages []string
+
+func (m *sentMessages) RoundTrip(req *http.Request) (*http.Response, error) {
+	var msg discordgo.MessageSend
+	if req.Body != nil {
+		_ = json.NewDecoder(req.Body).Decode(&msg)
+	}
+	*m = append(*m, msg.Content)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"id":"1"}`)),
+		Request:    req,
+	}, nil
+}
+
+func TestOnMessage_ReviewCommandDMOnly(t *testing.T) {
+	d, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	id, _, err := d.ProposeMemory("likes tea", "preference", "test", nil, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sent sentMessages
+	s, _ := discordgo.New("Bot test")
+	s.Client = &http.Client{Transport: &sent}
+	s.State.User = &discordgo.User{ID: "bot"}
+	b := &Bot{session: s, agent: agent.New(d, nil, 0), db: d, dms: make(map[string]*dmChannel)}
+
+	b.onMessage(s, &discordgo.MessageCreate{Message: &discordgo.Message{
+		ID: "10", ChannelID: "c1", GuildID: "g1", Content: "<@bot> !memories approve all",
+		Author: &discordgo.User{ID: "member"}, Mentions: []*discordgo.User{{ID: "bot"}},
+	}})
+	if len(sent) != 1 || sent[0] != reviewDMOnly {
+		t.Errorf("guild reply = %q, want %q", sent, reviewDMOnly)
+	}
+	proposed, err := d.ListProposedMemories()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(proposed) != 1 || proposed[0].ID != id {
+		t.Errorf("proposed memories = %v, want #%d still awaiting review", proposed, id)
+	}
+
+	sent = nil
+	b.onMessage(s, &discordgo.MessageCreate{Message: &discordgo.Message{
+		ID: "11", ChannelID: "dm1", Content: "!memories approve all", Author: &discordgo.User{ID: "owner"},
+	}})
+	if len(sent) != 1 || sent[0] != "Approved 1 memory(ies)." {
+		t.Errorf("DM reply = %q, want the memory approved", sent)
+	}
+}
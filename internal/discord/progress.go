@@ -0,0 +1,109 @@
+package discord
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// toolPhrases names what each tool looks at, for interim progress messages.
+var toolPhrases = map[string]string{
+	"list_things":          "your things",
+	"create_thing":         "your things",
+	"create_things":        "your things",
+	"update_thing":         "your things",
+	"complete_thing":       "your things",
+	"save_filter":          "your lists",
+	"run_filter":           "your lists",
+	"get_summary":          "overdue items",
+	"completed_things":     "what you finished",
+	"list_areas":           "your areas",
+	"set_area":             "your areas",
+	"list_thing_events":    "recent changes",
+	"save_memory":          "your memories",
+	"save_memories":        "your memories",
+	"search_memories":      "your memories",
+	"list_recent_memories": "your memories",
+	"get_memory_stats":     "your memories",
+	"update_memory":        "your memories",
+	"delete_memory":        "your memories",
+	"forget":               "your memories",
+	"search_conversations": "past conversations",
+	"list_schedules":       "your reminders",
+	"create_schedule":      "your reminders",
+	"update_schedule":      "your reminders",
+	"delete_schedule":      "your reminders",
+	"list_watches":         "your watches",
+	"create_watch":         "your watches",
+	"update_watch":         "your watches",
+	"delete_watch":         "your watches",
+	"run_watch":            "the web",
+	"list_watch_results":   "watch results",
+	"spawn_task":           "the details",
+	"start_job":            "background jobs",
+	"list_jobs":            "background jobs",
+	"describe_data_model":  "how jot stores things",
+	"list_capabilities":    "what I can do",
+	"set_style":            "your settings",
+}
+
+// progressText describes a turn in progress from the tools it has called,
+// e.g. "Checking your reminders and overdue items…".
+func progressText(tools []string) string {
+	var phrases []string
+	seen := make(map[string]bool)
+	for _, name := range tools {
+		p, ok := toolPhrases[name]
+		if !ok {
+			p = "a few things"
+		}
+		if !seen[p] {
+			seen[p] = true
+			phrases = append(phrases, p)
+		}
+	}
+	if len(phrases) == 0 {
+		return "Working on it…"
+	}
+	if len(phrases) > 3 {
+		phrases = append(phrases[:2], "more")
+	}
+	last := len(phrases) - 1
+	if last == 0 {
+		return "Checking " + phrases[0] + "…"
+	}
+	return "Checking " + strings.Join(phrases[:last], ", ") + " and " + phrases[last] + "…"
+}
+
+// turnProgress collects the tools an agent turn has called so far.
+type turnProgress struct {
+	mu    sync.Mutex
+	tools []string
+}
+
+func (p *turnProgress) observe(names []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tools = append(p.tools, names...)
+}
+
+func (p *turnProgress) text() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return progressText(p.tools)
+}
+
+// keepTyping calls typing now and then every interval until ctx is done.
+func keepTyping(ctx context.Context, interval time.Duration, typing func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		typing()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
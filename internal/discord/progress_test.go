@@ -0,0 +1,64 @@
+package discord
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/chris/jot/internal/llm"
+)
+
+func TestProgressText(t *testing.T) {
+	tests := []struct {
+		tools []string
+		want  string
+	}{
+		{nil, "Working on it…"},
+		{[]string{"list_schedules"}, "Checking your reminders…"},
+		{[]string{"list_schedules", "get_summary", "list_things", "update_thing"}, "Checking your reminders, overdue items and your things…"},
+		{[]string{"list_schedules", "get_summary", "list_things", "search_memories"}, "Checking your reminders, overdue items and more…"},
+		{[]string{"mystery_tool"}, "Checking a few things…"},
+	}
+	for _, tt := range tests {
+		if got := progressText(tt.tools); got != tt.want {
+			t.Errorf("progressText(%v) = %q, want %q", tt.tools, got, tt.want)
+		}
+	}
+}
+
+func TestToolPhrasesCoverAgentTools(t *testing.T) {
+	for _, tool := range llm.AgentTools {
+		if _, ok := toolPhrases[tool.Name]; !ok {
+			t.Errorf("toolPhrases has no entry for %s", tool.Name)
+		}
+	}
+}
+
+func TestKeepTyping(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := make(chan struct{}, 1)
+	done := make(chan struct{})
+	go func() {
+		keepTyping(ctx, time.Millisecond, func() {
+			select {
+			case calls <- struct{}{}:
+			default:
+			}
+		})
+		close(done)
+	}()
+
+	for range 3 {
+		select {
+		case <-calls:
+		case <-time.After(time.Second):
+			t.Fatal("typing was not refreshed")
+		}
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("keepTyping did not stop after cancel")
+	}
+}
@@ -0,0 +1,159 @@
+package discord
+
+import (
+	"log"
+	"slices"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// discordgo reconnects on its own (exponential backoff from 1s to 10m) and
+// tries to resume the gateway session, which replays missed events. After a
+// long sleep the session has usually expired, so it re-identifies instead and
+// anything sent in between is never delivered. The handlers here fill that
+// gap: after every reconnect, and on startup, the bot fetches DM history since
+// the last message it saw and handles what it missed. The last ID per channel
+// is persisted so downtime between runs is covered too.
+
+// catchUpLimit caps how many missed messages are fetched per channel.
+const catchUpLimit = 50
+
+// recentIDs is how many message IDs per channel are remembered for dedup, so
+// a message delivered by both a resumed session and a history fetch is only
+// handled once.
+const recentIDs = 50
+
+// dmChannel tracks the newest DM handled in a channel.
+type dmChannel struct {
+	last   string   // newest message ID seen
+	recent []string // most recent handled IDs, oldest first
+}
+
+// claim records a DM as handled and reports whether it is new. The mark is
+// saved before the message is handled, so a crash mid-turn can't replay it.
+func (b *Bot) claim(channelID, messageID string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.markSeen(channelID, messageID) {
+		return false
+	}
+	if b.db != nil {
+		if err := b.db.SetLastMessage(channelID, messageID); err != nil {
+			log.Print(err)
+		}
+	}
+	return true
+}
+
+func (b *Bot) markSeen(channelID, messageID string) bool {
+	ch := b.dms[channelID]
+	if ch == nil {
+		ch = &dmChannel{}
+		b.dms[channelID] = ch
+	}
+	if slices.Contains(ch.recent, messageID) {
+		return false
+	}
+	ch.recent = append(ch.recent, messageID)
+	if len(ch.recent) > recentIDs {
+		ch.recent = ch.recent[len(ch.recent)-recentIDs:]
+	}
+	if newerID(messageID, ch.last) {
+		ch.last = messageID
+	}
+	return true
+}
+
+// lastSeen returns the newest message ID seen in each DM channel.
+func (b *Bot) lastSeen() map[string]string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	seen := make(map[string]string, len(b.dms))
+	for id, ch := range b.dms {
+		seen[id] = ch.last
+	}
+	return seen
+}
+
+// onDisconnect marks the start of a gap. The newest IDs seen are snapshotted
+// now: once the connection is back, live DMs advance lastSeen past the gap
+// before the catch-up fetch could read it.
+func (b *Bot) onDisconnect(s *discordgo.Session, _ *discordgo.Disconnect) {
+	seen := b.lastSeen()
+	b.mu.Lock()
+	if b.disconnectedAt.IsZero() {
+		b.disconnectedAt = time.Now()
+		b.gapSeen = seen
+	}
+	b.mu.Unlock()
+	log.Printf("Discord disconnected; reconnecting")
+}
+
+// onReady fires on the first connect and whenever a reconnect had to
+// re-identify.
+func (b *Bot) onReady(s *discordgo.Session, _ *discordgo.Ready) {
+	b.mu.Lock()
+	first := !b.started
+	b.started = true
+	b.mu.Unlock()
+	if first {
+		b.registerCommands(s)
+		_, seen := b.endGap()
+		go b.catchUp(s, seen) // messages sent while jot wasn't running
+		return
+	}
+	b.reconnected(s, "re-identified")
+}
+
+func (b *Bot) onResumed(s *discordgo.Session, _ *discordgo.Resumed) {
+	b.reconnected(s, "resumed session")
+}
+
+func (b *Bot) reconnected(s *discordgo.Session, how string) {
+	since, seen := b.endGap()
+	if since.IsZero() {
+		return
+	}
+	log.Printf("Discord reconnected (%s) after %s", how, time.Since(since).Round(time.Second))
+	go b.catchUp(s, seen)
+}
+
+// endGap clears and returns when the current gap began and the IDs seen
+// before it.
+func (b *Bot) endGap() (time.Time, map[string]string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	since, seen := b.disconnectedAt, b.gapSeen
+	b.disconnectedAt, b.gapSeen = time.Time{}, nil
+	return since, seen
+}
+
+// catchUp handles DMs that arrived after the given newest-seen ID in each
+// channel, oldest first.
+func (b *Bot) catchUp(s *discordgo.Session, seen map[string]string) {
+	for channelID, after := range seen {
+		if after == "" {
+			continue
+		}
+		msgs, err := s.ChannelMessages(channelID, catchUpLimit, "", after, "")
+		if err != nil {
+			log.Printf("fetching missed messages in %s: %v", channelID, err)
+			continue
+		}
+		// Discord returns newest first.
+		for i := len(msgs) - 1; i >= 0; i-- {
+			log.Printf("handling missed message %s", msgs[i].ID)
+			b.onMessage(s, &discordgo.MessageCreate{Message: msgs[i]})
+		}
+	}
+}
+
+// newerID reports whether snowflake a is newer than b. Snowflakes grow with
+// time, so a longer ID is newer and equal lengths compare as strings.
+func newerID(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) > len(b)
+	}
+	return a > b
+}
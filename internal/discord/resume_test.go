@@ -0,0 +1,53 @@
+package discord
+
+import "testing"
+
+func TestClaim(t *testing.T) {
+	b := &Bot{dms: make(map[string]*dmChannel)}
+	if !b.claim("c", "100") || !b.claim("c", "99") {
+		t.Fatal("new messages should be claimed")
+	}
+	if b.claim("c", "100") {
+		t.Error("a replayed message should not be claimed twice")
+	}
+	if got := b.lastSeen()["c"]; got != "100" {
+		t.Errorf("lastSeen = %q, want newest ID 100", got)
+	}
+}
+
+func TestNewerID(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"1000", "999", true},
+		{"999", "1000", false},
+		{"1235", "1234", true},
+		{"1234", "1234", false},
+		{"1", "", true},
+	}
+	for _, tt := range tests {
+		if got := newerID(tt.a, tt.b); got != tt.want {
+			t.Errorf("newerID(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestGapSnapshot(t *testing.T) {
+	b := &Bot{dms: make(map[string]*dmChannel)}
+	b.claim("c", "100")
+	b.onDisconnect(nil, nil)
+	b.claim("c", "200") // a live DM right after reconnecting
+	b.onDisconnect(nil, nil)
+
+	since, seen := b.endGap()
+	if since.IsZero() {
+		t.Fatal("expected the gap start to be recorded")
+	}
+	if seen["c"] != "100" {
+		t.Errorf("catch-up starts after %q, want 100 (seen before the gap)", seen["c"])
+	}
+	if since, seen := b.endGap(); !since.IsZero() || seen != nil {
+		t.Error("endGap should clear the gap")
+	}
+}
@@ -41,6 +41,12 @@ type anthRequest struct {
 	System      []anthText    `json:"system,omitempty"`
 	Messages    []anthMessage `json:"messages"`
 	Tools       []anthTool    `json:"tools,omitempty"`
+	ToolChoice  *anthChoice   `json:"tool_choice,omitempty"`
+}
+
+type anthChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
 }
 
 type anthText struct {
@@ -78,7 +84,7 @@ type anthResponse struct {
 	} `json:"error,omitempty"`
 }
 
-func (c *AnthropicClient) Chat(ctx context.Context, systemPrompt string, messages []Message, tools []Tool) (*Response, error) {
+func (c *AnthropicClient) Chat(ctx context.Context, systemPrompt string, messages []Message, tools []Tool, opts ...ChatOption) (*Response, error) {
 	// Build tools
 	anthTools := make([]anthTool, len(tools))
 	for i, t := range tools {
@@ -152,6 +158,9 @@ func (c *AnthropicClient) Chat(ctx context.Context, systemPrompt string, message
 		Messages:    anthMsgs,
 		Tools:       anthTools,
 	}
+	if len(anthTools) > 0 {
+		reqBody.ToolChoice = anthropicToolChoice(ApplyChatOptions(opts).ToolChoice)
+	}
 
 	body, err := json.Marshal(reqBody)
 	if err != nil {
@@ -212,3 +221,32 @@ func (c *AnthropicClient) Chat(ctx context.Context, systemPrompt string, message
 
 	return result, nil
 }
+
+// anthropicToolChoice maps a ToolChoice to Anthropic's tool_choice field.
+// Auto is the API default, so it is omitted.
+func anthropicToolChoice(tc ToolChoice) *anthChoice {
+	switch tc.Mode {
+	case ToolChoiceAny:
+		return &anthChoice{Type: "any"}
+	case ToolChoiceTool:
+		return &anthChoice{Type: "tool", Name: tc.Name}
+	case ToolChoiceNone:
+		return &anthChoice{Type: "none"}
+	}
+	return nil
+}
+
+// Generate forces a single tool whose input schema is the requested schema;
+// the tool input is the structured output.
+func (c *AnthropicClient) Generate(ctx context.Context, prompt string, schema Schema) (json.RawMessage, error) {
+	tool := Tool{Name: schema.Name, Description: schema.Description, Parameters: schema.Parameters}
+	system := fmt.Sprintf("Return your answer by calling the %s tool.", schema.Name)
+	resp, err := c.Chat(ctx, system, []Message{{Role: "user", Content: prompt}}, []Tool{tool}, ForceTool(schema.Name))
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.ToolCalls) == 0 {
+		return nil, fmt.Errorf("anthropic generate: no %s tool call in response", schema.Name)
+	}
+	return json.Marshal(resp.ToolCalls[0].Params)
+}
@@ -2,6 +2,7 @@ package llm
 
 import (
 	"context"
+	"encoding/json"
 	"log"
 	"strings"
 	"time"
@@ -32,15 +33,69 @@ type Tool struct {
 }
 
 type Client interface {
-	Chat(ctx context.Context, systemPrompt string, messages []Message, tools []Tool) (*Response, error)
+	Chat(ctx context.Context, systemPrompt string, messages []Message, tools []Tool, opts ...ChatOption) (*Response, error)
+	// Generate returns a JSON object matching schema, using the provider's
+	// native structured output. For internal pipelines, not conversation.
+	Generate(ctx context.Context, prompt string, schema Schema) (json.RawMessage, error)
+}
+
+// Schema describes the JSON object Generate must return. Parameters must fit
+// OpenAI's strict mode: every object sets "additionalProperties": false and
+// lists all its properties as required.
+type Schema struct {
+	Name        string         // identifier: letters, digits, _ and - only
+	Description string         // what the output is for
+	Parameters  map[string]any // JSON Schema; the root must be an object
+}
+
+// Tool choice modes. The zero ToolChoice behaves like ToolChoiceAuto.
+const (
+	ToolChoiceAuto = "auto" // model decides whether to call a tool
+	ToolChoiceAny  = "any"  // model must call at least one tool
+	ToolChoiceTool = "tool" // model must call the tool named in ToolChoice.Name
+	ToolChoiceNone = "none" // model must not call tools
+)
+
+// ToolChoice controls whether and which tool the model must call.
+type ToolChoice struct {
+	Mode string
+	Name string
+}
+
+// ChatOptions holds per-call settings applied by ChatOption functions.
+type ChatOptions struct {
+	ToolChoice ToolChoice
+}
+
+// ChatOption configures a single Chat call.
+type ChatOption func(*ChatOptions)
+
+// WithToolChoice sets the tool choice for a Chat call.
+func WithToolChoice(tc ToolChoice) ChatOption {
+	return func(o *ChatOptions) { o.ToolChoice = tc }
+}
+
+// ForceTool makes the model call the named tool, so structured flows get a
+// tool call back instead of depending on prompt compliance.
+func ForceTool(name string) ChatOption {
+	return WithToolChoice(ToolChoice{Mode: ToolChoiceTool, Name: name})
+}
+
+// ApplyChatOptions folds opts into a ChatOptions value.
+func ApplyChatOptions(opts []ChatOption) ChatOptions {
+	var o ChatOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
 }
 
 const MaxRetries = 3
 
 // ChatWithRetry wraps Client.Chat with retry on rate limit (429) errors.
-func ChatWithRetry(ctx context.Context, client Client, systemPrompt string, messages []Message, tools []Tool) (*Response, error) {
+func ChatWithRetry(ctx context.Context, client Client, systemPrompt string, messages []Message, tools []Tool, opts ...ChatOption) (*Response, error) {
 	for attempt := 0; ; attempt++ {
-		resp, err := client.Chat(ctx, systemPrompt, messages, tools)
+		resp, err := client.Chat(ctx, systemPrompt, messages, tools, opts...)
 		if err == nil {
 			return resp, nil
 		}
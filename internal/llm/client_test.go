@@ -0,0 +1,80 @@
+package llm
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestApplyChatOptions(t *testing.T) {
+	if got := ApplyChatOptions(nil); got.ToolChoice != (ToolChoice{}) {
+		t.Errorf("expected zero options, got %+v", got)
+	}
+	got := ApplyChatOptions([]ChatOption{WithToolChoice(ToolChoice{Mode: ToolChoiceAny}), ForceTool("save_memory")})
+	if got.ToolChoice.Mode != ToolChoiceTool || got.ToolChoice.Name != "save_memory" {
+		t.Errorf("expected last option to win, got %+v", got.ToolChoice)
+	}
+}
+
+func TestAnthropicToolChoice(t *testing.T) {
+	tests := []struct {
+		tc   ToolChoice
+		want string
+	}{
+		{ToolChoice{}, "null"},
+		{ToolChoice{Mode: ToolChoiceAuto}, "null"},
+		{ToolChoice{Mode: ToolChoiceAny}, `{"type":"any"}`},
+		{ToolChoice{Mode: ToolChoiceTool, Name: "submit"}, `{"type":"tool","name":"submit"}`},
+		{ToolChoice{Mode: ToolChoiceNone}, `{"type":"none"}`},
+	}
+	for _, tt := range tests {
+		b, _ := json.Marshal(anthropicToolChoice(tt.tc))
+		if string(b) != tt.want {
+			t.Errorf("anthropicToolChoice(%+v) = %s, want %s", tt.tc, b, tt.want)
+		}
+	}
+}
+
+func TestOpenAIToolChoice(t *testing.T) {
+	tests := []struct {
+		tc     ToolChoice
+		want   string
+		wantOK bool
+	}{
+		{ToolChoice{}, "", false},
+		{ToolChoice{Mode: ToolChoiceAny}, `"required"`, true},
+		{ToolChoice{Mode: ToolChoiceTool, Name: "submit"}, `{"function":{"name":"submit"},"type":"function"}`, true},
+		{ToolChoice{Mode: ToolChoiceNone}, `"none"`, true},
+	}
+	for _, tt := range tests {
+		choice, ok := openAIToolChoice(tt.tc)
+		if ok != tt.wantOK {
+			t.Errorf("openAIToolChoice(%+v) ok = %v, want %v", tt.tc, ok, tt.wantOK)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		b, _ := json.Marshal(choice)
+		if string(b) != tt.want {
+			t.Errorf("openAIToolChoice(%+v) = %s, want %s", tt.tc, b, tt.want)
+		}
+	}
+}
+
+func TestOpenAIResponseFormatStrict(t *testing.T) {
+	schema := Schema{Name: "items", Description: "test", Parameters: map[string]any{"type": "object"}}
+	b, _ := json.Marshal(openAIResponseFormat(schema))
+	var got struct {
+		Type       string `json:"type"`
+		JSONSchema struct {
+			Name   string `json:"name"`
+			Strict bool   `json:"strict"`
+		} `json:"json_schema"`
+	}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Type != "json_schema" || got.JSONSchema.Name != "items" || !got.JSONSchema.Strict {
+		t.Errorf("response format = %s, want a strict json_schema", b)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/openai/openai-go/v3"
 	"github.com/openai/openai-go/v3/option"
 	"github.com/openai/openai-go/v3/packages/param"
+	"github.com/openai/openai-go/v3/shared"
 )
 
 type OpenAIClient struct {
@@ -31,7 +32,7 @@ func NewOpenAIClient(apiKey, model, baseURL string, temperature *float64) *OpenA
 	return &OpenAIClient{client: client, model: model, temperature: temperature}
 }
 
-func (c *OpenAIClient) Chat(ctx context.Context, systemPrompt string, messages []Message, tools []Tool) (*Response, error) {
+func (c *OpenAIClient) Chat(ctx context.Context, systemPrompt string, messages []Message, tools []Tool, opts ...ChatOption) (*Response, error) {
 	// Convert tools
 	oaiTools := make([]openai.ChatCompletionToolUnionParam, len(tools))
 	for i, t := range tools {
@@ -91,6 +92,11 @@ func (c *OpenAIClient) Chat(ctx context.Context, systemPrompt string, messages [
 	if c.temperature != nil {
 		params.Temperature = param.NewOpt(*c.temperature)
 	}
+	if len(oaiTools) > 0 {
+		if choice, ok := openAIToolChoice(ApplyChatOptions(opts).ToolChoice); ok {
+			params.ToolChoice = choice
+		}
+	}
 
 	resp, err := c.client.Chat.Completions.New(ctx, params)
 	if err != nil {
@@ -119,3 +125,58 @@ func (c *OpenAIClient) Chat(ctx context.Context, systemPrompt string, messages [
 
 	return result, nil
 }
+
+// openAIToolChoice maps a ToolChoice to OpenAI's tool_choice param. It
+// reports false for auto, which is the API default.
+func openAIToolChoice(tc ToolChoice) (openai.ChatCompletionToolChoiceOptionUnionParam, bool) {
+	switch tc.Mode {
+	case ToolChoiceAny:
+		return openai.ChatCompletionToolChoiceOptionUnionParam{OfAuto: param.NewOpt("required")}, true
+	case ToolChoiceTool:
+		return openai.ToolChoiceOptionFunctionToolChoice(openai.ChatCompletionNamedToolChoiceFunctionParam{Name: tc.Name}), true
+	case ToolChoiceNone:
+		return openai.ChatCompletionToolChoiceOptionUnionParam{OfAuto: param.NewOpt("none")}, true
+	}
+	return openai.ChatCompletionToolChoiceOptionUnionParam{}, false
+}
+
+// Generate uses a strict json_schema response format so the reply content is
+// the structured output, guaranteed to match the schema.
+func (c *OpenAIClient) Generate(ctx context.Context, prompt string, schema Schema) (json.RawMessage, error) {
+	params := openai.ChatCompletionNewParams{
+		Model:          openai.ChatModel(c.model),
+		Messages:       []openai.ChatCompletionMessageParamUnion{openai.UserMessage(prompt)},
+		ResponseFormat: openAIResponseFormat(schema),
+	}
+	if c.temperature != nil {
+		params.Temperature = param.NewOpt(*c.temperature)
+	}
+
+	resp, err := c.client.Chat.Completions.New(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("openai generate: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("openai generate: no choices in response")
+	}
+	content := resp.Choices[0].Message.Content
+	if !json.Valid([]byte(content)) {
+		return nil, fmt.Errorf("openai generate: invalid JSON in response: %.200s", content)
+	}
+	return json.RawMessage(content), nil
+}
+
+// openAIResponseFormat asks for structured output in strict mode, where the
+// API constrains decoding to the schema rather than just aiming for it.
+func openAIResponseFormat(schema Schema) openai.ChatCompletionNewParamsResponseFormatUnion {
+	return openai.ChatCompletionNewParamsResponseFormatUnion{
+		OfJSONSchema: &shared.ResponseFormatJSONSchemaParam{
+			JSONSchema: shared.ResponseFormatJSONSchemaJSONSchemaParam{
+				Name:        schema.Name,
+				Description: openai.String(schema.Description),
+				Schema:      schema.Parameters,
+				Strict:      openai.Bool(true),
+			},
+		},
+	}
+}
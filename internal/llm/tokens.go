@@ -1,6 +1,11 @@
 package llm
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"unicode/utf8"
+)
 
 // charsPerToken is the average number of characters per token.
 // This is a rough heuristic — real tokenizers vary, but 4 chars/token
@@ -23,9 +28,7 @@ func EstimateMessageTokens(m Message) int {
 	tokens += EstimateTokens(m.Content)
 	for _, tc := range m.ToolCalls {
 		tokens += EstimateTokens(tc.Name)
-		if params, err := json.Marshal(tc.Params); err == nil {
-			tokens += EstimateTokens(string(params))
-		}
+		tokens += (jsonLen(tc.Params) + charsPerToken - 1) / charsPerToken
 		tokens += 4 // tool call framing overhead
 	}
 	if m.ToolCallID != "" {
@@ -57,3 +60,110 @@ func EstimateToolsTokens(tools []Tool) int {
 	}
 	return total
 }
+
+// jsonLen returns len(json.Marshal(v)), or 0 if v doesn't marshal. Tool call
+// params are counted on every trim, so the types that decoded JSON produces
+// are measured without encoding them.
+func jsonLen(v any) int {
+	switch v := v.(type) {
+	case nil:
+		return 4 // null
+	case string:
+		return quotedLen(v)
+	case bool:
+		if v {
+			return 4
+		}
+		return 5
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return 0
+		}
+		return floatLen(v)
+	case int:
+		var buf [20]byte
+		return len(strconv.AppendInt(buf[:0], int64(v), 10))
+	case []any:
+		n := 2
+		for i, e := range v {
+			if i > 0 {
+				n++
+			}
+			en := jsonLen(e)
+			if en == 0 {
+				return 0
+			}
+			n += en
+		}
+		return n
+	case map[string]any:
+		if v == nil {
+			return 4
+		}
+		n := 2
+		for k, e := range v {
+			if n > 2 {
+				n++
+			}
+			en := jsonLen(e)
+			if en == 0 {
+				return 0
+			}
+			n += quotedLen(k) + 1 + en
+		}
+		return n
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return len(b)
+}
+
+// quotedLen is the length of s as a JSON string, with encoding/json's
+// escaping (including its HTML-safe escapes). Invalid UTF-8 is the one case
+// where encoders differ.
+func quotedLen(s string) int {
+	n := 2
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			switch {
+			case c == '"' || c == '\\' || c == '\n' || c == '\r' || c == '\t' || c == '\b' || c == '\f':
+				n += 2
+			case c < 0x20 || c == '<' || c == '>' || c == '&':
+				n += 6
+			default:
+				n++
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			n += 3 // replacement character; older encoders write \ufffd, close enough
+		case r == '\u2028' || r == '\u2029':
+			n += 6
+		default:
+			n += size
+		}
+		i += size
+	}
+	return n
+}
+
+// floatLen is the length of f as encoding/json formats it.
+func floatLen(f float64) int {
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	var buf [32]byte
+	b := strconv.AppendFloat(buf[:0], f, format, -1, 64)
+	n := len(b)
+	if format == 'e' && n >= 4 && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+		n-- // json writes e-07 as e-7
+	}
+	return n
+}
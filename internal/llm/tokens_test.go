@@ -1,6 +1,10 @@
 package llm
 
-import "testing"
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
 
 func TestEstimateTokens(t *testing.T) {
 	tests := []struct {
@@ -104,3 +108,21 @@ func TestEstimateToolsTokens_AllAgentTools(t *testing.T) {
 	}
 	t.Logf("AgentTools estimated tokens: %d", got)
 }
+
+func TestJSONLen(t *testing.T) {
+	for _, v := range []any{
+		nil, true, false, "", "plain", `quote " and \ slash`, "tab\tnew\nline\x01", "<b>&</b>",
+		"naïve café 🎉", "bad \xff utf8", "line sep", float64(0), float64(42), -3.5, 1e21, 1.5e-7, 123456789.125,
+		7, []any{}, []any{"a", float64(1), nil}, map[string]any(nil), map[string]any{},
+		map[string]any{"title": "Draft", "tags": []any{"work", "q3"}, "limit": float64(20), "nested": map[string]any{"ok": true}},
+		[]string{"typed", "slice"},
+	} {
+		want, _ := json.Marshal(v)
+		if got := jsonLen(v); got != len(want) {
+			t.Errorf("jsonLen(%#v) = %d, want %d (%s)", v, got, len(want), want)
+		}
+	}
+	if got := jsonLen(map[string]any{"x": math.NaN()}); got != 0 {
+		t.Errorf("jsonLen with NaN = %d, want 0 (unmarshalable)", got)
+	}
+}
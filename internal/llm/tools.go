@@ -3,11 +3,26 @@ package llm
 var AgentTools = []Tool{
 	{
 		Name:        "list_things",
-		Description: "List things, optionally filtered by status, priority, or tag. Items past their due date are marked overdue.",
+		Description: "List things, optionally filtered by status, priority, or tag. Items past their due date are marked overdue. Returns at most 50 unless limit is set; paged results include total and offset.",
 		Parameters: obj(map[string]any{
 			"status":   prop("string", "Filter by status: open, active, done, dropped"),
 			"priority": prop("string", "Filter by priority: low, normal, high, urgent"),
 			"tag":      prop("string", "Filter by tag"),
+			"sort":     prop("string", "priority (default), due_date (soonest first), newest, oldest"),
+			"limit":    prop("integer", "Max things to return (default 50)"),
+			"offset":   prop("integer", "Skip this many things (for paging)"),
+		}),
+	},
+	{
+		Name:        "get_summary",
+		Description: "Overview of things: counts by status, open items that are overdue, due today, or due in the next 6 days, and recently created items. Pass tag or area to scope it; unscoped, it also rolls up each area.",
+		Parameters: obj(map[string]any{
+			"tag":           prop("string", "Only include things with this tag"),
+			"area":          prop("string", "Only include things tagged into this area (e.g. work, health)"),
+			"recent_days":   prop("integer", "Window for recently created items in days (default 7)"),
+			"recent_limit":  prop("integer", "Max recent items (default 5)"),
+			"list_limit":    prop("integer", "Max items per due bucket (default 10)"),
+			"open_statuses": map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": "Statuses that count as open (default open, active)"},
 		}),
 	},
 	{
@@ -21,6 +36,23 @@ var AgentTools = []Tool{
 			"tags":     map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": "Tags for categorization"},
 		}, "title"),
 	},
+	{
+		Name:        "create_things",
+		Description: "Create several things at once (e.g. the steps of a project). All are created in one transaction; returns their IDs in order.",
+		Parameters: objReq(map[string]any{
+			"things": map[string]any{
+				"type":        "array",
+				"description": "Things to create, each with the same fields as create_thing",
+				"items": objReq(map[string]any{
+					"title":    prop("string", "What the thing is"),
+					"notes":    prop("string", "Additional details"),
+					"priority": prop("string", "Priority: low, normal, high, urgent"),
+					"due_date": prop("string", "Due date in YYYY-MM-DD format"),
+					"tags":     map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+				}, "title"),
+			},
+		}, "things"),
+	},
 	{
 		Name:        "update_thing",
 		Description: "Update a thing by ID. Can change title, notes, status, priority, due_date, or tags.",
@@ -41,6 +73,61 @@ var AgentTools = []Tool{
 			"id": prop("integer", "Thing ID to complete"),
 		}, "id"),
 	},
+	{
+		Name:        "save_filter",
+		Description: "Save a named list of things (e.g. \"errands\", \"this week's focus\") to run later with run_filter. Replaces a filter with the same name; set delete to remove it.",
+		Parameters: objReq(map[string]any{
+			"name":            prop("string", "Filter name"),
+			"status":          prop("string", "Status: open, active, done, dropped"),
+			"priority":        prop("string", "Priority: low, normal, high, urgent"),
+			"tags":            map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": "Match things with any of these tags"},
+			"due_within_days": prop("integer", "Only things due within N days of when the filter runs (overdue included)"),
+			"sort":            prop("string", "priority, due_date, newest, oldest"),
+			"limit":           prop("integer", "Max things"),
+			"check_in":        prop("boolean", "Include this list in check-ins"),
+			"delete":          prop("boolean", "Delete the filter instead"),
+		}, "name"),
+	},
+	{
+		Name:        "run_filter",
+		Description: "Run a saved filter by name, or all check-in filters with check_in. With neither, lists saved filters.",
+		Parameters: obj(map[string]any{
+			"name":     prop("string", "Filter name"),
+			"check_in": prop("boolean", "Run every filter marked for check-ins"),
+		}),
+	},
+	{
+		Name:        "list_areas",
+		Description: "List areas of focus (work, health, home, ...) with their tags, and per-area counts of open, overdue, and recently completed things. Use for balance questions.",
+		Parameters: obj(map[string]any{
+			"since_days": prop("integer", "Count completions from the last N days (default 7)"),
+		}),
+	},
+	{
+		Name:        "set_area",
+		Description: "Create an area or map tags into it (a tag belongs to one area; mapping moves it). Set delete to remove the area.",
+		Parameters: objReq(map[string]any{
+			"name":   prop("string", "Area name"),
+			"tags":   map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": "Tags to map into the area"},
+			"delete": prop("boolean", "Delete the area instead (things and tags are kept)"),
+		}, "name"),
+	},
+	{
+		Name:        "completed_things",
+		Description: "List things completed in a date range (inclusive, user's local dates). Defaults to the last 7 days. Use for weekly reviews and \"what did I finish?\".",
+		Parameters: obj(map[string]any{
+			"from": prop("string", "Start date YYYY-MM-DD"),
+			"to":   prop("string", "End date YYYY-MM-DD (default today)"),
+		}),
+	},
+	{
+		Name:        "list_thing_events",
+		Description: "List automatic changes to things: priority escalations as due dates approach (kind 'escalated') and active items untouched for 14+ days (kind 'stale').",
+		Parameters: obj(map[string]any{
+			"since_days": prop("integer", "Look back this many days (default 7)"),
+			"kind":       prop("string", "Filter by kind: escalated, stale"),
+		}),
+	},
 	{
 		Name:        "save_memory",
 		Description: "Save a memory for future reference. Use this to remember important context, decisions, blockers, user preferences, or events. Be specific and include temporal context (e.g. 'as of Feb 2026'). Choose the right category. Use category 'habit' to log recurring activity entries like 'gym: done' or 'meditation: skipped'.",
@@ -52,6 +139,22 @@ var AgentTools = []Tool{
 			"expires_at": prop("string", "Optional expiry datetime (YYYY-MM-DD HH:MM:SS). Omit for permanent memories."),
 		}, "content", "category"),
 	},
+	{
+		Name:        "save_memories",
+		Description: "Save several memories in one transaction, e.g. a week of habit logs. Same fields and dedup as save_memory.",
+		Parameters: objReq(map[string]any{
+			"memories": map[string]any{
+				"type": "array",
+				"items": objReq(map[string]any{
+					"content":    prop("string", "What to remember"),
+					"category":   prop("string", "As in save_memory"),
+					"tags":       map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+					"thing_id":   prop("integer", "Thing to link"),
+					"expires_at": prop("string", "YYYY-MM-DD HH:MM:SS"),
+				}, "content", "category"),
+			},
+		}, "memories"),
+	},
 	{
 		Name:        "search_memories",
 		Description: "Search past memories by text, category, tag, or thing. Returns matches ordered by recency. Use this to recall context before answering questions.",
@@ -72,6 +175,23 @@ var AgentTools = []Tool{
 			"limit":    prop("integer", "Max results (default 10)"),
 		}),
 	},
+	{
+		Name:        "get_memory_stats",
+		Description: "Count memories by category, plus proposed, expiring, and unresolved blockers with the oldest one's age in days.",
+		Parameters:  obj(nil),
+	},
+	{
+		Name:        "set_style",
+		Description: "Save how the user wants you to talk to them; persists across conversations. Only given fields change.",
+		Parameters: obj(map[string]any{
+			"tone":     map[string]any{"type": "string", "enum": []string{"terse", "balanced", "chatty"}},
+			"emoji":    prop("boolean", "Use emoji"),
+			"plain":    prop("boolean", "Plain text for screen readers: no markdown or emoji"),
+			"approach": map[string]any{"type": "string", "enum": []string{"coaching", "neutral"}},
+			"notes":    prop("string", "Other wishes in the user's words, e.g. 'be more blunt'. Replaces earlier notes"),
+			"reset":    prop("boolean", "Clear the style first"),
+		}),
+	},
 	{
 		Name:        "update_memory",
 		Description: "Update a memory by ID. Can change content, category, tags, or expires_at. Use this to correct or enrich existing memories.",
@@ -90,6 +210,23 @@ var AgentTools = []Tool{
 			"id": prop("integer", "Memory ID to delete"),
 		}, "id"),
 	},
+	{
+		Name:        "forget",
+		Description: "Forget everything mentioning a name or topic: matching memories, things (and their linked memories), conversation summaries, and conversation history. Call without confirm first to list what would be deleted and get a confirm_token. Show the user the list and end your turn; only if they agree in their next message, call again with confirm set to that token. Exactly the listed items are deleted.",
+		Parameters: objReq(map[string]any{
+			"query":   prop("string", "Name or phrase to forget, e.g. 'Acme Corp'"),
+			"confirm": prop("string", "confirm_token from the preview, once the user has agreed. Omit to preview candidates."),
+		}, "query"),
+	},
+	{
+		Name:        "search_conversations",
+		Description: "Search past conversation transcripts (what the user and you actually said) by text. Use when the user asks what they told you before and search_memories doesn't have it.",
+		Parameters: objReq(map[string]any{
+			"query": prop("string", "Words or phrase to search for"),
+			"since": prop("string", "Only messages on or after this date (YYYY-MM-DD)"),
+			"limit": prop("integer", "Max results (default 10)"),
+		}, "query"),
+	},
 	{
 		Name:        "list_schedules",
 		Description: "List all schedules, including both recurring (cron) and one-shot reminders.",
@@ -99,20 +236,28 @@ var AgentTools = []Tool{
 		Name:        "create_schedule",
 		Description: "Create a schedule. For recurring tasks, provide cron_expr. For one-shot reminders, provide fire_at instead (local time).",
 		Parameters: objReq(map[string]any{
-			"name":      prop("string", "Unique name slug, e.g. 'weekly-review' or 'reminder-call-dentist'"),
-			"cron_expr": prop("string", "Cron expression for recurring schedules, e.g. '0 9 * * *'. Omit for one-shot reminders."),
-			"prompt":    prop("string", "What to tell the agent when this schedule fires"),
-			"fire_at":   prop("string", "Local datetime for one-shot reminders: 'YYYY-MM-DD HH:MM:SS'. Omit for recurring schedules."),
+			"name":         prop("string", "Unique name slug, e.g. 'weekly-review' or 'reminder-call-dentist'"),
+			"cron_expr":    prop("string", "Cron expression for recurring schedules, e.g. '0 9 * * *'. Omit for one-shot reminders."),
+			"prompt":       prop("string", "What to tell the agent when this schedule fires"),
+			"fire_at":      prop("string", "Local datetime for one-shot reminders: 'YYYY-MM-DD HH:MM:SS'. Omit for recurring schedules."),
+			"check_in":     prop("boolean", "Recurring only: append precomputed check-in context to the prompt"),
+			"digest":       prop("boolean", "Recurring only: append a list of the last 24h of jot's changes"),
+			"memory_days":  prop("integer", "Check-in memory window in days (default 7; e.g. 31 for a monthly review)"),
+			"memory_limit": prop("integer", "Max memories in check-in context (default 20)"),
 		}, "name", "prompt"),
 	},
 	{
 		Name:        "update_schedule",
-		Description: "Update a schedule by name. Can change cron_expr, prompt, or enabled.",
+		Description: "Update a schedule by name. Can change cron_expr, prompt, enabled, check_in, digest, memory_days, or memory_limit.",
 		Parameters: objReq(map[string]any{
-			"name":      prop("string", "Schedule name to update"),
-			"cron_expr": prop("string", "New cron expression"),
-			"prompt":    prop("string", "New prompt"),
-			"enabled":   prop("boolean", "true to enable, false to disable"),
+			"name":         prop("string", "Schedule name to update"),
+			"cron_expr":    prop("string", "New cron expression"),
+			"prompt":       prop("string", "New prompt"),
+			"enabled":      prop("boolean", "true to enable, false to disable"),
+			"check_in":     prop("boolean", "true to append precomputed check-in context to the prompt"),
+			"digest":       prop("boolean", "true to append the changes digest"),
+			"memory_days":  prop("integer", "Check-in memory window in days"),
+			"memory_limit": prop("integer", "Max memories in check-in context"),
 		}, "name"),
 	},
 	{
@@ -122,6 +267,30 @@ var AgentTools = []Tool{
 			"name": prop("string", "Schedule name to delete"),
 		}, "name"),
 	},
+	{
+		Name:        "spawn_task",
+		Description: "Delegate a scoped lookup needing many tool calls to a sub-agent with its own context; returns only its summary.",
+		Parameters: objReq(map[string]any{
+			"task":  prop("string", "Self-contained instructions, including what the summary should cover"),
+			"tools": map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": "Tool names or globs to narrow its read-only tools (list_*, search_*, get_*) to"},
+		}, "task"),
+	},
+	{
+		Name:        "start_job",
+		Description: "Queue a long task (research, multi-step summaries) to run in the background. The result is delivered to the user when done; tell them so and end the turn.",
+		Parameters: objReq(map[string]any{
+			"prompt": prop("string", "Complete, self-contained instructions for the job"),
+			"title":  prop("string", "Short label shown on delivery"),
+		}, "prompt"),
+	},
+	{
+		Name:        "list_jobs",
+		Description: "List recent background jobs, or get one by id (includes its result).",
+		Parameters: obj(map[string]any{
+			"id":     prop("integer", "Job ID"),
+			"status": prop("string", "Filter: queued, running, done, failed"),
+		}),
+	},
 	{
 		Name:        "list_watches",
 		Description: "List all web watches (URL monitors that extract info on a schedule).",
@@ -171,6 +340,16 @@ var AgentTools = []Tool{
 			"limit":           prop("integer", "Max results to return (default 50)"),
 		}, "name"),
 	},
+	{
+		Name:        "describe_data_model",
+		Description: "Describe jot's tables: fields, allowed values (statuses, categories), and relationships.",
+		Parameters:  obj(nil),
+	},
+	{
+		Name:        "list_capabilities",
+		Description: "List what jot can do here, by area, and which integrations are on. Use when asked what you can do.",
+		Parameters:  obj(nil),
+	},
 }
 
 // Helper functions for building JSON Schema objects.
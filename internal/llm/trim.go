@@ -37,19 +37,19 @@ func TrimMessages(messages []Message, maxTokens int) []Message {
 		dropUntil++
 	}
 
-	// Rebuild the message slice from the surviving groups.
-	var trimmed []Message
-	for _, g := range groups[dropUntil:] {
-		trimmed = append(trimmed, g.messages...)
+	// Groups are contiguous, so the survivors are a suffix of messages.
+	dropped := 0
+	for _, g := range groups[:dropUntil] {
+		dropped += len(g.messages)
 	}
-	return trimmed
+	return messages[dropped:]
 }
 
 // messageGroup is a logical unit of conversation that must be kept or
 // dropped as a whole. For example, an assistant message with tool calls
 // plus all the subsequent tool-result messages form one group.
 type messageGroup struct {
-	messages []Message
+	messages []Message // a subslice of the input, not a copy
 	tokens   int
 }
 
@@ -60,30 +60,29 @@ type messageGroup struct {
 //   - An assistant message with tool calls + the following tool-result
 //     messages form a single group.
 func groupMessages(messages []Message) []messageGroup {
-	var groups []messageGroup
+	groups := make([]messageGroup, 0, len(messages))
 	i := 0
 	for i < len(messages) {
 		msg := messages[i]
 
 		// Assistant message with tool calls: group it with subsequent tool results.
 		if msg.Role == "assistant" && len(msg.ToolCalls) > 0 {
-			group := messageGroup{}
-			group.messages = append(group.messages, msg)
-			group.tokens += EstimateMessageTokens(msg)
+			start := i
+			group := messageGroup{tokens: EstimateMessageTokens(msg)}
 			i++
 			// Collect all tool result messages that follow.
 			for i < len(messages) && messages[i].ToolCallID != "" {
-				group.messages = append(group.messages, messages[i])
 				group.tokens += EstimateMessageTokens(messages[i])
 				i++
 			}
+			group.messages = messages[start:i]
 			groups = append(groups, group)
 			continue
 		}
 
 		// Any other message is its own group.
 		groups = append(groups, messageGroup{
-			messages: []Message{msg},
+			messages: messages[i : i+1],
 			tokens:   EstimateMessageTokens(msg),
 		})
 		i++
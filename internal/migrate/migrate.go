@@ -0,0 +1,644 @@
+// Package migrate imports data from older jot databases. It recognizes the
+// legacy projects/todos/ideas model and habit logs as well as current-shape
+// things and memories, maps them into things, tags, and memories, and
+// reports what it transformed.
+package migrate
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chris/jot/internal/db"
+
+	_ "modernc.org/sqlite"
+)
+
+// Step reports what happened to one source table.
+type Step struct {
+	Table string
+	Rows  int
+	Into  string   // "things", "memories", "habit logs", or "" when skipped
+	Notes []string // transformations applied, or why the table was skipped
+}
+
+// Plan is everything Read found in an old database, ready for Apply.
+type Plan struct {
+	Source   string
+	Hash     string // SHA-256 of the source file, for NoteKey
+	Things   []Thing
+	Memories []Memory
+	Habits   []db.HabitLog
+	Steps    []Step
+}
+
+// Thing is a thing to import. OldID is its ID in the source database when it
+// came from a things table, so memories linked to it can follow.
+type Thing struct {
+	db.NewThing
+	OldID int64
+}
+
+// Memory is a memory to import, linked by OldThingID to a Thing.
+type Memory struct {
+	db.NewMemory
+	OldThingID int64
+}
+
+// Result counts what Apply wrote.
+type Result struct {
+	Things     int
+	Memories   int
+	Duplicates int
+	Habits     int
+}
+
+// Count returns the number of rows the plan would import.
+func (p *Plan) Count() int {
+	return len(p.Things) + len(p.Memories) + len(p.Habits)
+}
+
+// NoteKey is the note Apply sets so the same file isn't imported twice. It
+// is keyed on the file's content hash, so a copy or a move counts as the same
+// file.
+func NoteKey(hash string) string {
+	return "migrated_from:sha256:" + hash
+}
+
+// fileHash returns the hex SHA-256 of the file at path.
+func fileHash(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("hashing %s: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ignored are tables that hold derived or per-session state, never imported.
+var ignored = []string{
+	"conversations", "conversation_summaries", "conversation_messages", "notes",
+	"tool_idempotency", "tool_profiles", "discord_channels", "guild_settings",
+	"schedule_runs", "thing_events", "jobs", "check_ins", "audit_log",
+	"deliveries", "discord_deletions",
+}
+
+// Read opens the database at path read-only and plans the import.
+func Read(path string) (*Plan, error) {
+	hash, err := fileHash(path)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer conn.Close()
+	r := &reader{conn: conn}
+	tables, err := r.tables()
+	if err != nil {
+		return nil, err
+	}
+	if len(tables) == 0 {
+		return nil, fmt.Errorf("%s has no tables; is it a jot database?", path)
+	}
+
+	// Projects first, so todos can be tagged with their names; things before
+	// memories, so links survive.
+	slices.SortStableFunc(tables, func(a, b string) int {
+		return readOrder(a) - readOrder(b)
+	})
+
+	p := &Plan{Source: path, Hash: hash}
+	projects := map[int64]string{}
+	for _, t := range tables {
+		var (
+			step Step
+			err  error
+		)
+		switch t {
+		case "projects":
+			step, err = r.projects(p, projects)
+		case "todos":
+			step, err = r.todos(p, projects)
+		case "ideas":
+			step, err = r.ideas(p)
+		case "things":
+			step, err = r.things(p)
+		case "memories":
+			step, err = r.memories(p)
+		case "habit_logs":
+			step, err = r.habitLogs(p, slices.Contains(tables, "habits"))
+		case "habits":
+			continue // names resolved through habit_logs
+		case "skills", "reminders", "schedules", "watches", "watch_results", "areas", "area_tags", "saved_filters":
+			step, err = r.skip(t, "not imported; recreate with jot setup or by asking jot")
+		default:
+			if slices.Contains(ignored, t) {
+				continue
+			}
+			step, err = r.skip(t, "unrecognized table")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", t, err)
+		}
+		p.Steps = append(p.Steps, step)
+	}
+	return p, nil
+}
+
+// Apply writes the plan into d in one transaction: things first, so
+// memories can be linked to their new IDs, then memories, habit logs, and the
+// NoteKey note. If any part fails nothing is written, so a retry can't
+// duplicate things.
+func Apply(d *db.DB, p *Plan) (*Result, error) {
+	res := &Result{}
+	err := d.Import(func(im *db.Importer) error {
+		newIDs := map[int64]int64{}
+		if len(p.Things) > 0 {
+			things := make([]db.NewThing, len(p.Things))
+			for i, t := range p.Things {
+				things[i] = t.NewThing
+			}
+			ids, err := im.CreateThings(things)
+			if err != nil {
+				return fmt.Errorf("importing things: %w", err)
+			}
+			for i, t := range p.Things {
+				if t.OldID != 0 {
+					newIDs[t.OldID] = ids[i]
+				}
+			}
+			res.Things = len(ids)
+		}
+		if len(p.Memories) > 0 {
+			mems := make([]db.NewMemory, len(p.Memories))
+			for i, m := range p.Memories {
+				mems[i] = m.NewMemory
+				if id, ok := newIDs[m.OldThingID]; ok {
+					mems[i].ThingID = &id
+				}
+			}
+			saved, err := im.SaveMemories(mems)
+			if err != nil {
+				return fmt.Errorf("importing memories: %w", err)
+			}
+			for _, s := range saved {
+				if s.Duplicate {
+					res.Duplicates++
+				} else {
+					res.Memories++
+				}
+			}
+		}
+		if len(p.Habits) > 0 {
+			ids, err := im.LogHabits(p.Habits)
+			if err != nil {
+				return fmt.Errorf("importing habit logs: %w", err)
+			}
+			res.Habits = len(ids)
+		}
+		return im.SetNote(NoteKey(p.Hash), time.Now().UTC().Format(time.DateTime))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func readOrder(table string) int {
+	if i := slices.Index([]string{"projects", "todos", "ideas", "things", "memories", "habit_logs"}, table); i >= 0 {
+		return i
+	}
+	return 100
+}
+
+type reader struct {
+	conn *sql.DB
+}
+
+// row is one source row, keyed by column name.
+type row map[string]any
+
+// str returns the first of cols that is present and non-empty, as a string.
+func (r row) str(cols ...string) string {
+	for _, c := range cols {
+		switch v := r[c].(type) {
+		case string:
+			if v = strings.TrimSpace(v); v != "" {
+				return v
+			}
+		case []byte:
+			if s := strings.TrimSpace(string(v)); s != "" {
+				return s
+			}
+		case int64:
+			return strconv.FormatInt(v, 10)
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		case time.Time:
+			return v.UTC().Format(time.DateTime)
+		}
+	}
+	return ""
+}
+
+func (r row) int(cols ...string) int64 {
+	n, _ := strconv.ParseInt(r.str(cols...), 10, 64)
+	return n
+}
+
+// has reports whether any of cols is a column of the row.
+func (r row) has(cols ...string) bool {
+	for _, c := range cols {
+		if _, ok := r[c]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *reader) tables() ([]string, error) {
+	rows, err := r.conn.Query(`SELECT name FROM sqlite_master
+		WHERE type = 'table' AND name NOT LIKE 'sqlite_%' AND sql NOT LIKE 'CREATE VIRTUAL%'
+		ORDER BY rootpage`)
+	if err != nil {
+		return nil, fmt.Errorf("listing tables: %w", err)
+	}
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		// FTS shadow tables (memories_fts_data, ...) aren't virtual themselves.
+		if strings.Contains(name, "_fts_") {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+func (r *reader) rows(table string) ([]row, error) {
+	rows, err := r.conn.Query(`SELECT * FROM "` + table + `" ORDER BY rowid`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	var out []row
+	for rows.Next() {
+		vals := make([]any, len(cols))
+		ptrs := make([]any, len(cols))
+		for i := range vals {
+			ptrs[i] = &vals[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		rw := make(row, len(cols))
+		for i, c := range cols {
+			rw[strings.ToLower(c)] = vals[i]
+		}
+		out = append(out, rw)
+	}
+	return out, rows.Err()
+}
+
+func (r *reader) skip(table, why string) (Step, error) {
+	var n int
+	if err := r.conn.QueryRow(`SELECT COUNT(*) FROM "` + table + `"`).Scan(&n); err != nil {
+		return Step{}, err
+	}
+	return Step{Table: table, Rows: n, Notes: []string{why}}, nil
+}
+
+// projects become things tagged "project". Their names are remembered so
+// todos can be tagged with the project they belonged to.
+func (r *reader) projects(p *Plan, names map[int64]string) (Step, error) {
+	rows, err := r.rows("projects")
+	if err != nil {
+		return Step{}, err
+	}
+	for _, rw := range rows {
+		title := rw.str("name", "title")
+		if title == "" {
+			continue
+		}
+		names[rw.int("id")] = tagName(title)
+		status := mapStatus(rw.str("status"))
+		p.Things = append(p.Things, Thing{NewThing: db.NewThing{
+			Title:       title,
+			Notes:       rw.str("description", "notes"),
+			Status:      status,
+			Priority:    mapPriority(rw.str("priority")),
+			DueDate:     date(rw.str("due_date", "deadline")),
+			Tags:        withTag(parseTags(rw.str("tags")), "project"),
+			CreatedAt:   timestamp(rw.str("created_at")),
+			CompletedAt: completedAt(status, rw),
+		}})
+	}
+	return Step{Table: "projects", Rows: len(rows), Into: "things", Notes: []string{
+		`tagged "project"`, "statuses mapped to open/active/done/dropped",
+	}}, nil
+}
+
+// todos become things, tagged with their project's name.
+func (r *reader) todos(p *Plan, projects map[int64]string) (Step, error) {
+	rows, err := r.rows("todos")
+	if err != nil {
+		return Step{}, err
+	}
+	linked := 0
+	for _, rw := range rows {
+		title := rw.str("title", "content", "text", "task")
+		if title == "" {
+			continue
+		}
+		status := mapStatus(rw.str("status"))
+		if rw.has("done", "completed") && rw.int("done", "completed") != 0 {
+			status = "done"
+		}
+		tags := parseTags(rw.str("tags"))
+		if name, ok := projects[rw.int("project_id")]; ok {
+			tags = withTag(tags, name)
+			linked++
+		}
+		p.Things = append(p.Things, Thing{NewThing: db.NewThing{
+			Title:       title,
+			Notes:       rw.str("notes", "description"),
+			Status:      status,
+			Priority:    mapPriority(rw.str("priority")),
+			DueDate:     date(rw.str("due_date", "due", "deadline")),
+			Tags:        tags,
+			CreatedAt:   timestamp(rw.str("created_at")),
+			CompletedAt: completedAt(status, rw),
+		}})
+	}
+	step := Step{Table: "todos", Rows: len(rows), Into: "things", Notes: []string{"done flags and statuses mapped to open/active/done/dropped"}}
+	if linked > 0 {
+		step.Notes = append(step.Notes, fmt.Sprintf("%d tagged with their project's name", linked))
+	}
+	return step, nil
+}
+
+// ideas become things tagged "idea". Long ideas keep their first line as the
+// title and the full text as notes.
+func (r *reader) ideas(p *Plan) (Step, error) {
+	rows, err := r.rows("ideas")
+	if err != nil {
+		return Step{}, err
+	}
+	for _, rw := range rows {
+		title, notes := rw.str("title"), rw.str("description", "notes")
+		if content := rw.str("content", "text", "body"); title == "" {
+			title = firstLine(content)
+			if title != content {
+				notes = content
+			}
+		} else if notes == "" {
+			notes = content
+		}
+		if title == "" {
+			continue
+		}
+		status := mapStatus(rw.str("status"))
+		p.Things = append(p.Things, Thing{NewThing: db.NewThing{
+			Title:       title,
+			Notes:       notes,
+			Status:      status,
+			Tags:        withTag(parseTags(rw.str("tags")), "idea"),
+			CreatedAt:   timestamp(rw.str("created_at")),
+			CompletedAt: completedAt(status, rw),
+		}})
+	}
+	return Step{Table: "ideas", Rows: len(rows), Into: "things", Notes: []string{`tagged "idea"`}}, nil
+}
+
+// things from another jot database are copied as they are.
+func (r *reader) things(p *Plan) (Step, error) {
+	rows, err := r.rows("things")
+	if err != nil {
+		return Step{}, err
+	}
+	for _, rw := range rows {
+		title := rw.str("title")
+		if title == "" {
+			continue
+		}
+		status := mapStatus(rw.str("status"))
+		p.Things = append(p.Things, Thing{OldID: rw.int("id"), NewThing: db.NewThing{
+			Title:       title,
+			Notes:       rw.str("notes"),
+			Status:      status,
+			Priority:    mapPriority(rw.str("priority")),
+			DueDate:     date(rw.str("due_date")),
+			Tags:        parseTags(rw.str("tags")),
+			CreatedAt:   timestamp(rw.str("created_at")),
+			CompletedAt: completedAt(status, rw),
+		}})
+	}
+	return Step{Table: "things", Rows: len(rows), Into: "things"}, nil
+}
+
+// memories keep their category, tags, dates, and thing links; unknown
+// categories become observations.
+func (r *reader) memories(p *Plan) (Step, error) {
+	rows, err := r.rows("memories")
+	if err != nil {
+		return Step{}, err
+	}
+	recategorized, proposed := 0, 0
+	for _, rw := range rows {
+		content := rw.str("content")
+		if content == "" {
+			continue
+		}
+		category := strings.ToLower(rw.str("category"))
+		if category == "" || db.CheckValue("memories", "category", category) != nil {
+			category = "observation"
+			recategorized++
+		}
+		status := rw.str("status")
+		if db.CheckValue("memories", "status", status) != nil {
+			status = ""
+		}
+		if status == "proposed" {
+			proposed++
+		}
+		p.Memories = append(p.Memories, Memory{OldThingID: rw.int("thing_id"), NewMemory: db.NewMemory{
+			Content:   content,
+			Category:  category,
+			Source:    "import",
+			Status:    status,
+			Tags:      parseTags(rw.str("tags")),
+			ExpiresAt: timestamp(rw.str("expires_at")),
+			CreatedAt: timestamp(rw.str("created_at")),
+		}})
+	}
+	step := Step{Table: "memories", Rows: len(rows), Into: "memories", Notes: []string{`source set to "import"`}}
+	if recategorized > 0 {
+		step.Notes = append(step.Notes, fmt.Sprintf("%d with unknown categories saved as observations", recategorized))
+	}
+	if proposed > 0 {
+		step.Notes = append(step.Notes, fmt.Sprintf("%d still proposed, awaiting review", proposed))
+	}
+	return step, nil
+}
+
+// habitLogs become habit memories tagged with the habit's name, taken from
+// the row or, in the older two-table shape, from habits by habit_id.
+func (r *reader) habitLogs(p *Plan, hasHabits bool) (Step, error) {
+	names := map[int64]string{}
+	if hasHabits {
+		rows, err := r.rows("habits")
+		if err != nil {
+			return Step{}, err
+		}
+		for _, rw := range rows {
+			names[rw.int("id")] = rw.str("name", "title")
+		}
+	}
+	rows, err := r.rows("habit_logs")
+	if err != nil {
+		return Step{}, err
+	}
+	skipped := 0
+	for _, rw := range rows {
+		habit := rw.str("habit", "name")
+		if habit == "" {
+			habit = names[rw.int("habit_id")]
+		}
+		if habit == "" {
+			skipped++
+			continue
+		}
+		p.Habits = append(p.Habits, db.HabitLog{
+			Habit: tagName(habit),
+			Note:  rw.str("note", "notes"),
+			At:    timestamp(rw.str("logged_at", "date", "created_at")),
+		})
+	}
+	step := Step{Table: "habit_logs", Rows: len(rows), Into: "habit logs", Notes: []string{"saved as habit memories tagged with the habit"}}
+	if skipped > 0 {
+		step.Notes = append(step.Notes, fmt.Sprintf("%d without a habit name skipped", skipped))
+	}
+	return step, nil
+}
+
+// mapStatus maps the statuses older schemas used onto jot's.
+func mapStatus(s string) string {
+	switch strings.ToLower(strings.ReplaceAll(s, " ", "_")) {
+	case "active", "in_progress", "doing", "started", "wip":
+		return "active"
+	case "done", "complete", "completed", "finished", "closed":
+		return "done"
+	case "dropped", "cancelled", "canceled", "abandoned", "archived", "wontfix":
+		return "dropped"
+	}
+	return "open"
+}
+
+func mapPriority(s string) string {
+	switch strings.ToLower(s) {
+	case "low", "1":
+		return "low"
+	case "high", "3":
+		return "high"
+	case "urgent", "critical", "4", "5":
+		return "urgent"
+	}
+	return "normal"
+}
+
+func completedAt(status string, rw row) string {
+	if status != "done" {
+		return ""
+	}
+	if t := timestamp(rw.str("completed_at", "updated_at")); t != "" {
+		return t
+	}
+	return time.Now().UTC().Format(time.DateTime)
+}
+
+// timestamp normalizes a stored time to UTC "YYYY-MM-DD HH:MM:SS", or "" if
+// it can't be read.
+func timestamp(s string) string {
+	if s == "" {
+		return ""
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil && n > 1e9 {
+		return time.Unix(n, 0).UTC().Format(time.DateTime)
+	}
+	for _, layout := range []string{time.DateTime, time.RFC3339Nano, "2006-01-02T15:04:05", time.DateOnly} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC().Format(time.DateTime)
+		}
+	}
+	return ""
+}
+
+func date(s string) string {
+	if t := timestamp(s); t != "" {
+		return t[:len(time.DateOnly)]
+	}
+	return ""
+}
+
+// parseTags reads a JSON array or a comma-separated list.
+func parseTags(s string) []string {
+	if s == "" {
+		return nil
+	}
+	var tags []string
+	if json.Unmarshal([]byte(s), &tags) != nil {
+		tags = strings.Split(s, ",")
+	}
+	out := tags[:0]
+	for _, t := range tags {
+		if t = tagName(t); t != "" && !slices.Contains(out, t) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func withTag(tags []string, tag string) []string {
+	if tag == "" || slices.Contains(tags, tag) {
+		return tags
+	}
+	return append(tags, tag)
+}
+
+// tagName lowercases a name and joins its words with hyphens.
+func tagName(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), "-")
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	line = strings.TrimSpace(line)
+	if len(line) > 80 {
+		if i := strings.LastIndex(line[:80], " "); i > 0 {
+			return line[:i] + "…"
+		}
+	}
+	return line
+}
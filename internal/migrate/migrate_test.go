@@ -0,0 +1,184 @@
+package migrate
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/chris/jot/internal/db"
+	"github.com/chris/jot/internal/db/dbtest"
+)
+
+// legacyDB writes a database in the old projects/todos/ideas shape.
+func legacyDB(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "old.db")
+	conn, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("opening legacy db: %v", err)
+	}
+	defer conn.Close()
+	for _, stmt := range []string{
+		`CREATE TABLE projects (id INTEGER PRIMARY KEY, name TEXT, description TEXT, status TEXT, created_at TEXT)`,
+		`CREATE TABLE todos (id INTEGER PRIMARY KEY, project_id INTEGER, title TEXT, done INTEGER, due TEXT, created_at TEXT)`,
+		`CREATE TABLE ideas (id INTEGER PRIMARY KEY, content TEXT, tags TEXT, created_at TEXT)`,
+		`CREATE TABLE habits (id INTEGER PRIMARY KEY, name TEXT)`,
+		`CREATE TABLE habit_logs (id INTEGER PRIMARY KEY, habit_id INTEGER, note TEXT, logged_at TEXT)`,
+		`CREATE TABLE memories (id INTEGER PRIMARY KEY, content TEXT, category TEXT, created_at TEXT)`,
+		`CREATE TABLE skills (id INTEGER PRIMARY KEY, name TEXT)`,
+		`INSERT INTO projects VALUES (1, 'Garden Shed', 'Build it before winter', 'in progress', '2023-04-01 10:00:00')`,
+		`INSERT INTO todos VALUES (1, 1, 'Buy lumber', 1, NULL, '2023-04-02T09:30:00Z'), (2, 1, 'Pour foundation', 0, '2023-05-01', NULL)`,
+		`INSERT INTO ideas VALUES (1, 'Podcast about sheds' || char(10) || 'Interview builders', 'diy, Audio', '2023-03-01 08:00:00')`,
+		`INSERT INTO habits VALUES (1, 'Morning Run')`,
+		`INSERT INTO habit_logs VALUES (1, 1, '5k', '2023-04-03 06:00:00'), (2, 9, '', '2023-04-04 06:00:00')`,
+		`INSERT INTO memories VALUES (1, 'Prefers cedar over pine', 'preference', '2023-04-05 12:00:00'), (2, 'Rained all week', 'weather', NULL)`,
+		`INSERT INTO skills VALUES (1, 'weekly-review')`,
+	} {
+		if _, err := conn.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	return path
+}
+
+func TestMigrateLegacy(t *testing.T) {
+	p, err := Read(legacyDB(t))
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(p.Things) != 4 || len(p.Memories) != 2 || len(p.Habits) != 1 {
+		t.Fatalf("plan: %d things, %d memories, %d habits", len(p.Things), len(p.Memories), len(p.Habits))
+	}
+	var tables []string
+	for _, s := range p.Steps {
+		tables = append(tables, s.Table)
+	}
+	if want := []string{"projects", "todos", "ideas", "memories", "habit_logs", "skills"}; !slices.Equal(tables, want) {
+		t.Errorf("steps = %v, want %v", tables, want)
+	}
+
+	d := dbtest.Open(t)
+	res, err := Apply(d, p)
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if res.Things != 4 || res.Memories != 2 || res.Habits != 1 {
+		t.Errorf("result = %+v", res)
+	}
+
+	things, err := d.ListThings("", "", "")
+	if err != nil {
+		t.Fatalf("ListThings: %v", err)
+	}
+	byTitle := map[string]db.Thing{}
+	for _, th := range things {
+		byTitle[th.Title] = th
+	}
+	if shed := byTitle["Garden Shed"]; shed.Status != "active" || !slices.Contains(shed.Tags, "project") || shed.CreatedAt != "2023-04-01 10:00:00" {
+		t.Errorf("project = %+v", shed)
+	}
+	if lumber := byTitle["Buy lumber"]; !slices.Contains(lumber.Tags, "garden-shed") || lumber.CompletedAt == "" {
+		t.Errorf("done todo = %+v", lumber)
+	}
+	if found := byTitle["Pour foundation"]; found.Status != "open" || found.DueDate != "2023-05-01" {
+		t.Errorf("open todo = %+v", found)
+	}
+	if idea := byTitle["Podcast about sheds"]; !slices.Equal(idea.Tags, []string{"diy", "audio", "idea"}) || idea.Notes == "" {
+		t.Errorf("idea = %+v", idea)
+	}
+
+	mems, err := d.SearchMemories("", "", "", nil, "", 10)
+	if err != nil {
+		t.Fatalf("SearchMemories: %v", err)
+	}
+	cats := map[string]string{}
+	for _, m := range mems {
+		cats[m.Content] = m.Category
+	}
+	if cats["Rained all week"] != "observation" || cats["Prefers cedar over pine"] != "preference" || cats["5k"] != "habit" {
+		t.Errorf("memory categories = %v", cats)
+	}
+
+	if at, _ := d.GetNote(NoteKey(p.Hash)); at == "" {
+		t.Error("expected the import to be recorded")
+	}
+
+	// A copy of the same file has the same key.
+	data, err := os.ReadFile(p.Source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cp := filepath.Join(t.TempDir(), "copy.db")
+	if err := os.WriteFile(cp, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if again, err := Read(cp); err != nil || again.Hash != p.Hash {
+		t.Errorf("expected a copy to hash the same, got %v, %v", again, err)
+	}
+}
+
+// TestApplyAtomic checks that a failed import writes nothing, so a retry
+// doesn't duplicate things.
+func TestApplyAtomic(t *testing.T) {
+	d := dbtest.Open(t)
+	p := &Plan{
+		Hash:   "abc",
+		Things: []Thing{{NewThing: db.NewThing{Title: "Pour foundation"}}},
+		Habits: []db.HabitLog{{Habit: "  "}}, // fails after the things are inserted
+	}
+	if _, err := Apply(d, p); err == nil {
+		t.Fatal("expected an error for a habit log with no habit")
+	}
+	if things, _ := d.ListThings("", "", ""); len(things) != 0 {
+		t.Errorf("expected no things after a failed import, got %+v", things)
+	}
+	if at, _ := d.GetNote(NoteKey("abc")); at != "" {
+		t.Error("expected no import note after a failed import")
+	}
+}
+
+// TestMigrateJotDB imports from another jot database, keeping memories
+// linked to their things.
+func TestMigrateJotDB(t *testing.T) {
+	path := dbtest.Path(t)
+	old, err := db.Open(path)
+	if err != nil {
+		t.Fatalf("opening old db: %v", err)
+	}
+	id, err := old.CreateThing("Renew passport", "", "high", "", []string{"travel"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := old.SaveMemory("Passport photos are at the pharmacy", "observation", "agent", nil, &id, ""); err != nil {
+		t.Fatal(err)
+	}
+	old.Close()
+
+	p, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	for _, s := range p.Steps {
+		if s.Into == "" && s.Notes[0] == "unrecognized table" {
+			t.Errorf("jot table %s reported as unrecognized", s.Table)
+		}
+	}
+	d := dbtest.Open(t)
+	d.CreateThing("Already here", "", "", "", nil)
+	if _, err := Apply(d, p); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	mems, err := d.SearchMemories("passport", "", "", nil, "", 10)
+	if err != nil || len(mems) != 1 {
+		t.Fatalf("SearchMemories: %v, %v", mems, err)
+	}
+	if mems[0].ThingID == nil {
+		t.Fatal("memory lost its thing")
+	}
+	th, err := d.GetThing(*mems[0].ThingID)
+	if err != nil || th.Title != "Renew passport" || th.Priority != "high" {
+		t.Errorf("linked thing = %+v, %v", th, err)
+	}
+}
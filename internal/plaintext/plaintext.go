@@ -0,0 +1,110 @@
+// Package plaintext turns the Markdown jot writes for Discord into plain text
+// that reads cleanly in a screen reader: no formatting marks, tables, or
+// emoji.
+package plaintext
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	fence      = regexp.MustCompile("^\\s*(```|~~~)")
+	heading    = regexp.MustCompile(`^\s*#{1,6}\s+`)
+	quote      = regexp.MustCompile(`^\s*>+\s?`)
+	bullet     = regexp.MustCompile(`^\s*([-*+•◦▪]|\[[ xX]\])\s+(\[[ xX]\]\s+)?`)
+	rule       = regexp.MustCompile(`^\s*([-*_]\s*){3,}$`)
+	tableSep   = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+	image      = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	link       = regexp.MustCompile(`\[([^\]]+)\]\(<?([^)>]+)>?\)`)
+	customEmo  = regexp.MustCompile(`<a?:\w+:\d+>`)
+	strong     = regexp.MustCompile(`(\*\*|__|~~|\|\|)(.+?)(\*\*|__|~~|\|\|)`)
+	emphasis   = regexp.MustCompile(`(^|[^\w*])[*_]([^*_\s](?:[^*_]*[^*_\s])?)[*_]($|[^\w*])`)
+	code       = regexp.MustCompile("`([^`]*)`")
+	spaces     = regexp.MustCompile(`[ \t]{2,}`)
+	blankLines = regexp.MustCompile(`\n{3,}`)
+)
+
+// Strip converts Markdown to plain text. Headings, list items, and quotes keep
+// their text on their own line; numbered lists keep their numbers; table rows
+// become comma-separated lines; links become "text (url)".
+func Strip(s string) string {
+	lines := strings.Split(s, "\n")
+	out := make([]string, 0, len(lines))
+	inCode := false
+	for _, line := range lines {
+		if fence.MatchString(line) {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			out = append(out, stripEmoji(line))
+			continue
+		}
+		if rule.MatchString(line) || (strings.Contains(line, "|") && tableSep.MatchString(line)) {
+			continue
+		}
+		line = heading.ReplaceAllString(line, "")
+		line = quote.ReplaceAllString(line, "")
+		line = bullet.ReplaceAllString(line, "")
+		if t := strings.TrimSpace(line); strings.HasPrefix(t, "|") && strings.HasSuffix(t, "|") && len(t) > 1 {
+			cells := strings.Split(t[1:len(t)-1], "|")
+			for i, c := range cells {
+				cells[i] = strings.TrimSpace(c)
+			}
+			line = strings.Join(cells, ", ")
+		}
+		out = append(out, inline(line))
+	}
+	text := blankLines.ReplaceAllString(strings.Join(out, "\n"), "\n\n")
+	return strings.TrimSpace(text)
+}
+
+// inline strips formatting within one line.
+func inline(line string) string {
+	line = customEmo.ReplaceAllString(line, "")
+	line = image.ReplaceAllString(line, "$1")
+	line = link.ReplaceAllStringFunc(line, func(m string) string {
+		parts := link.FindStringSubmatch(m)
+		if parts[1] == parts[2] {
+			return parts[2]
+		}
+		return parts[1] + " (" + parts[2] + ")"
+	})
+	line = code.ReplaceAllString(line, "$1")
+	line = strong.ReplaceAllString(line, "$2")
+	// Twice, since adjacent matches share their boundary character.
+	line = emphasis.ReplaceAllString(line, "$1$2$3")
+	line = emphasis.ReplaceAllString(line, "$1$2$3")
+	line = stripEmoji(line)
+	line = spaces.ReplaceAllString(line, " ")
+	return strings.TrimRight(line, " \t")
+}
+
+// stripEmoji drops emoji, their modifiers, and joiners. A space left at the
+// start of the line by a leading emoji goes too.
+func stripEmoji(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		if !isEmoji(r) {
+			sb.WriteRune(r)
+		}
+	}
+	if sb.Len() == len(s) {
+		return s
+	}
+	return strings.TrimLeft(sb.String(), " ")
+}
+
+func isEmoji(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF, // pictographs, emoticons, transport, flags, skin tones
+		r >= 0x2600 && r <= 0x27BF,                             // misc symbols and dingbats (☀ ✅ ❗)
+		r >= 0x2B00 && r <= 0x2BFF,                             // ⭐ ⬆ and friends
+		r >= 0x231A && r <= 0x231B, r >= 0x23E9 && r <= 0x23FA, // ⌚ ⏰ ⏳
+		r >= 0xE0020 && r <= 0xE007F,                       // tag sequences
+		r == 0x200D, r == 0xFE0E, r == 0xFE0F, r == 0x20E3: // joiner, variation selectors, keycap
+		return true
+	}
+	return false
+}
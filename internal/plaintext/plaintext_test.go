@@ -0,0 +1,29 @@
+package plaintext
+
+import "testing"
+
+func TestStrip(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"plain", "Nothing to change.", "Nothing to change."},
+		{"heading and emphasis", "## Today 🌞\n**Overdue:** *Pay rent*", "Today\nOverdue: Pay rent"},
+		{"bullets", "- #2 Pay rent (due today)\n  * nested\n• watch item\n- [x] done", "#2 Pay rent (due today)\nnested\nwatch item\ndone"},
+		{"numbered", "1. First\n2. Second", "1. First\n2. Second"},
+		{"links", "See [the docs](https://example.com) or https://x.io and [https://y.io](https://y.io)", "See the docs (https://example.com) or https://x.io and https://y.io"},
+		{"code", "Run `jot tools` then\n```\njot publish\n```", "Run jot tools then\njot publish"},
+		{"table", "| Area | Open |\n|------|-----:|\n| work | 3 |", "Area, Open\nwork, 3"},
+		{"emoji", "✅ Done! 🎉 <:party:12345> Nice 👍🏽", "Done! Nice"},
+		{"zwj sequence", "Family: 👨‍👩‍👧 ok", "Family: ok"},
+		{"snake case survives", "set memory_days and *note*", "set memory_days and note"},
+		{"quote and rule", "> quoted\n---\nafter", "quoted\nafter"},
+		{"blank lines", "a\n\n\n\nb", "a\n\nb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Strip(tt.in); got != tt.want {
+				t.Errorf("Strip(%q) =\n%q\nwant\n%q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
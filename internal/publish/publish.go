@@ -0,0 +1,198 @@
+// Package publish renders schedule output (check-ins, weekly reviews) and a
+// few stats into a static "operating log": plain HTML pages, or Markdown with
+// front matter for a Hugo site.
+package publish
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/chris/jot/internal/db"
+)
+
+// Site is everything one publish run renders.
+type Site struct {
+	Title       string
+	GeneratedAt time.Time
+	Days        int // stats window
+	Open        int
+	Overdue     int
+	Completed   int // in the last Days days
+	Areas       []db.AreaRollup
+	Runs        []db.ScheduleRun // oldest first
+}
+
+// Day is the runs from one local date.
+type Day struct {
+	Date string
+	Runs []db.ScheduleRun
+}
+
+// ByDay groups s.Runs by local date, newest day first.
+func (s *Site) ByDay() []Day {
+	var days []Day
+	for _, r := range s.Runs {
+		date := localDate(r.CreatedAt)
+		if n := len(days); n > 0 && days[n-1].Date == date {
+			days[n-1].Runs = append(days[n-1].Runs, r)
+			continue
+		}
+		days = append(days, Day{Date: date, Runs: []db.ScheduleRun{r}})
+	}
+	for i, j := 0, len(days)-1; i < j; i, j = i+1, j-1 {
+		days[i], days[j] = days[j], days[i]
+	}
+	return days
+}
+
+// WriteHTML writes index.html (stats and a list of days) and one page per
+// day into dir, returning the number of pages written.
+func WriteHTML(dir string, s *Site) (int, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return 0, fmt.Errorf("creating %s: %w", dir, err)
+	}
+	days := s.ByDay()
+	if err := writeTemplate(filepath.Join(dir, "index.html"), indexHTML, struct {
+		*Site
+		ByDay []Day
+	}{s, days}); err != nil {
+		return 0, err
+	}
+	for _, d := range days {
+		if err := writeTemplate(filepath.Join(dir, d.Date+".html"), dayHTML, struct {
+			Title string
+			Day
+		}{s.Title, d}); err != nil {
+			return 0, err
+		}
+	}
+	return len(days) + 1, nil
+}
+
+// WriteHugo writes content/log/_index.md and one Markdown page per day into
+// a Hugo site at dir, returning the number of pages written.
+func WriteHugo(dir string, s *Site) (int, error) {
+	logDir := filepath.Join(dir, "content", "log")
+	if err := os.MkdirAll(logDir, 0o755); err != nil {
+		return 0, fmt.Errorf("creating %s: %w", logDir, err)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "---\ntitle: %q\ndate: %s\n---\n\n", s.Title, s.GeneratedAt.Format(time.RFC3339))
+	writeStatsMarkdown(&sb, s)
+	if err := writeFile(filepath.Join(logDir, "_index.md"), sb.String()); err != nil {
+		return 0, err
+	}
+
+	days := s.ByDay()
+	for _, d := range days {
+		sb.Reset()
+		fmt.Fprintf(&sb, "---\ntitle: %q\ndate: %s\n---\n\n", d.Date, d.Date)
+		for _, r := range d.Runs {
+			fmt.Fprintf(&sb, "## %s (%s)\n\n%s\n\n", r.ScheduleName, localTime(r.CreatedAt), strings.TrimSpace(r.Output))
+		}
+		if err := writeFile(filepath.Join(logDir, d.Date+".md"), sb.String()); err != nil {
+			return 0, err
+		}
+	}
+	return len(days) + 1, nil
+}
+
+func writeStatsMarkdown(sb *strings.Builder, s *Site) {
+	fmt.Fprintf(sb, "- Open: %d\n- Overdue: %d\n- Completed in the last %d days: %d\n", s.Open, s.Overdue, s.Days, s.Completed)
+	if len(s.Areas) > 0 {
+		fmt.Fprintf(sb, "\n| Area | Open | Overdue | Completed |\n|------|------|---------|-----------|\n")
+		for _, a := range s.Areas {
+			fmt.Fprintf(sb, "| %s | %d | %d | %d |\n", a.Area, a.Open, a.Overdue, a.Completed)
+		}
+	}
+}
+
+// Push commits everything in dir, a git work tree, and pushes it to the
+// default remote.
+func Push(dir, message string) error {
+	for _, args := range [][]string{
+		{"add", "-A"},
+		{"commit", "--allow-empty", "-m", message},
+		{"push"},
+	} {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("git %s: %w\n%s", args[0], err, out)
+		}
+	}
+	return nil
+}
+
+func writeTemplate(path string, t *template.Template, data any) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating %s: %w", path, err)
+	}
+	if err := t.Execute(f, data); err != nil {
+		f.Close()
+		return fmt.Errorf("rendering %s: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return nil
+}
+
+func writeFile(path, content string) error {
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return nil
+}
+
+// localDate and localTime convert a stored UTC datetime to the machine's
+// local date and time of day.
+func localDate(ts string) string { return parseUTC(ts).Format(time.DateOnly) }
+func localTime(ts string) string { return parseUTC(ts).Format("15:04") }
+
+func parseUTC(ts string) time.Time {
+	t, err := time.ParseInLocation(time.DateTime, ts, time.UTC)
+	if err != nil {
+		return time.Time{}
+	}
+	return t.Local()
+}
+
+var funcs = template.FuncMap{"time": localTime}
+
+const style = `<style>body{font-family:system-ui,sans-serif;max-width:42rem;margin:2rem auto;padding:0 1rem;line-height:1.5}
+.run{white-space:pre-wrap;border-left:3px solid #ccc;padding-left:1rem}table{border-collapse:collapse}td,th{padding:.2rem .6rem;text-align:left}</style>`
+
+var indexHTML = template.Must(template.New("index").Funcs(funcs).Parse(`<!doctype html>
+<html><head><meta charset="utf-8"><title>{{.Title}}</title>` + style + `</head><body>
+<h1>{{.Title}}</h1>
+<p>Updated {{.GeneratedAt.Format "2006-01-02 15:04"}}.</p>
+<ul>
+<li>Open: {{.Open}}</li>
+<li>Overdue: {{.Overdue}}</li>
+<li>Completed in the last {{.Days}} days: {{.Completed}}</li>
+</ul>
+{{if .Areas}}<table><tr><th>Area</th><th>Open</th><th>Overdue</th><th>Completed</th></tr>
+{{range .Areas}}<tr><td>{{.Area}}</td><td>{{.Open}}</td><td>{{.Overdue}}</td><td>{{.Completed}}</td></tr>
+{{end}}</table>{{end}}
+<h2>Log</h2>
+{{if not .ByDay}}<p>Nothing yet.</p>{{end}}<ul>
+{{range .ByDay}}<li><a href="{{.Date}}.html">{{.Date}}</a> ({{len .Runs}})</li>
+{{end}}</ul>
+</body></html>
+`))
+
+var dayHTML = template.Must(template.New("day").Funcs(funcs).Parse(`<!doctype html>
+<html><head><meta charset="utf-8"><title>{{.Date}} · {{.Title}}</title>` + style + `</head><body>
+<p><a href="index.html">{{.Title}}</a></p>
+<h1>{{.Date}}</h1>
+{{range .Runs}}<h2>{{.ScheduleName}} <small>{{time .CreatedAt}}</small></h2>
+<div class="run">{{.Output}}</div>
+{{end}}</body></html>
+`))
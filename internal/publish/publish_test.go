@@ -0,0 +1,72 @@
+package publish
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chris/jot/internal/db"
+)
+
+func testSite() *Site {
+	return &Site{
+		Title:       "Operating log",
+		GeneratedAt: time.Date(2026, 3, 2, 9, 0, 0, 0, time.Local),
+		Days:        7,
+		Open:        4,
+		Completed:   2,
+		Runs: []db.ScheduleRun{
+			{ScheduleName: "morning-checkin", Output: "Two things due <today>.", CreatedAt: "2026-03-01 12:00:00"},
+			{ScheduleName: "weekly-review", Output: "Good week.", CreatedAt: "2026-03-02 12:00:00"},
+		},
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	dir := t.TempDir()
+	s := testSite()
+	n, err := WriteHTML(dir, s)
+	if err != nil {
+		t.Fatalf("WriteHTML: %v", err)
+	}
+	if n != 3 {
+		t.Errorf("wrote %d pages, want index + 2 days", n)
+	}
+
+	index, _ := os.ReadFile(filepath.Join(dir, "index.html"))
+	days := s.ByDay()
+	if !strings.Contains(string(index), `href="`+days[0].Date+`.html"`) || !strings.Contains(string(index), "Open: 4") {
+		t.Errorf("index missing day link or stats:\n%s", index)
+	}
+	page, _ := os.ReadFile(filepath.Join(dir, days[1].Date+".html"))
+	if !strings.Contains(string(page), "Two things due &lt;today&gt;.") {
+		t.Errorf("day page should contain escaped run output:\n%s", page)
+	}
+}
+
+func TestWriteHugo(t *testing.T) {
+	dir := t.TempDir()
+	s := testSite()
+	if _, err := WriteHugo(dir, s); err != nil {
+		t.Fatalf("WriteHugo: %v", err)
+	}
+	day := s.ByDay()[0]
+	page, err := os.ReadFile(filepath.Join(dir, "content", "log", day.Date+".md"))
+	if err != nil {
+		t.Fatalf("reading day page: %v", err)
+	}
+	if !strings.HasPrefix(string(page), "---\ntitle: \""+day.Date+"\"") || !strings.Contains(string(page), "## weekly-review") {
+		t.Errorf("unexpected day page:\n%s", page)
+	}
+}
+
+func TestByDay(t *testing.T) {
+	s := testSite()
+	s.Runs = append(s.Runs, db.ScheduleRun{ScheduleName: "evening", CreatedAt: "2026-03-02 12:30:00"})
+	days := s.ByDay()
+	if len(days) != 2 || len(days[0].Runs) != 2 {
+		t.Errorf("expected newest day first with 2 runs, got %+v", days)
+	}
+}
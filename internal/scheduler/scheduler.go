@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -13,6 +14,8 @@ import (
 
 	"github.com/chris/jot/internal/agent"
 	"github.com/chris/jot/internal/db"
+	"github.com/chris/jot/internal/discord"
+	"github.com/chris/jot/internal/plaintext"
 	"github.com/chris/jot/internal/watch"
 	"github.com/robfig/cron/v3"
 )
@@ -25,6 +28,7 @@ type Scheduler struct {
 	watchRunner   *watch.Runner
 	dmSend        func(userID, content string) error
 	mu            sync.Mutex
+	deliverMu     sync.Mutex             // one sender drains the delivery queue at a time
 	entryIDs      map[int64]cron.EntryID // scheduleID -> cron entry
 	watchEntryIDs map[int64]cron.EntryID // watchID -> cron entry
 }
@@ -55,7 +59,7 @@ func (s *Scheduler) Start() {
 		}
 	}()
 
-	// Poll for due reminders every 60 seconds; prune old data daily.
+	// Poll for due reminders every 60 seconds; age things and prune old data daily.
 	go func() {
 		t := time.NewTicker(60 * time.Second)
 		defer t.Stop()
@@ -64,12 +68,37 @@ func (s *Scheduler) Start() {
 			s.fireReminders()
 
 			if time.Since(lastPrune) > 24*time.Hour {
+				s.ageThings()
 				s.pruneOldData()
 				lastPrune = time.Now()
 			}
 		}
 	}()
 
+	// Jobs a restart interrupted go back in the queue; one worker runs them in order.
+	if n, err := s.db.RequeueRunningJobs(); err != nil {
+		log.Printf("scheduler: requeuing jobs: %v", err)
+	} else if n > 0 {
+		log.Printf("scheduler: requeued %d interrupted job(s)", n)
+	}
+	go func() {
+		t := time.NewTicker(jobPollInterval)
+		defer t.Stop()
+		for range t.C {
+			s.runJobs()
+		}
+	}()
+
+	// Deliveries left pending by a failure or a restart go out as they come due.
+	go func() {
+		s.sendDeliveries()
+		t := time.NewTicker(deliveryPollInterval)
+		defer t.Stop()
+		for range t.C {
+			s.sendDeliveries()
+		}
+	}()
+
 	log.Println("scheduler started")
 }
 
@@ -85,11 +114,14 @@ func (s *Scheduler) SeedDefaultSchedule(cronExpr string) {
 		return
 	}
 	if len(schedules) == 0 && cronExpr != "" {
-		_, err := s.db.CreateSchedule(
+		id, err := s.db.CreateSchedule(
 			"morning-checkin",
 			cronExpr,
 			"Perform a morning check-in. Summarize pending work, mention overdue items, suggest priorities for the day.",
 		)
+		if err == nil {
+			err = s.db.UpdateSchedule(id, map[string]any{"check_in": 1})
+		}
 		if err != nil {
 			log.Printf("scheduler: seeding default schedule: %v", err)
 		} else {
@@ -140,10 +172,20 @@ func (s *Scheduler) runSchedule(sched db.Schedule) {
 	var reply string
 	var err error
 
+	prompt := sched.Prompt
+	if sched.CheckIn {
+		if prompt, err = s.agent.BuildCheckInPrompt(sched); err != nil {
+			// The model can still fetch context with tools; don't skip the run.
+			log.Printf("scheduler[%s]: %v", sched.Name, err)
+			prompt = sched.Prompt
+		}
+	}
+
+	ctx := agent.WithProfile(context.Background(), agent.ProfileSchedule)
 	if userID := s.resolveUserID(); userID != "" {
-		reply, err = s.agent.RunWithConversation(context.Background(), userID, sched.Prompt)
+		reply, err = s.agent.RunWithConversation(ctx, userID, prompt)
 	} else {
-		reply, _, err = s.agent.Run(context.Background(), nil, sched.Prompt)
+		reply, _, err = s.agent.Run(ctx, nil, prompt)
 	}
 
 	if err != nil {
@@ -154,6 +196,23 @@ func (s *Scheduler) runSchedule(sched db.Schedule) {
 	if err := s.db.RecordScheduleRun(sched.ID); err != nil {
 		log.Printf("scheduler[%s]: recording run: %v", sched.Name, err)
 	}
+	if err := s.db.LogScheduleRun(sched, reply); err != nil {
+		log.Printf("scheduler[%s]: %v", sched.Name, err)
+	}
+
+	if sched.Digest {
+		if digest, err := s.agent.ChangesDigest(time.Now().Add(-24 * time.Hour)); err != nil {
+			log.Printf("scheduler[%s]: changes digest: %v", sched.Name, err)
+		} else {
+			reply += "\n\n" + digest
+		}
+	}
+
+	if n, err := s.db.CountProposedMemories(); err != nil {
+		log.Printf("scheduler[%s]: counting proposed memories: %v", sched.Name, err)
+	} else if n > 0 {
+		reply += fmt.Sprintf("\n\n_%d proposed memory(ies) awaiting review. Reply `!memories` to approve or reject._", n)
+	}
 
 	s.deliver(fmt.Sprintf("scheduler[%s]", sched.Name), reply)
 
@@ -166,14 +225,15 @@ func (s *Scheduler) fireReminders() {
 		log.Printf("scheduler: listing one-shots: %v", err)
 		return
 	}
+	ctx := agent.WithProfile(context.Background(), agent.ProfileSchedule)
 	for _, r := range pending {
 		msg := fmt.Sprintf("A reminder just fired. The user asked to be reminded: %q. Deliver this reminder to them in a brief, friendly message. Do NOT create a new reminder or ask clarifying questions — just notify them.", r.Prompt)
 		var reply string
 		var err error
 		if userID := s.resolveUserID(); userID != "" {
-			reply, err = s.agent.RunWithConversation(context.Background(), userID, msg)
+			reply, err = s.agent.RunWithConversation(ctx, userID, msg)
 		} else {
-			reply, _, err = s.agent.Run(context.Background(), nil, msg)
+			reply, _, err = s.agent.Run(ctx, nil, msg)
 		}
 		if err != nil {
 			log.Printf("scheduler: one-shot %d agent error: %v", r.ID, err)
@@ -187,6 +247,63 @@ func (s *Scheduler) fireReminders() {
 	}
 }
 
+// jobPollInterval is how often the job worker checks for queued jobs.
+const jobPollInterval = 15 * time.Second
+
+// runJobs runs queued background jobs one at a time until the queue is empty,
+// delivering each result (or failure) like a schedule's reply.
+func (s *Scheduler) runJobs() {
+	for {
+		job, err := s.db.ClaimJob()
+		if err != nil {
+			log.Printf("scheduler: claiming job: %v", err)
+			return
+		}
+		if job == nil {
+			return
+		}
+		label := fmt.Sprintf("job[%d]", job.ID)
+		reply, err := s.agent.RunJob(context.Background(), *job)
+		if err != nil {
+			log.Printf("%s: agent error: %v", label, err)
+			if err := s.db.FinishJob(job.ID, "", err.Error()); err != nil {
+				log.Printf("%s: %v", label, err)
+			}
+			s.deliver(label, fmt.Sprintf("**Job #%d failed: %s**\n\n%v", job.ID, job.Title, err))
+			continue
+		}
+		if err := s.db.FinishJob(job.ID, reply, ""); err != nil {
+			log.Printf("%s: %v", label, err)
+		}
+		s.deliver(label, fmt.Sprintf("**Job #%d done: %s**\n\n%s", job.ID, job.Title, reply))
+		log.Printf("%s: completed", label)
+	}
+}
+
+// staleAfterDays is how long an active thing can go untouched before it is flagged.
+const staleAfterDays = 14
+
+// ageThings escalates priority on things with approaching due dates and flags
+// stale active things. Both write thing_events for check-ins to report.
+func (s *Scheduler) ageThings() {
+	today := time.Now().In(s.agent.Location()).Format("2006-01-02")
+	if events, err := s.db.EscalateDueThings(today); err != nil {
+		log.Printf("scheduler: escalating things: %v", err)
+	} else if len(events) > 0 {
+		log.Printf("scheduler: escalated %d thing(s)", len(events))
+	}
+
+	if events, err := s.db.FlagStaleThings(staleAfterDays); err != nil {
+		log.Printf("scheduler: flagging stale things: %v", err)
+	} else if len(events) > 0 {
+		log.Printf("scheduler: flagged %d stale thing(s)", len(events))
+	}
+}
+
+// transcriptRetentionDays is how long conversation transcripts stay
+// searchable with search_conversations.
+const transcriptRetentionDays = 365
+
 func (s *Scheduler) pruneOldData() {
 	if n, err := s.db.PruneOldWatchResults(180); err != nil {
 		log.Printf("scheduler: pruning watch results: %v", err)
@@ -199,6 +316,42 @@ func (s *Scheduler) pruneOldData() {
 	} else if n > 0 {
 		log.Printf("scheduler: pruned %d old conversation summary(ies)", n)
 	}
+
+	if n, err := s.db.PruneTranscript(transcriptRetentionDays); err != nil {
+		log.Printf("scheduler: pruning transcript: %v", err)
+	} else if n > 0 {
+		log.Printf("scheduler: pruned %d transcript message(s)", n)
+	}
+
+	if n, err := s.db.PruneThingEvents(90); err != nil {
+		log.Printf("scheduler: pruning thing events: %v", err)
+	} else if n > 0 {
+		log.Printf("scheduler: pruned %d thing event(s)", n)
+	}
+
+	if n, err := s.db.PruneFailedDeliveries(30); err != nil {
+		log.Printf("scheduler: pruning deliveries: %v", err)
+	} else if n > 0 {
+		log.Printf("scheduler: pruned %d failed delivery(ies)", n)
+	}
+
+	if n, err := s.db.PruneAuditLog(90); err != nil {
+		log.Printf("scheduler: pruning audit log: %v", err)
+	} else if n > 0 {
+		log.Printf("scheduler: pruned %d audit log entry(ies)", n)
+	}
+
+	if n, err := s.db.PruneJobs(30); err != nil {
+		log.Printf("scheduler: pruning jobs: %v", err)
+	} else if n > 0 {
+		log.Printf("scheduler: pruned %d finished job(s)", n)
+	}
+
+	if n, err := s.db.PruneToolResults(1); err != nil {
+		log.Printf("scheduler: pruning idempotency keys: %v", err)
+	} else if n > 0 {
+		log.Printf("scheduler: pruned %d idempotency key(s)", n)
+	}
 }
 
 // loadWatches registers enabled watches with cron expressions into the cron scheduler.
@@ -281,26 +434,117 @@ func formatWatchResults(watchName string, results []db.WatchResult) string {
 	return strings.TrimSpace(b.String())
 }
 
+// Delivery queue tuning. A failed delivery is retried after
+// deliveryBackoff, doubling each time up to deliveryMaxBackoff, and given up
+// on after deliveryMaxAttempts (about three hours of retries).
+const (
+	deliveryPollInterval = 30 * time.Second
+	deliveryBatch        = 20
+	deliveryBackoff      = 30 * time.Second
+	deliveryMaxBackoff   = time.Hour
+	deliveryMaxAttempts  = 10
+)
+
+// errNoTarget means neither a Discord DM user nor a webhook is configured;
+// retrying won't help.
+var errNoTarget = errors.New("no delivery method available (no DM user and no webhook)")
+
+// deliver queues content for delivery and tries to send it right away. The
+// queue is persistent, so a network failure or restart delays a check-in or
+// reminder instead of losing it. Content too long for one Discord message is
+// queued as one delivery per chunk, so a retry after a partial send doesn't
+// repeat the chunks that went out.
 func (s *Scheduler) deliver(label, content string) {
-	// Try DM first
-	if s.dmSend != nil {
-		note, err := s.db.GetNote("discord_user_id")
-		if err == nil && note != "" {
-			if err := s.dmSend(note, content); err != nil {
-				log.Printf("%s: DM send failed: %v", label, err)
+	chunks := discord.SplitMessage(content, discord.MessageLimit)
+	for i, chunk := range chunks {
+		if _, err := s.db.EnqueueDelivery(label, chunk); err != nil {
+			log.Printf("%s: %v; sending without the queue", label, err)
+			if err := s.send(strings.Join(chunks[i:], "")); err != nil {
+				log.Printf("%s: %v", label, err)
+			}
+			break
+		}
+	}
+	s.sendDeliveries()
+}
+
+// sendDeliveries sends due deliveries, oldest first. The first failure ends
+// the round: the target is probably down, so the rest wait for the next one.
+func (s *Scheduler) sendDeliveries() {
+	s.deliverMu.Lock()
+	defer s.deliverMu.Unlock()
+	for {
+		due, err := s.db.DueDeliveries(deliveryBatch)
+		if err != nil {
+			log.Printf("scheduler: listing deliveries: %v", err)
+			return
+		}
+		for _, d := range due {
+			err := s.send(d.Content)
+			if err == nil {
+				if err := s.db.DeliverySent(d.ID); err != nil {
+					log.Printf("%s: %v", d.Label, err)
+				}
+				continue
+			}
+			var retryAt time.Time
+			if attempts := d.Attempts + 1; attempts < deliveryMaxAttempts && !errors.Is(err, errNoTarget) {
+				retryAt = time.Now().Add(deliveryDelay(attempts))
+				log.Printf("%s: delivery attempt %d failed, retrying at %s: %v", d.Label, attempts, retryAt.Format(time.TimeOnly), err)
 			} else {
-				return
+				log.Printf("%s: delivery failed after %d attempt(s), giving up: %v", d.Label, attempts, err)
+			}
+			if err := s.db.DeliveryFailed(d.ID, err.Error(), retryAt); err != nil {
+				log.Printf("%s: %v", d.Label, err)
 			}
+			return
+		}
+		if len(due) < deliveryBatch {
+			return
 		}
 	}
-	// Fall back to webhook
+}
+
+// deliveryDelay is the wait before retry number attempts.
+func deliveryDelay(attempts int) time.Duration {
+	d := deliveryBackoff
+	for range attempts - 1 {
+		if d *= 2; d >= deliveryMaxBackoff {
+			return deliveryMaxBackoff
+		}
+	}
+	return d
+}
+
+// send delivers content by Discord DM, falling back to the webhook.
+func (s *Scheduler) send(content string) error {
+	var dmErr error
+	if s.dmSend != nil {
+		if userID := s.resolveUserID(); userID != "" {
+			if dmErr = s.dmSend(userID, content); dmErr == nil {
+				return nil
+			}
+			dmErr = fmt.Errorf("DM send failed: %w", dmErr)
+		}
+	}
+	// The DM path applies the plain-text preference itself.
 	if s.webhookURL != "" {
-		if err := postWebhook(s.webhookURL, content); err != nil {
-			log.Printf("%s: webhook failed: %v", label, err)
+		plain := false
+		if userID := s.resolveUserID(); userID != "" && s.agent != nil {
+			plain = s.agent.PlainText(userID)
 		}
-		return
+		if plain {
+			content = plaintext.Strip(content)
+		}
+		if err := postWebhook(s.webhookURL, content, plain); err != nil {
+			return errors.Join(dmErr, err)
+		}
+		return nil
+	}
+	if dmErr != nil {
+		return dmErr
 	}
-	log.Printf("%s: no delivery method available (no DM user and no webhook)", label)
+	return errNoTarget
 }
 
 // resolveUserID looks up the discord_user_id note. Returns empty string if not set.
@@ -312,8 +556,14 @@ func (s *Scheduler) resolveUserID() string {
 	return note
 }
 
-func postWebhook(url, content string) error {
-	payload := map[string]string{"content": content}
+// suppressEmbeds is Discord's SUPPRESS_EMBEDS message flag.
+const suppressEmbeds = 1 << 2
+
+func postWebhook(url, content string, plain bool) error {
+	payload := map[string]any{"content": content}
+	if plain {
+		payload["flags"] = suppressEmbeds
+	}
 	body, _ := json.Marshal(payload)
 	resp, err := http.Post(url, "application/json", bytes.NewReader(body))
 	if err != nil {
@@ -0,0 +1,115 @@
+package scheduler
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chris/jot/internal/db"
+	"github.com/chris/jot/internal/db/dbtest"
+)
+
+func TestDeliveryQueue(t *testing.T) {
+	path := dbtest.Path(t)
+	d, err := db.Open(path)
+	if err != nil {
+		t.Fatalf("opening db: %v", err)
+	}
+	defer d.Close()
+	// A second connection to move retries forward without waiting.
+	raw, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("opening raw connection: %v", err)
+	}
+	defer raw.Close()
+	d.SetNote("discord_user_id", "u1")
+
+	var sent []string
+	down := true
+	s := New(d, nil, "", func(userID, content string) error {
+		if down {
+			return errors.New("gateway unavailable")
+		}
+		sent = append(sent, content)
+		return nil
+	}, nil)
+
+	s.deliver("scheduler[morning]", "first")
+	s.deliver("reminder[1]", "second")
+	if len(sent) != 0 {
+		t.Fatalf("sent while down: %v", sent)
+	}
+	if due, _ := d.DueDeliveries(10); len(due) != 0 {
+		t.Fatalf("expected both deliveries waiting to retry, got %+v", due)
+	}
+
+	// Once the target is back and the retries are due, both go out in order.
+	down = false
+	if _, err := raw.Exec(`UPDATE deliveries SET next_attempt_at = datetime('now')`); err != nil {
+		t.Fatal(err)
+	}
+	s.sendDeliveries()
+	if len(sent) != 2 || sent[0] != "first" || sent[1] != "second" {
+		t.Fatalf("sent = %v", sent)
+	}
+	if left, _ := d.DueDeliveries(10); len(left) != 0 {
+		t.Errorf("queue not empty: %+v", left)
+	}
+}
+
+func TestDeliveryChunksSentOnce(t *testing.T) {
+	path := dbtest.Path(t)
+	d, err := db.Open(path)
+	if err != nil {
+		t.Fatalf("opening db: %v", err)
+	}
+	defer d.Close()
+	raw, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("opening raw connection: %v", err)
+	}
+	defer raw.Close()
+	d.SetNote("discord_user_id", "u1")
+
+	var sent []string
+	fail := 2 // the second chunk fails once
+	s := New(d, nil, "", func(userID, content string) error {
+		if fail--; fail == 0 {
+			return errors.New("gateway unavailable")
+		}
+		sent = append(sent, content)
+		return nil
+	}, nil)
+
+	line := strings.Repeat("x", 999) + "\n"
+	s.deliver("job[1]", strings.Repeat(line, 5)) // 5000 bytes: three chunks
+	if len(sent) != 1 {
+		t.Fatalf("expected the first chunk sent before the failure, got %d", len(sent))
+	}
+	if _, err := raw.Exec(`UPDATE deliveries SET next_attempt_at = datetime('now')`); err != nil {
+		t.Fatal(err)
+	}
+	s.sendDeliveries()
+	if len(sent) != 3 || strings.Join(sent, "") != strings.Repeat(line, 5) {
+		t.Errorf("sent %d chunk(s); want each of 3 exactly once, in order", len(sent))
+	}
+}
+
+func TestDeliveryNoTarget(t *testing.T) {
+	d := dbtest.Open(t)
+	s := New(d, nil, "", nil, nil)
+	s.deliver("job[1]", "done")
+	if due, _ := d.DueDeliveries(10); len(due) != 0 {
+		t.Errorf("undeliverable message left pending: %+v", due)
+	}
+}
+
+func TestDeliveryDelay(t *testing.T) {
+	for attempts, want := range map[int]time.Duration{1: 30 * time.Second, 2: time.Minute, 4: 4 * time.Minute, 8: time.Hour, 20: time.Hour} {
+		if got := deliveryDelay(attempts); got != want {
+			t.Errorf("deliveryDelay(%d) = %s, want %s", attempts, got, want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+// Package share renders a single thing and its linked memories into a
+// standalone Markdown snippet that can be handed to someone else.
+package share
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/chris/jot/internal/db"
+)
+
+// Render writes t and its linked memories as Markdown. Only the thing's own
+// context is included: no IDs, other things, or unrelated memories.
+func Render(w io.Writer, t *db.Thing, memories []db.Memory) {
+	fmt.Fprintf(w, "# %s\n\n", t.Title)
+	fmt.Fprintf(w, "- Status: %s\n- Priority: %s\n", t.Status, t.Priority)
+	if t.DueDate != "" {
+		fmt.Fprintf(w, "- Due: %s\n", t.DueDate)
+	}
+	if len(t.Tags) > 0 {
+		fmt.Fprintf(w, "- Tags: %s\n", strings.Join(t.Tags, ", "))
+	}
+	if t.Notes != "" {
+		fmt.Fprintf(w, "\n%s\n", t.Notes)
+	}
+	if len(memories) > 0 {
+		fmt.Fprintf(w, "\n## Notes\n\n")
+		// Oldest first reads as a timeline.
+		for i := len(memories) - 1; i >= 0; i-- {
+			m := memories[i]
+			fmt.Fprintf(w, "- %s (%s): %s\n", datePart(m.CreatedAt), m.Category, m.Content)
+		}
+	}
+}
+
+// Encrypt pipes plaintext through the age CLI, producing ASCII-armored
+// output. With recipients it encrypts to those public keys; with none it
+// prompts for a passphrase on the terminal.
+func Encrypt(plaintext []byte, recipients []string) ([]byte, error) {
+	path, err := exec.LookPath("age")
+	if err != nil {
+		return nil, fmt.Errorf("age not found in PATH (install from https://age-encryption.org): %w", err)
+	}
+	args := []string{"--armor"}
+	if len(recipients) == 0 {
+		args = append(args, "--passphrase")
+	}
+	for _, r := range recipients {
+		args = append(args, "--recipient", r)
+	}
+
+	var out bytes.Buffer
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = bytes.NewReader(plaintext)
+	cmd.Stdout = &out
+	cmd.Stderr = os.Stderr // age prompts for the passphrase here
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("running age: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+func datePart(ts string) string {
+	if len(ts) >= 10 {
+		return ts[:10]
+	}
+	return ts
+}
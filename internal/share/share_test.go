@@ -0,0 +1,33 @@
+package share
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/chris/jot/internal/db"
+)
+
+func TestRender(t *testing.T) {
+	thing := &db.Thing{ID: 42, Title: "Migrate billing service", Status: "active", Priority: "high", DueDate: "2026-04-01", Tags: []string{"work"}, Notes: "Cut over after the freeze."}
+	memories := []db.Memory{ // newest first, as SearchMemories returns them
+		{ID: 9, Content: "Decided to keep the old API for a month", Category: "decision", CreatedAt: "2026-03-10 09:00:00"},
+		{ID: 3, Content: "Blocked on DB credentials", Category: "blocker", CreatedAt: "2026-03-02 14:30:00"},
+	}
+
+	var buf bytes.Buffer
+	Render(&buf, thing, memories)
+	got := buf.String()
+
+	for _, want := range []string{"# Migrate billing service", "- Due: 2026-04-01", "- Tags: work", "Cut over after the freeze.", "- 2026-03-02 (blocker): Blocked on DB credentials"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+	if strings.Index(got, "Blocked on DB") > strings.Index(got, "Decided to keep") {
+		t.Error("expected notes oldest first")
+	}
+	if strings.Contains(got, "42") {
+		t.Error("snippet should not expose internal IDs")
+	}
+}
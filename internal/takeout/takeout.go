@@ -0,0 +1,303 @@
+// Package takeout writes a human-readable archive of everything jot stores.
+package takeout
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/chris/jot/internal/db"
+)
+
+// Write writes e to w as a zip archive containing data.json (the complete
+// export, machine-readable) and one Markdown file per kind of data.
+func Write(w io.Writer, e *db.Export) error {
+	zw := zip.NewWriter(w)
+
+	files := []struct {
+		name   string
+		render func(io.Writer, *db.Export)
+	}{
+		{"README.md", writeIndex},
+		{"things.md", writeThings},
+		{"memories.md", writeMemories},
+		{"schedules.md", writeSchedules},
+		{"watches.md", writeWatches},
+		{"jobs.md", writeJobs},
+		{"conversations.md", writeConversations},
+		{"changes.md", writeChanges},
+		{"settings.md", writeNotes},
+	}
+	for _, f := range files {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return fmt.Errorf("creating %s: %w", f.name, err)
+		}
+		f.render(fw, e)
+	}
+
+	fw, err := zw.Create("data.json")
+	if err != nil {
+		return fmt.Errorf("creating data.json: %w", err)
+	}
+	enc := json.NewEncoder(fw)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(e); err != nil {
+		return fmt.Errorf("encoding data.json: %w", err)
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("finishing archive: %w", err)
+	}
+	return nil
+}
+
+func writeIndex(w io.Writer, e *db.Export) {
+	fmt.Fprintf(w, "# Jot takeout\n\nExported %s UTC. This archive contains everything jot has stored about you.\n\n", e.ExportedAt)
+	fmt.Fprintf(w, "| File | Contents |\n|------|----------|\n")
+	fmt.Fprintf(w, "| things.md | %d thing(s), %d escalation(s) and stale flag(s) |\n", len(e.Things), len(e.ThingEvents))
+	fmt.Fprintf(w, "| memories.md | %d memory(ies), %d awaiting review |\n", len(e.Memories), len(e.ProposedMemories))
+	fmt.Fprintf(w, "| schedules.md | %d schedule(s) and reminder(s), %d run(s), %d unsent message(s) |\n", len(e.Schedules), len(e.ScheduleRuns), len(e.Deliveries))
+	fmt.Fprintf(w, "| watches.md | %d watch(es), %d result(s) |\n", len(e.Watches), len(e.WatchResults))
+	fmt.Fprintf(w, "| jobs.md | %d background job(s) |\n", len(e.Jobs))
+	fmt.Fprintf(w, "| conversations.md | %d conversation(s), %d summary(ies), %d transcript message(s) |\n", len(e.Conversations), len(e.ConversationSummaries), len(e.Transcript))
+	fmt.Fprintf(w, "| changes.md | %d change(s) jot made through its tools |\n", len(e.AuditLog))
+	fmt.Fprintf(w, "| settings.md | %d setting(s), %d area(s), %d saved filter(s) |\n", len(e.Notes), len(e.Areas), len(e.SavedFilters))
+	fmt.Fprintf(w, "| data.json | All of the above, machine-readable |\n\n")
+	fmt.Fprintf(w, "Jot does not store file attachments, so there are none to include.\n\n")
+	fmt.Fprintf(w, "To delete something, run `jot purge <query>` or ask jot to forget it.\n")
+}
+
+func writeThings(w io.Writer, e *db.Export) {
+	fmt.Fprintf(w, "# Things\n\n")
+	if len(e.Things) == 0 {
+		fmt.Fprintf(w, "_None._\n")
+	}
+	events := make(map[int64][]db.ThingEvent)
+	for _, ev := range e.ThingEvents {
+		events[ev.ThingID] = append(events[ev.ThingID], ev)
+	}
+	for _, t := range e.Things {
+		fmt.Fprintf(w, "## #%d %s\n\n", t.ID, t.Title)
+		fmt.Fprintf(w, "- Status: %s\n- Priority: %s\n", t.Status, t.Priority)
+		if t.DueDate != "" {
+			fmt.Fprintf(w, "- Due: %s\n", t.DueDate)
+		}
+		if len(t.Tags) > 0 {
+			fmt.Fprintf(w, "- Tags: %s\n", strings.Join(t.Tags, ", "))
+		}
+		fmt.Fprintf(w, "- Created: %s\n", t.CreatedAt)
+		if t.CompletedAt != "" {
+			fmt.Fprintf(w, "- Completed: %s\n", t.CompletedAt)
+		}
+		for _, ev := range events[t.ID] {
+			fmt.Fprintf(w, "- %s %s: %s\n", ev.CreatedAt, ev.Kind, ev.Detail)
+		}
+		if t.Notes != "" {
+			fmt.Fprintf(w, "\n%s\n", t.Notes)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+func writeMemories(w io.Writer, e *db.Export) {
+	fmt.Fprintf(w, "# Memories\n\n")
+	writeMemoryList(w, e.Memories)
+	if len(e.ProposedMemories) > 0 {
+		fmt.Fprintf(w, "\n## Awaiting review\n\n")
+		writeMemoryList(w, e.ProposedMemories)
+	}
+}
+
+func writeMemoryList(w io.Writer, memories []db.Memory) {
+	if len(memories) == 0 {
+		fmt.Fprintf(w, "_None._\n")
+	}
+	for _, m := range memories {
+		fmt.Fprintf(w, "- **#%d** %s [%s] %s", m.ID, m.CreatedAt, m.Category, m.Content)
+		if len(m.Tags) > 0 {
+			fmt.Fprintf(w, " (tags: %s)", strings.Join(m.Tags, ", "))
+		}
+		if m.ThingID != nil {
+			fmt.Fprintf(w, " (thing #%d)", *m.ThingID)
+		}
+		if m.ExpiresAt != "" {
+			fmt.Fprintf(w, " (expires %s)", m.ExpiresAt)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+func writeSchedules(w io.Writer, e *db.Export) {
+	fmt.Fprintf(w, "# Schedules\n\n")
+	if len(e.Schedules) == 0 {
+		fmt.Fprintf(w, "_None._\n")
+	}
+	for _, s := range e.Schedules {
+		when := s.CronExpr
+		if s.FireAt != "" {
+			when = "once at " + s.FireAt + " UTC"
+		}
+		state := "enabled"
+		if !s.Enabled {
+			state = "disabled"
+		}
+		fmt.Fprintf(w, "## %s\n\n- When: %s\n- State: %s\n\n%s\n\n", s.Name, when, state, s.Prompt)
+	}
+	if len(e.ScheduleRuns) > 0 {
+		fmt.Fprintf(w, "## Run log\n\n")
+		for _, r := range e.ScheduleRuns {
+			fmt.Fprintf(w, "### %s %s\n\n%s\n\n", r.CreatedAt, r.ScheduleName, r.Output)
+		}
+	}
+	if len(e.Deliveries) > 0 {
+		fmt.Fprintf(w, "## Waiting to be sent\n\n")
+		for _, dl := range e.Deliveries {
+			fmt.Fprintf(w, "### %s %s (%s, %d attempt(s))\n\n%s\n\n", dl.CreatedAt, dl.Label, dl.Status, dl.Attempts, dl.Content)
+		}
+	}
+}
+
+func writeWatches(w io.Writer, e *db.Export) {
+	fmt.Fprintf(w, "# Watches\n\n")
+	if len(e.Watches) == 0 {
+		fmt.Fprintf(w, "_None._\n")
+	}
+	results := make(map[int64][]db.WatchResult)
+	for _, r := range e.WatchResults {
+		results[r.WatchID] = append(results[r.WatchID], r)
+	}
+	for _, wa := range e.Watches {
+		fmt.Fprintf(w, "## %s\n\n%s\n\n", wa.Name, wa.Prompt)
+		for _, u := range wa.URLs {
+			fmt.Fprintf(w, "- <%s>\n", u)
+		}
+		if rs := results[wa.ID]; len(rs) > 0 {
+			fmt.Fprintf(w, "\n### Results\n\n")
+			for _, r := range rs {
+				fmt.Fprintf(w, "- %s %s", r.FirstSeen, r.Title)
+				if r.SourceURL != "" {
+					fmt.Fprintf(w, " <%s>", r.SourceURL)
+				}
+				fmt.Fprintln(w)
+			}
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+func writeJobs(w io.Writer, e *db.Export) {
+	fmt.Fprintf(w, "# Background jobs\n\n")
+	if len(e.Jobs) == 0 {
+		fmt.Fprintf(w, "_None._\n")
+	}
+	for _, j := range e.Jobs {
+		fmt.Fprintf(w, "## #%d %s\n\n- Status: %s\n- Created: %s\n", j.ID, j.Title, j.Status, j.CreatedAt)
+		if j.FinishedAt != "" {
+			fmt.Fprintf(w, "- Finished: %s\n", j.FinishedAt)
+		}
+		fmt.Fprintf(w, "\n%s\n\n", j.Prompt)
+		if j.Result != "" {
+			fmt.Fprintf(w, "### Result\n\n%s\n\n", j.Result)
+		}
+		if j.Error != "" {
+			fmt.Fprintf(w, "### Error\n\n%s\n\n", j.Error)
+		}
+	}
+}
+
+func writeConversations(w io.Writer, e *db.Export) {
+	fmt.Fprintf(w, "# Conversations\n\n")
+	if len(e.ConversationSummaries) > 0 {
+		fmt.Fprintf(w, "## Summaries\n\n")
+		for _, s := range e.ConversationSummaries {
+			fmt.Fprintf(w, "- **%s** (%s, %d messages): %s\n", s.CreatedAt, s.UserID, s.MessageCount, s.Summary)
+		}
+		fmt.Fprintln(w)
+	}
+	for _, c := range e.Conversations {
+		fmt.Fprintf(w, "## Current conversation: %s\n\nLast message %s.\n\n", c.UserID, c.LastMessageAt)
+		for _, m := range c.Messages {
+			if m.ToolCallID != "" || m.Content == "" {
+				continue // tool results are machine data; they're in data.json
+			}
+			fmt.Fprintf(w, "**%s:** %s\n\n", m.Role, m.Content)
+		}
+	}
+	if len(e.Transcript) > 0 {
+		fmt.Fprintf(w, "## Transcript\n\n")
+		for _, m := range e.Transcript {
+			fmt.Fprintf(w, "**%s** %s (%s): %s\n\n", m.CreatedAt, m.Role, m.UserID, m.Content)
+		}
+	}
+	if len(e.Conversations) == 0 && len(e.ConversationSummaries) == 0 && len(e.Transcript) == 0 {
+		fmt.Fprintf(w, "_None._\n")
+	}
+}
+
+func writeChanges(w io.Writer, e *db.Export) {
+	fmt.Fprintf(w, "# Changes\n\nWrites jot made on your behalf, oldest first (UTC).\n\n")
+	if len(e.AuditLog) == 0 {
+		fmt.Fprintf(w, "_None._\n")
+	}
+	for _, a := range e.AuditLog {
+		fmt.Fprintf(w, "- %s %s %s %s", a.CreatedAt, a.Tool, a.Action, a.Target)
+		if a.Detail != "" {
+			fmt.Fprintf(w, ": %s", a.Detail)
+		}
+		if a.Profile != "" {
+			fmt.Fprintf(w, " (%s)", a.Profile)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+func writeNotes(w io.Writer, e *db.Export) {
+	fmt.Fprintf(w, "# Settings\n\n")
+	if len(e.Notes) == 0 {
+		fmt.Fprintf(w, "_None._\n")
+	}
+	keys := make([]string, 0, len(e.Notes))
+	for k := range e.Notes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(w, "- %s: %s\n", k, e.Notes[k])
+	}
+	if len(e.Areas) > 0 {
+		fmt.Fprintf(w, "\n## Areas\n\n")
+		for _, a := range e.Areas {
+			fmt.Fprintf(w, "- %s: %s\n", a.Name, strings.Join(a.Tags, ", "))
+		}
+	}
+	if len(e.SavedFilters) > 0 {
+		fmt.Fprintf(w, "\n## Saved filters\n\n")
+		for _, f := range e.SavedFilters {
+			spec, _ := json.Marshal(f)
+			fmt.Fprintf(w, "- %s: `%s`\n", f.Name, spec)
+		}
+	}
+	if len(e.ToolDenials) > 0 {
+		fmt.Fprintf(w, "\n## Tools denied\n\n")
+		for _, t := range e.ToolDenials {
+			fmt.Fprintf(w, "- %s: `%s`\n", t.Profile, t.Pattern)
+		}
+	}
+	if len(e.GuildSettings) > 0 {
+		fmt.Fprintf(w, "\n## Discord servers\n\n")
+		for _, g := range e.GuildSettings {
+			channels, role := "any channel", "anyone"
+			if len(g.Channels) > 0 {
+				channels = "channels " + strings.Join(g.Channels, ", ")
+			}
+			if g.RequiredRole != "" {
+				role = "role " + g.RequiredRole
+			}
+			fmt.Fprintf(w, "- %s: %s, %s, profile %s\n", g.GuildID, channels, role, g.Profile)
+		}
+	}
+}
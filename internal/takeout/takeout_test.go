@@ -0,0 +1,87 @@
+package takeout
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/chris/jot/internal/db"
+	"github.com/chris/jot/internal/llm"
+)
+
+func TestWrite(t *testing.T) {
+	thingID := int64(1)
+	e := &db.Export{
+		ExportedAt: "2026-03-01 12:00:00",
+		Things:     []db.Thing{{ID: 1, Title: "Renew passport", Status: "open", Priority: "high", Tags: []string{"admin"}}},
+		ThingEvents: []db.ThingEvent{{ID: 1, ThingID: 1, Title: "Renew passport", Kind: "escalated",
+			Detail: "normal → high (due 2026-03-03)", CreatedAt: "2026-03-01 06:00:00"}},
+		Memories:   []db.Memory{{ID: 7, Content: "Passport expires in June", Category: "event", ThingID: &thingID}},
+		Notes:      map[string]string{"timezone": "America/Chicago"},
+		Jobs:       []db.Job{{ID: 3, Title: "Desks", Prompt: "Research standing desks", Status: "done", Result: "1. Uplift"}},
+		Deliveries: []db.Delivery{{ID: 2, Label: "reminder[4]", Content: "Passport photo appointment", Status: "pending"}},
+		AuditLog:   []db.AuditEntry{{ID: 1, Tool: "create_thing", Action: "create", Target: "thing #1", Detail: "Renew passport", CreatedAt: "2026-03-01 11:00:00"}},
+		Conversations: []db.Conversation{{UserID: "cli", Messages: []llm.Message{
+			{Role: "user", Content: "when does my passport expire?"},
+			{Role: "user", Content: `{"id":7}`, ToolCallID: "t1"},
+		}}},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, e); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("reading zip: %v", err)
+	}
+
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		b, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(b)
+	}
+
+	for _, name := range []string{"README.md", "things.md", "memories.md", "schedules.md", "watches.md", "jobs.md", "conversations.md", "changes.md", "settings.md", "data.json"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("missing %s", name)
+		}
+	}
+	if !strings.Contains(files["things.md"], "## #1 Renew passport") {
+		t.Errorf("things.md missing thing:\n%s", files["things.md"])
+	}
+	if !strings.Contains(files["things.md"], "- 2026-03-01 06:00:00 escalated: normal → high (due 2026-03-03)") {
+		t.Errorf("things.md missing thing event:\n%s", files["things.md"])
+	}
+	if !strings.Contains(files["memories.md"], "Passport expires in June") || !strings.Contains(files["memories.md"], "(thing #1)") {
+		t.Errorf("memories.md missing memory:\n%s", files["memories.md"])
+	}
+	if strings.Contains(files["conversations.md"], `{"id":7}`) {
+		t.Error("conversations.md should skip tool results")
+	}
+	if !strings.Contains(files["schedules.md"], "Passport photo appointment") {
+		t.Errorf("schedules.md missing unsent message:\n%s", files["schedules.md"])
+	}
+	if !strings.Contains(files["jobs.md"], "## #3 Desks") || !strings.Contains(files["jobs.md"], "1. Uplift") {
+		t.Errorf("jobs.md missing job:\n%s", files["jobs.md"])
+	}
+	if !strings.Contains(files["changes.md"], "create_thing create thing #1: Renew passport") {
+		t.Errorf("changes.md missing audit entry:\n%s", files["changes.md"])
+	}
+	if !strings.Contains(files["settings.md"], "timezone: America/Chicago") {
+		t.Errorf("settings.md missing note:\n%s", files["settings.md"])
+	}
+
+	var decoded db.Export
+	if err := json.Unmarshal([]byte(files["data.json"]), &decoded); err != nil {
+		t.Fatalf("data.json: %v", err)
+	}
+	if len(decoded.Things) != 1 || len(decoded.Memories) != 1 || len(decoded.Conversations[0].Messages) != 2 {
+		t.Errorf("data.json incomplete: %+v", decoded)
+	}
+}
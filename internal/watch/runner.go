@@ -12,16 +12,40 @@ import (
 	"github.com/chris/jot/internal/llm"
 )
 
-const extractionSystemPrompt = `You are an extraction assistant. You will receive text content scraped from one or more web pages, along with instructions on what to extract.
+const extractionPrompt = `You are an extraction assistant. You will receive text content scraped from one or more web pages, along with instructions on what to extract.
 
-Return ONLY a JSON array of objects. Each object must have these fields:
+Return every matching item in "items". For each item:
 - "title": unique identifying name for the item (required). Include enough context to distinguish similar items (e.g., "Hamlet - Austin Playhouse" not just "Hamlet"). Titles are used for deduplication across runs.
-- "body": relevant details, summary, or description (optional, empty string if none)
-- "source_url": the URL this item came from, if identifiable (optional, empty string if unknown)
-
-If no matching items are found, return an empty array: []
-
-Do not include any text outside the JSON array. No markdown, no explanation, just the JSON.`
+- "body": relevant details, summary, or description (empty string if none)
+- "source_url": the URL this item came from, if identifiable (empty string if unknown)
+
+If no matching items are found, return an empty "items" array.`
+
+// extractionSchema is the structured output shape for watch extraction.
+var extractionSchema = llm.Schema{
+	Name:        "watch_items",
+	Description: "Items extracted from watched web pages",
+	Parameters: map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"items": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"title":      map[string]any{"type": "string"},
+						"body":       map[string]any{"type": "string"},
+						"source_url": map[string]any{"type": "string"},
+					},
+					"required":             []string{"title", "body", "source_url"},
+					"additionalProperties": false,
+				},
+			},
+		},
+		"required":             []string{"items"},
+		"additionalProperties": false,
+	},
+}
 
 // Runner coordinates watch execution: fetch → extract → dedup → store.
 type Runner struct {
@@ -71,15 +95,14 @@ func (r *Runner) RunWatch(ctx context.Context, w db.Watch) ([]db.WatchResult, er
 	}
 
 	// 2. Ask the LLM to extract items.
-	userMessage := fmt.Sprintf("%s\n\n%s", w.Prompt, strings.Join(contentParts, "\n\n"))
-	messages := []llm.Message{{Role: "user", Content: userMessage}}
+	prompt := fmt.Sprintf("%s\n\n%s\n\n%s", extractionPrompt, w.Prompt, strings.Join(contentParts, "\n\n"))
 
-	resp, err := r.client.Chat(ctx, extractionSystemPrompt, messages, nil)
+	raw, err := r.client.Generate(ctx, prompt, extractionSchema)
 	if err != nil {
 		return nil, fmt.Errorf("LLM extraction: %w", err)
 	}
 
-	items, err := parseExtractedItems(resp.Content)
+	items, err := parseExtractedItems(string(raw))
 	if err != nil {
 		return nil, fmt.Errorf("parsing LLM response: %w", err)
 	}
@@ -123,7 +146,8 @@ func (r *Runner) RunWatch(ctx context.Context, w db.Watch) ([]db.WatchResult, er
 }
 
 // parseExtractedItems parses the LLM's JSON response into extracted items.
-// Tolerates markdown code fences around the JSON.
+// Accepts the {"items": [...]} structured output or a bare array, and
+// tolerates markdown code fences around either.
 func parseExtractedItems(raw string) ([]extractedItem, error) {
 	cleaned := strings.TrimSpace(raw)
 	// Strip markdown code fences if the LLM wraps them.
@@ -133,7 +157,17 @@ func parseExtractedItems(raw string) ([]extractedItem, error) {
 	cleaned = strings.TrimSpace(cleaned)
 
 	var items []extractedItem
-	if err := json.Unmarshal([]byte(cleaned), &items); err != nil {
+	var err error
+	if strings.HasPrefix(cleaned, "{") {
+		var wrapped struct {
+			Items []extractedItem `json:"items"`
+		}
+		err = json.Unmarshal([]byte(cleaned), &wrapped)
+		items = wrapped.Items
+	} else {
+		err = json.Unmarshal([]byte(cleaned), &items)
+	}
+	if err != nil {
 		preview := cleaned
 		if len(preview) > 500 {
 			preview = preview[:500] + "..."
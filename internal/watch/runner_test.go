@@ -2,6 +2,7 @@ package watch
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -17,13 +18,20 @@ type mockLLM struct {
 	err      error
 }
 
-func (m *mockLLM) Chat(_ context.Context, _ string, _ []llm.Message, _ []llm.Tool) (*llm.Response, error) {
+func (m *mockLLM) Chat(_ context.Context, _ string, _ []llm.Message, _ []llm.Tool, _ ...llm.ChatOption) (*llm.Response, error) {
 	if m.err != nil {
 		return nil, m.err
 	}
 	return &llm.Response{Content: m.response}, nil
 }
 
+func (m *mockLLM) Generate(_ context.Context, _ string, _ llm.Schema) (json.RawMessage, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return json.RawMessage(m.response), nil
+}
+
 func openTestDB(t *testing.T) *db.DB {
 	t.Helper()
 	d, err := db.Open(":memory:")
@@ -238,6 +246,8 @@ func TestParseExtractedItems(t *testing.T) {
 		wantErr bool
 	}{
 		{"valid JSON", `[{"title":"A","body":"B","source_url":"C"}]`, 1, false},
+		{"structured output", `{"items":[{"title":"A","body":"B","source_url":"C"}]}`, 1, false},
+		{"structured empty", `{"items":[]}`, 0, false},
 		{"empty array", `[]`, 0, false},
 		{"with fences", "```json\n[{\"title\":\"A\",\"body\":\"\",\"source_url\":\"\"}]\n```", 1, false},
 		{"invalid JSON", `not json`, 0, true},
@@ -258,3 +268,29 @@ func TestParseExtractedItems(t *testing.T) {
 		})
 	}
 }
+
+// OpenAI's strict structured outputs reject schemas whose objects allow extra
+// properties or leave any property optional.
+func TestExtractionSchemaStrict(t *testing.T) {
+	var check func(path string, s map[string]any)
+	check = func(path string, s map[string]any) {
+		if items, ok := s["items"].(map[string]any); ok {
+			check(path+"[]", items)
+		}
+		props, ok := s["properties"].(map[string]any)
+		if !ok {
+			return
+		}
+		if s["additionalProperties"] != false {
+			t.Errorf("%s: additionalProperties must be false", path)
+		}
+		required, _ := s["required"].([]string)
+		if len(required) != len(props) {
+			t.Errorf("%s: required = %v, want every property", path, required)
+		}
+		for name, p := range props {
+			check(path+"."+name, p.(map[string]any))
+		}
+	}
+	check("watch_items", extractionSchema.Parameters)
+}